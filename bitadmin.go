@@ -5,10 +5,15 @@ import (
 	"sort"
 
 	"github.com/daeMOn63/bitadmin/commands/cache"
+	"github.com/daeMOn63/bitadmin/commands/completion"
+	"github.com/daeMOn63/bitadmin/commands/credentials"
 	"github.com/daeMOn63/bitadmin/commands/group"
 	"github.com/daeMOn63/bitadmin/commands/hooks"
+	"github.com/daeMOn63/bitadmin/commands/project"
 	"github.com/daeMOn63/bitadmin/commands/repository"
+	"github.com/daeMOn63/bitadmin/commands/search"
 	"github.com/daeMOn63/bitadmin/commands/user"
+	"github.com/daeMOn63/bitadmin/commands/webhooks"
 	"github.com/daeMOn63/bitadmin/helper"
 	"github.com/daeMOn63/bitadmin/settings"
 	"github.com/fatih/color"
@@ -49,15 +54,46 @@ func main() {
 		Settings: globalSettings,
 	}
 
+	projectCommand := &project.Command{
+		Settings: globalSettings,
+	}
+
+	webhooksCommand := &webhooks.Command{
+		Settings: globalSettings,
+	}
+
+	searchCommand := &search.Command{
+		Settings: globalSettings,
+	}
+
+	completionCommand := &completion.Command{}
+
+	loginCommand := &credentials.LoginCommand{
+		Settings: globalSettings,
+	}
+
+	logoutCommand := &credentials.LogoutCommand{
+		Settings: globalSettings,
+	}
+
 	app.Commands = []cli.Command{
 		cacheCommand.GetCommand(),
 		repositoryCommand.GetCommand(),
 		userCommand.GetCommand(),
 		groupCommand.GetCommand(),
 		hooksCommand.GetCommand(),
+		projectCommand.GetCommand(),
+		webhooksCommand.GetCommand(),
+		searchCommand.GetCommand(),
+		completionCommand.GetCommand(),
+		loginCommand.GetCommand(),
+		logoutCommand.GetCommand(),
 	}
 
-	app.BashComplete = helper.AppAutoComplete
+	app.BashComplete = func(c *cli.Context) {
+		helper.AppAutoComplete(c, globalSettings.GetFileCache())
+	}
+	helper.EnableCommandAutoComplete(app.Commands)
 
 	sort.Sort(cli.FlagsByName(app.Flags))
 	sort.Sort(cli.CommandsByName(app.Commands))
@@ -65,7 +101,8 @@ func main() {
 	err := app.Run(os.Args)
 
 	if err != nil {
+		err = helper.TranslateError(err)
 		color.New(color.FgRed).Fprintf(cli.ErrWriter, "\nError: %s\n", err.Error())
-		os.Exit(1)
+		os.Exit(helper.ExitCode(err))
 	}
 }
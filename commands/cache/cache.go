@@ -6,7 +6,6 @@ import (
 	"fmt"
 
 	"github.com/daeMOn63/bitadmin/settings"
-	"github.com/daeMOn63/bitclient"
 	"github.com/urfave/cli"
 )
 
@@ -17,20 +16,45 @@ type Command struct {
 
 // GetCommand provide a ready to use cli.Command
 func (command *Command) GetCommand() cli.Command {
+	refreshCommand := &RefreshCommand{
+		Settings: command.Settings,
+		flags:    &RefreshCommandFlags{},
+	}
+
+	clearCommand := &ClearCommand{
+		Settings: command.Settings,
+		flags:    &ClearCommandFlags{},
+	}
+
+	listCommand := &ListCommand{
+		Settings: command.Settings,
+		flags:    &ListCommandFlags{},
+	}
+
+	queryCommand := &QueryCommand{
+		Settings: command.Settings,
+		flags:    &QueryCommandFlags{},
+	}
+
+	statusCommand := &StatusCommand{
+		Settings: command.Settings,
+		flags:    &StatusCommandFlags{},
+	}
+
 	return cli.Command{
 		Name:  "cache",
 		Usage: "Caching data for faster operation and autocompletion.",
 		Subcommands: []cli.Command{
-			{
-				Name:   "clear",
-				Usage:  "Clear all cached data",
-				Action: command.ClearCacheAction,
-			},
+			clearCommand.GetCommand(),
+			listCommand.GetCommand(),
 			{
 				Name:   "warmup",
 				Usage:  "Fetch data and cache them",
 				Action: command.WarmupCacheAction,
 			},
+			refreshCommand.GetCommand(),
+			statusCommand.GetCommand(),
+			queryCommand.GetCommand(),
 			{
 				Name:   "dump",
 				Usage:  "Print current cache content",
@@ -40,107 +64,23 @@ func (command *Command) GetCommand() cli.Command {
 	}
 }
 
-// ClearCacheAction wipe out the cache
-func (command *Command) ClearCacheAction(context *cli.Context) error {
-	fmt.Println("Clearing cache... ")
-	return command.Settings.GetFileCache().Clear()
-}
-
 // WarmupCacheAction load all entities and save them into a file
 func (command *Command) WarmupCacheAction(context *cli.Context) error {
-	fmt.Println("Warming up cache...")
-	client, err := command.Settings.GetAPIClient()
-	if err != nil {
-		return err
-	}
-
-	cache := command.Settings.GetFileCache()
-
-	var limit uint
-	var offset uint
-	var isLastPage bool
-
-	cache.Clear()
-
-	fmt.Printf("Loading users...")
-	limit = 1000
-	offset = uint(0)
-	isLastPage = false
-
-	for !isLastPage {
-		userResponse, err := client.GetUsers(bitclient.PagedRequest{
-			Limit: limit,
-			Start: offset,
-		})
-		if err != nil {
-			return err
-		}
-
-		cache.Users = append(cache.Users, userResponse.Values...)
-
-		isLastPage = userResponse.IsLastPage
-
-		offset += limit
-
+	refreshCommand := &RefreshCommand{
+		Settings: command.Settings,
+		flags:    &RefreshCommandFlags{},
 	}
-	fmt.Println("done")
-	fmt.Printf("Cached %d users\n", len(cache.Users))
-
-	fmt.Printf("Loading projects...")
-	fmt.Printf("Loading users...")
-	limit = 1000
-	offset = uint(0)
-	isLastPage = false
-	for !isLastPage {
-		projectResponse, err := client.GetProjects(bitclient.PagedRequest{
-			Limit: limit,
-			Start: offset,
-		})
-		if err != nil {
-			return err
-		}
-		cache.Projects = append(cache.Projects, projectResponse.Values...)
-
-		isLastPage = projectResponse.IsLastPage
-
-		offset += limit
-	}
-	fmt.Println("done")
-	fmt.Printf("Cached %d projects\n", len(cache.Projects))
-
-	fmt.Printf("Loading repositories...")
-	for _, project := range cache.Projects {
-
-		limit = 1000
-		offset = uint(0)
-		isLastPage = false
 
-		for !isLastPage {
-			repositoryResponse, err := client.GetRepositories(project.Key, bitclient.PagedRequest{
-				Limit: limit,
-				Start: offset,
-			})
-
-			if err != nil {
-				return err
-			}
-
-			cache.Repositories = append(cache.Repositories, repositoryResponse.Values...)
-
-			isLastPage = repositoryResponse.IsLastPage
-
-			offset += limit
-		}
-	}
-	fmt.Println("done")
-	fmt.Printf("Cached %d repositories\n", len(cache.Repositories))
-
-	fmt.Println("\nCache warmup completed")
-	return cache.Save()
+	return refreshCommand.RefreshAction(context)
 }
 
 // DumpCacheAction print on stdout the content of the cache
 func (command *Command) DumpCacheAction(context *cli.Context) error {
-	fmt.Println(command.Settings.GetFileCache())
+	cache, err := command.Settings.LoadFileCache()
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(cache)
 	return nil
 }
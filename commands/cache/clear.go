@@ -0,0 +1,89 @@
+// Package cache provide actions for loading / clearing / dumping the users, repositories, groups, projects from Bitbucket
+// It aims to provide fluid autocompletion and avoid hitting the API while searching for specific entities.
+package cache
+
+import (
+	"fmt"
+
+	"github.com/daeMOn63/bitadmin/helper"
+	"github.com/daeMOn63/bitadmin/settings"
+	"github.com/urfave/cli"
+)
+
+// ClearCommand define the command removing cached data
+type ClearCommand struct {
+	Settings *settings.BitAdminSettings
+	flags    *ClearCommandFlags
+}
+
+// ClearCommandFlags define the flags of the ClearCommand
+type ClearCommandFlags struct {
+	server string
+	all    bool
+}
+
+// GetCommand provide a ready to use cli.Command
+func (command *ClearCommand) GetCommand() cli.Command {
+	return cli.Command{
+		Name:   "clear",
+		Usage:  "Clear the cached data of the current server and user",
+		Action: command.ClearAction,
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:        "server",
+				Usage:       "Clear the caches of every user of the server at `<url>` instead",
+				Destination: &command.flags.server,
+			},
+			cli.BoolFlag{
+				Name:        "all",
+				Usage:       "Clear the caches of every server and user instead",
+				Destination: &command.flags.all,
+			},
+		},
+	}
+}
+
+// ClearAction wipe out the current cache, or remove the caches of the given server
+func (command *ClearCommand) ClearAction(context *cli.Context) error {
+	if len(command.flags.server) == 0 && !command.flags.all {
+		cache, err := command.Settings.LoadFileCache()
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Clearing cache %s...\n", cache.Dir())
+
+		return cache.ClearAll()
+	}
+
+	if len(command.flags.server) > 0 && command.flags.all {
+		return fmt.Errorf("--server and --all flags cannot be used together")
+	}
+
+	caches, err := helper.ListFileCaches(command.Settings.CacheRoot)
+	if err != nil {
+		return err
+	}
+
+	server := helper.NormalizeServerURL(command.flags.server)
+	removed := 0
+
+	for _, cache := range caches {
+		if !command.flags.all && cache.Server() != server {
+			continue
+		}
+
+		if err := cache.Remove(); err != nil {
+			return err
+		}
+
+		fmt.Printf("[OK] Removed cache of %s on %s\n", cache.User(), cache.Server())
+		removed++
+	}
+
+	if removed == 0 {
+		fmt.Println("[SKIP] No cache to remove")
+	}
+
+	return nil
+}
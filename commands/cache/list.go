@@ -0,0 +1,118 @@
+// Package cache provide actions for loading / clearing / dumping the users, repositories, groups, projects from Bitbucket
+// It aims to provide fluid autocompletion and avoid hitting the API while searching for specific entities.
+package cache
+
+import (
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/daeMOn63/bitadmin/helper"
+	"github.com/daeMOn63/bitadmin/settings"
+	"github.com/urfave/cli"
+)
+
+// ListCommand define the command listing the caches of every server and user
+type ListCommand struct {
+	Settings *settings.BitAdminSettings
+	flags    *ListCommandFlags
+}
+
+// ListCommandFlags define the flags of the ListCommand
+type ListCommandFlags struct {
+	format string
+}
+
+// cacheSummary describe a single server and user cache
+type cacheSummary struct {
+	Server       string `json:"server"`
+	User         string `json:"user"`
+	Directory    string `json:"directory"`
+	Users        int    `json:"users"`
+	Projects     int    `json:"projects"`
+	Repositories int    `json:"repositories"`
+	LastFetch    string `json:"lastFetch"`
+	Current      bool   `json:"current"`
+}
+
+// GetCommand provide a ready to use cli.Command
+func (command *ListCommand) GetCommand() cli.Command {
+	return cli.Command{
+		Name:   "list",
+		Usage:  "List the caches of every server and user",
+		Action: command.ListAction,
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:        "format",
+				Usage:       "Output `<format>`, one of table, json or csv",
+				Value:       helper.FormatTable,
+				Destination: &command.flags.format,
+			},
+		},
+	}
+}
+
+// ListAction print a summary of each cache, marking the one used with the current global flags
+func (command *ListCommand) ListAction(context *cli.Context) error {
+	if err := helper.ValidateFormat(command.flags.format); err != nil {
+		return err
+	}
+
+	caches, err := helper.ListFileCaches(command.Settings.CacheRoot)
+	if err != nil {
+		return err
+	}
+
+	// No cache is marked when the global flags do not select one
+	currentDir, _ := command.Settings.GetCacheDir()
+
+	var summaries []cacheSummary
+	var rows [][]string
+
+	for _, cache := range caches {
+		summary := cacheSummary{
+			Server:       cache.Server(),
+			User:         cache.User(),
+			Directory:    cache.Dir(),
+			Users:        cache.Count(helper.CacheUsers),
+			Projects:     cache.Count(helper.CacheProjects),
+			Repositories: cache.Count(helper.CacheRepositories),
+			LastFetch:    "-",
+			Current:      cache.Dir() == currentDir,
+		}
+
+		var lastFetch time.Time
+		for _, namespace := range helper.CacheNamespaces {
+			if fetchedAt, ok := cache.FetchedAt(namespace); ok && fetchedAt.After(lastFetch) {
+				lastFetch = fetchedAt
+			}
+		}
+		if !lastFetch.IsZero() {
+			summary.LastFetch = lastFetch.Format(time.RFC3339)
+		}
+
+		current := ""
+		if summary.Current {
+			current = "*"
+		}
+
+		summaries = append(summaries, summary)
+		rows = append(rows, []string{
+			current,
+			summary.Server,
+			summary.User,
+			strconv.Itoa(summary.Users),
+			strconv.Itoa(summary.Projects),
+			strconv.Itoa(summary.Repositories),
+			summary.LastFetch,
+		})
+	}
+
+	return helper.WriteFormatted(
+		os.Stdout,
+		command.flags.format,
+		[]string{"", "SERVER", "USER", "USERS", "PROJECTS", "REPOSITORIES", "LAST FETCH"},
+		rows,
+		summaries,
+	)
+}
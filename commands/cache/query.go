@@ -0,0 +1,341 @@
+// Package cache provide actions for loading / clearing / dumping the users, repositories, groups, projects from Bitbucket
+// It aims to provide fluid autocompletion and avoid hitting the API while searching for specific entities.
+package cache
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/daeMOn63/bitadmin/helper"
+	"github.com/daeMOn63/bitadmin/settings"
+	"github.com/urfave/cli"
+)
+
+// queryEntity define an entity type which can be queried, with the fields displayed by default
+type queryEntity struct {
+	name    string
+	fields  []string
+	records func(cache *helper.FileCache) ([]helper.Record, error)
+}
+
+// queryEntities list the entity types, in the order they are displayed in the help
+var queryEntities = []queryEntity{
+	{"users", []string{"slug", "displayName", "emailAddress", "active"}, userRecords},
+	{"projects", []string{"key", "name", "public", "repositories"}, projectRecords},
+	{"repositories", []string{"project.key", "slug", "name", "forkable", "public"}, repositoryRecords},
+	{"groups", []string{"name"}, groupRecords},
+	{"hooks", []string{"key", "name", "type", "version"}, hookRecords},
+	{"branches", []string{"project", "repository", "branch", "default"}, branchRecords},
+	{"permissions", []string{"project", "repository", "type", "name", "permission"}, permissionRecords},
+}
+
+// QueryCommand define the command querying the cached entities
+type QueryCommand struct {
+	Settings *settings.BitAdminSettings
+	flags    *QueryCommandFlags
+}
+
+// QueryCommandFlags define the flags of the QueryCommand
+type QueryCommandFlags struct {
+	where  cli.StringSlice
+	fields string
+	sort   string
+	format string
+}
+
+// GetCommand provide a ready to use cli.Command
+func (command *QueryCommand) GetCommand() cli.Command {
+	var entityNames []string
+	for _, entity := range queryEntities {
+		entityNames = append(entityNames, entity.name)
+	}
+
+	return cli.Command{
+		Name:      "query",
+		Usage:     "Filter and display cached entities without hitting the api",
+		ArgsUsage: "<" + strings.Join(entityNames, "|") + ">",
+		Description: "Examples:\n" +
+			"   bitadmin cache query repositories --where project.key=PRJ --where forkable=false\n" +
+			"   bitadmin cache query users --where 'emailAddress=*@example.com' --fields slug,emailAddress\n" +
+			"   bitadmin cache query users --where active=false --format csv\n" +
+			"   bitadmin cache query projects --where repositories=0",
+		Action: command.QueryAction,
+		Flags: []cli.Flag{
+			cli.StringSliceFlag{
+				Name:  "where",
+				Usage: "Only keep the entities matching `<field><operator><value>`. Operators are = and != (shell patterns allowed), ~ (regular expression), >, >=, < and <=. Can be repeated multiple times, all conditions must match",
+				Value: &command.flags.where,
+			},
+			cli.StringFlag{
+				Name:        "fields",
+				Usage:       "Comma separated `<fields>` to display, nested fields are joined with dots (ie: project.key). Use * for all fields",
+				Destination: &command.flags.fields,
+			},
+			cli.StringFlag{
+				Name:        "sort",
+				Usage:       "Sort the entities by `<field>`",
+				Destination: &command.flags.sort,
+			},
+			cli.StringFlag{
+				Name:        "format",
+				Usage:       "Output `<format>`, one of table, json or csv",
+				Value:       helper.FormatTable,
+				Destination: &command.flags.format,
+			},
+		},
+		BashComplete: func(c *cli.Context) {
+			if c.NArg() == 0 {
+				for _, entity := range entityNames {
+					helper.PrintCompletion(c.App.Writer, entity, "")
+				}
+				return
+			}
+			helper.AutoComplete(c, command.Settings.GetFileCache())
+		},
+	}
+}
+
+// QueryAction print the cached entities of the requested type matching all the filters
+func (command *QueryCommand) QueryAction(context *cli.Context) error {
+	if err := helper.ValidateFormat(command.flags.format); err != nil {
+		return err
+	}
+
+	entity, err := findQueryEntity(context.Args().First())
+	if err != nil {
+		return err
+	}
+
+	var filters []helper.QueryFilter
+	for _, expression := range command.flags.where {
+		filter, err := helper.ParseQueryFilter(expression)
+		if err != nil {
+			return err
+		}
+		filters = append(filters, filter)
+	}
+
+	cache, err := command.Settings.LoadFileCache()
+	if err != nil {
+		return err
+	}
+
+	records, err := entity.records(cache)
+	if err != nil {
+		return err
+	}
+
+	available := helper.RecordFields(records)
+
+	fields := entity.fields
+	if command.flags.fields == "*" {
+		fields = available
+	} else if len(command.flags.fields) > 0 {
+		fields = strings.Split(command.flags.fields, ",")
+	}
+
+	// Unknown fields are most likely typos, which would silently match nothing
+	checked := append(append([]string{}, fields...), command.flags.sort)
+	for _, filter := range filters {
+		checked = append(checked, filter.Field)
+	}
+	for _, field := range checked {
+		if len(field) > 0 && len(records) > 0 && !hasField(available, field) {
+			return fmt.Errorf("unknown field %s for %s, available fields are: %s", field, entity.name, strings.Join(available, ", "))
+		}
+	}
+
+	var matching []helper.Record
+	for _, record := range records {
+		if matchAll(record, filters) {
+			matching = append(matching, record)
+		}
+	}
+
+	if len(command.flags.sort) > 0 {
+		sort.SliceStable(matching, func(i, j int) bool {
+			return lessRecordValue(matching[i], matching[j], command.flags.sort)
+		})
+	}
+
+	var rows [][]string
+	var projected []map[string]interface{}
+
+	for _, record := range matching {
+		row := make([]string, len(fields))
+		object := make(map[string]interface{})
+		for i, field := range fields {
+			value, _ := record.Get(field)
+			row[i] = helper.FormatRecordValue(value)
+			object[field] = value
+		}
+		rows = append(rows, row)
+		projected = append(projected, object)
+	}
+
+	if projected == nil {
+		projected = []map[string]interface{}{}
+	}
+
+	return helper.WriteFormatted(os.Stdout, command.flags.format, fields, rows, projected)
+}
+
+// findQueryEntity return the entity type named name
+func findQueryEntity(name string) (queryEntity, error) {
+	var names []string
+	for _, entity := range queryEntities {
+		if entity.name == name {
+			return entity, nil
+		}
+		names = append(names, entity.name)
+	}
+
+	if len(name) == 0 {
+		return queryEntity{}, fmt.Errorf("an entity type is required, one of %s", strings.Join(names, ", "))
+	}
+
+	return queryEntity{}, fmt.Errorf("unknown entity type %s, must be one of %s", name, strings.Join(names, ", "))
+}
+
+func hasField(fields []string, field string) bool {
+	for _, available := range fields {
+		if strings.EqualFold(available, field) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func matchAll(record helper.Record, filters []helper.QueryFilter) bool {
+	for _, filter := range filters {
+		if !filter.Match(record) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// lessRecordValue compare two records on field, numerically when both values are numbers
+func lessRecordValue(a helper.Record, b helper.Record, field string) bool {
+	valueA, _ := a.Get(field)
+	valueB, _ := b.Get(field)
+
+	textA := helper.FormatRecordValue(valueA)
+	textB := helper.FormatRecordValue(valueB)
+
+	numberA, errA := strconv.ParseFloat(textA, 64)
+	numberB, errB := strconv.ParseFloat(textB, 64)
+	if errA == nil && errB == nil {
+		return numberA < numberB
+	}
+
+	return textA < textB
+}
+
+// toRecords flatten each entity of the slice
+func toRecords(count int, entity func(i int) interface{}) ([]helper.Record, error) {
+	var records []helper.Record
+	for i := 0; i < count; i++ {
+		record, err := helper.NewRecord(entity(i))
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+func userRecords(cache *helper.FileCache) ([]helper.Record, error) {
+	users := cache.Users()
+
+	return toRecords(len(users), func(i int) interface{} { return users[i] })
+}
+
+func projectRecords(cache *helper.FileCache) ([]helper.Record, error) {
+	projects := cache.Projects()
+
+	records, err := toRecords(len(projects), func(i int) interface{} { return projects[i] })
+	if err != nil {
+		return nil, err
+	}
+
+	// Computed field, so projects without repositories can be found
+	for i, project := range projects {
+		records[i]["repositories"] = float64(len(cache.ProjectRepositories(project.Key)))
+	}
+
+	return records, nil
+}
+
+func repositoryRecords(cache *helper.FileCache) ([]helper.Record, error) {
+	repositories := cache.Repositories()
+
+	return toRecords(len(repositories), func(i int) interface{} { return repositories[i] })
+}
+
+func groupRecords(cache *helper.FileCache) ([]helper.Record, error) {
+	var records []helper.Record
+	for _, group := range cache.Groups() {
+		records = append(records, helper.Record{"name": group})
+	}
+
+	return records, nil
+}
+
+func hookRecords(cache *helper.FileCache) ([]helper.Record, error) {
+	hooks := cache.Hooks()
+
+	return toRecords(len(hooks), func(i int) interface{} { return hooks[i] })
+}
+
+func branchRecords(cache *helper.FileCache) ([]helper.Record, error) {
+	var records []helper.Record
+	for _, project := range cache.Projects() {
+		for slug, branches := range cache.ProjectBranches(project.Key) {
+			for _, branch := range branches.Branches {
+				records = append(records, helper.Record{
+					"project":    project.Key,
+					"repository": slug,
+					"branch":     branch,
+					"default":    branch == branches.DefaultBranch,
+				})
+			}
+		}
+	}
+
+	return records, nil
+}
+
+func permissionRecords(cache *helper.FileCache) ([]helper.Record, error) {
+	var records []helper.Record
+	for _, project := range cache.Projects() {
+		for slug, snapshot := range cache.ProjectPermissions(project.Key) {
+			for _, permission := range snapshot.Users {
+				records = append(records, helper.Record{
+					"project":    project.Key,
+					"repository": slug,
+					"type":       "user",
+					"name":       permission.User.Slug,
+					"permission": permission.Permission,
+				})
+			}
+			for _, permission := range snapshot.Groups {
+				records = append(records, helper.Record{
+					"project":    project.Key,
+					"repository": slug,
+					"type":       "group",
+					"name":       permission.Group.Name,
+					"permission": permission.Permission,
+				})
+			}
+		}
+	}
+
+	return records, nil
+}
@@ -0,0 +1,301 @@
+// Package cache provide actions for loading / clearing / dumping the users, repositories, groups, projects from Bitbucket
+// It aims to provide fluid autocompletion and avoid hitting the API while searching for specific entities.
+package cache
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/daeMOn63/bitadmin/helper"
+	"github.com/daeMOn63/bitadmin/settings"
+	"github.com/daeMOn63/bitclient"
+	"github.com/urfave/cli"
+)
+
+// RefreshCommand define the command refreshing the whole cache or some parts of it
+type RefreshCommand struct {
+	Settings *settings.BitAdminSettings
+	flags    *RefreshCommandFlags
+}
+
+// RefreshCommandFlags define the flags of the RefreshCommand
+type RefreshCommandFlags struct {
+	users        bool
+	projects     bool
+	repositories bool
+	project      cli.StringSlice
+	groups       bool
+	hooks        bool
+	branches     bool
+	permissions  bool
+	everything   bool
+}
+
+// all is true when no part is selected, meaning users, projects and repositories must be refreshed
+func (flags *RefreshCommandFlags) all() bool {
+	return flags.everything || (!flags.users && !flags.projects && !flags.repositories && len(flags.project) == 0 &&
+		!flags.groups && !flags.hooks && !flags.branches && !flags.permissions)
+}
+
+// GetCommand provide a ready to use cli.Command
+func (command *RefreshCommand) GetCommand() cli.Command {
+	return cli.Command{
+		Name:   "refresh",
+		Usage:  "Fetch fresh data and replace the cached ones, everything is refreshed when no flag is given",
+		Action: command.RefreshAction,
+		Flags: []cli.Flag{
+			cli.BoolFlag{
+				Name:        "users",
+				Usage:       "Refresh the users",
+				Destination: &command.flags.users,
+			},
+			cli.BoolFlag{
+				Name:        "projects",
+				Usage:       "Refresh the projects",
+				Destination: &command.flags.projects,
+			},
+			cli.BoolFlag{
+				Name:        "repositories",
+				Usage:       "Refresh the repositories of every project",
+				Destination: &command.flags.repositories,
+			},
+			cli.StringSliceFlag{
+				Name:  "project",
+				Usage: "Refresh the repositories of the `<project_key>` only, also restricting --branches and --permissions. Can be repeated multiple times",
+				Value: &command.flags.project,
+			},
+			cli.BoolFlag{
+				Name:        "groups",
+				Usage:       "Refresh the groups",
+				Destination: &command.flags.groups,
+			},
+			cli.BoolFlag{
+				Name:        "hooks",
+				Usage:       "Refresh the catalogue of installed hooks",
+				Destination: &command.flags.hooks,
+			},
+			cli.BoolFlag{
+				Name:        "branches",
+				Usage:       "Refresh the default branch and branches of every repository. Require an api call per repository",
+				Destination: &command.flags.branches,
+			},
+			cli.BoolFlag{
+				Name:        "permissions",
+				Usage:       "Refresh the permissions of every project and repository. Require two api calls per repository",
+				Destination: &command.flags.permissions,
+			},
+			cli.BoolFlag{
+				Name:        "all",
+				Usage:       "Refresh everything, including the groups, hooks, branches and permissions",
+				Destination: &command.flags.everything,
+			},
+		},
+		BashComplete: func(c *cli.Context) {
+			helper.AutoComplete(c, command.Settings.GetFileCache())
+		},
+	}
+}
+
+// RefreshAction fetch the selected entities and swap them in the cache.
+// Nothing is saved until every fetch succeeded, so a failure leaves the previous cache untouched.
+func (command *RefreshCommand) RefreshAction(context *cli.Context) error {
+	client, err := command.Settings.GetAPIClient()
+	if err != nil {
+		return err
+	}
+
+	cache, err := command.Settings.LoadFileCache()
+	if err != nil {
+		return err
+	}
+
+	all := command.flags.all()
+
+	var users []bitclient.User
+	if all || command.flags.users {
+		fmt.Printf("Loading users...")
+		users, err = helper.GetAllUsers(client)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("done, %d users\n", len(users))
+	}
+
+	var projects []bitclient.Project
+	if all || command.flags.projects || command.flags.repositories {
+		fmt.Printf("Loading projects...")
+		projects, err = helper.GetAllProjects(client)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("done, %d projects\n", len(projects))
+	}
+
+	var repositories []bitclient.Repository
+	if all || command.flags.repositories {
+		fmt.Printf("Loading repositories...")
+		for _, project := range projects {
+			projectRepositories, err := helper.GetAllRepositories(client, project.Key)
+			if err != nil {
+				return err
+			}
+			repositories = append(repositories, projectRepositories...)
+		}
+		fmt.Printf("done, %d repositories\n", len(repositories))
+	}
+
+	projectRepositories := make(map[string][]bitclient.Repository)
+	for _, projectKey := range command.flags.project {
+		fmt.Printf("Loading repositories of project %s...", projectKey)
+		projectRepositories[projectKey], err = helper.GetAllRepositories(client, projectKey)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("done, %d repositories\n", len(projectRepositories[projectKey]))
+	}
+
+	if all || command.flags.users {
+		cache.SetUsers(users)
+		cache.Touch(helper.CacheUsers)
+	}
+
+	if all || command.flags.projects {
+		cache.SetProjects(projects)
+		cache.Touch(helper.CacheProjects)
+	}
+
+	if all || command.flags.repositories {
+		cache.SetRepositories(repositories)
+		cache.Touch(helper.CacheRepositories)
+	}
+
+	for projectKey, repositories := range projectRepositories {
+		cache.SetProjectRepositories(projectKey, repositories)
+	}
+
+	if err := command.refreshInventory(cache); err != nil {
+		return err
+	}
+
+	err = cache.Save()
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("[OK] Cache refreshed")
+
+	return nil
+}
+
+// refreshInventory fetch the optional namespaces selected by the flags and swap them in the cache.
+// Repositories are read from the cache, so they must be refreshed first.
+func (command *RefreshCommand) refreshInventory(cache *helper.FileCache) error {
+	flags := command.flags
+	if !flags.everything && !flags.groups && !flags.hooks && !flags.branches && !flags.permissions {
+		return nil
+	}
+
+	restClient, err := command.Settings.GetRestClient()
+	if err != nil {
+		return err
+	}
+
+	if flags.everything || flags.groups {
+		fmt.Printf("Loading groups...")
+		groups, err := restClient.GetGroups()
+		if err != nil {
+			return err
+		}
+		cache.SetGroups(groups)
+		cache.Touch(helper.CacheGroups)
+		fmt.Printf("done, %d groups\n", len(groups))
+	}
+
+	projectKeys := []string(flags.project)
+	if len(projectKeys) == 0 {
+		for _, project := range cache.Projects() {
+			projectKeys = append(projectKeys, project.Key)
+		}
+	}
+
+	if flags.everything || flags.hooks {
+		fmt.Printf("Loading hooks...")
+		var hooks []helper.HookDetails
+		// Every project expose the whole catalogue of installed hooks
+		if len(projectKeys) > 0 {
+			projectHooks, err := restClient.GetRepositoryHooks(projectKeys[0], "")
+			if err != nil {
+				return err
+			}
+			for _, hook := range projectHooks {
+				hooks = append(hooks, hook.Details)
+			}
+		}
+		cache.SetHooks(hooks)
+		// With --project, the catalogue is read from a project chosen by the user instead of the whole server,
+		// so it is kept without being marked fresh
+		if len(flags.project) == 0 && len(projectKeys) > 0 {
+			cache.Touch(helper.CacheHooks)
+		}
+		fmt.Printf("done, %d hooks\n", len(hooks))
+	}
+
+	if flags.everything || flags.branches {
+		fmt.Printf("Loading branches...")
+		count := 0
+		for _, projectKey := range projectKeys {
+			projectBranches := make(map[string]helper.RepositoryBranches)
+			for _, repository := range cache.ProjectRepositories(projectKey) {
+				branches, err := restClient.GetAllBranches(projectKey, repository.Slug)
+				if err != nil {
+					return fmt.Errorf("cannot load branches of %s/%s: %w", projectKey, repository.Slug, err)
+				}
+
+				repositoryBranches := helper.RepositoryBranches{}
+				for _, branch := range branches {
+					repositoryBranches.Branches = append(repositoryBranches.Branches, branch.ID)
+					if branch.IsDefault {
+						repositoryBranches.DefaultBranch = branch.ID
+					}
+				}
+				projectBranches[repository.Slug] = repositoryBranches
+				count += len(branches)
+			}
+			cache.SetProjectBranches(projectKey, projectBranches)
+		}
+		if len(flags.project) == 0 {
+			cache.Touch(helper.CacheBranches)
+		}
+		fmt.Printf("done, %d branches\n", count)
+	}
+
+	if flags.everything || flags.permissions {
+		fmt.Printf("Loading permissions...")
+		count := 0
+		for _, projectKey := range projectKeys {
+			projectPermissions := make(map[string]helper.PermissionSnapshot)
+
+			// The project permissions are stored with an empty repository slug
+			slugs := []string{""}
+			for _, repository := range cache.ProjectRepositories(projectKey) {
+				slugs = append(slugs, repository.Slug)
+			}
+
+			for _, slug := range slugs {
+				snapshot, err := restClient.GetPermissions(projectKey, slug)
+				if err != nil {
+					return fmt.Errorf("cannot load permissions of %s: %w", strings.TrimSuffix(projectKey+"/"+slug, "/"), err)
+				}
+				projectPermissions[slug] = snapshot
+				count += len(snapshot.Users) + len(snapshot.Groups)
+			}
+			cache.SetProjectPermissions(projectKey, projectPermissions)
+		}
+		if len(flags.project) == 0 {
+			cache.Touch(helper.CachePermissions)
+		}
+		fmt.Printf("done, %d permissions\n", count)
+	}
+
+	return nil
+}
@@ -0,0 +1,102 @@
+// Package cache provide actions for loading / clearing / dumping the users, repositories, groups, projects from Bitbucket
+// It aims to provide fluid autocompletion and avoid hitting the API while searching for specific entities.
+package cache
+
+import (
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/daeMOn63/bitadmin/helper"
+	"github.com/daeMOn63/bitadmin/settings"
+	"github.com/urfave/cli"
+)
+
+// StatusCommand define the command reporting the freshness of the cached data
+type StatusCommand struct {
+	Settings *settings.BitAdminSettings
+	flags    *StatusCommandFlags
+}
+
+// StatusCommandFlags define the flags of the StatusCommand
+type StatusCommandFlags struct {
+	format string
+}
+
+// namespaceStatus hold the state of a single cached namespace
+type namespaceStatus struct {
+	Namespace string     `json:"namespace"`
+	Entries   int        `json:"entries"`
+	FetchedAt *time.Time `json:"fetchedAt"`
+	Age       string     `json:"age"`
+	Status    string     `json:"status"`
+}
+
+// GetCommand provide a ready to use cli.Command
+func (command *StatusCommand) GetCommand() cli.Command {
+	return cli.Command{
+		Name:   "status",
+		Usage:  "Show when each part of the cache was fetched and if it is stale",
+		Action: command.StatusAction,
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:        "format",
+				Usage:       "Output `<format>`, one of table, json or csv",
+				Value:       helper.FormatTable,
+				Destination: &command.flags.format,
+			},
+		},
+	}
+}
+
+// StatusAction print the entries count, fetch time and staleness of every cached namespace
+func (command *StatusCommand) StatusAction(context *cli.Context) error {
+	if err := helper.ValidateFormat(command.flags.format); err != nil {
+		return err
+	}
+
+	cache, err := command.Settings.LoadFileCache()
+	if err != nil {
+		return err
+	}
+
+	var statuses []namespaceStatus
+	var rows [][]string
+
+	namespaces := append(append([]string{}, helper.CacheNamespaces...), helper.OptionalCacheNamespaces...)
+
+	for _, namespace := range namespaces {
+		status := namespaceStatus{
+			Namespace: namespace,
+			Entries:   cache.Count(namespace),
+			Age:       "-",
+			Status:    "never fetched",
+		}
+
+		if age, ok := cache.Age(namespace); ok {
+			fetchedAt, _ := cache.FetchedAt(namespace)
+			status.FetchedAt = &fetchedAt
+			status.Age = age.Round(time.Second).String()
+			status.Status = "fresh"
+			if cache.IsStale(namespace) {
+				status.Status = "stale"
+			}
+		}
+
+		fetchedAt := "-"
+		if status.FetchedAt != nil {
+			fetchedAt = status.FetchedAt.Format(time.RFC3339)
+		}
+
+		statuses = append(statuses, status)
+		rows = append(rows, []string{namespace, strconv.Itoa(status.Entries), fetchedAt, status.Age, status.Status})
+	}
+
+	return helper.WriteFormatted(
+		os.Stdout,
+		command.flags.format,
+		[]string{"NAMESPACE", "ENTRIES", "FETCHED AT", "AGE", "STATUS"},
+		rows,
+		statuses,
+	)
+}
@@ -0,0 +1,167 @@
+// Package completion provide the shell scripts enabling the bitadmin autocompletion
+package completion
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/daeMOn63/bitadmin/helper"
+	"github.com/urfave/cli"
+)
+
+// Command define the command printing the completion script of a shell
+type Command struct{}
+
+// scriptData hold the values available in the completion scripts
+type scriptData struct {
+	Name     string
+	Function string
+	Env      string
+	Shell    string
+}
+
+// scripts are the completion script templates, by shell name. Each script run bitadmin with the words typed so far
+// and --generate-bash-completion, the candidates being printed one per line, optionally followed by a tab and a
+// description.
+var scripts = map[string]string{
+	"bash": `# bash completion for {{.Name}}
+# Enable it with: source <({{.Name}} completion bash)
+
+_{{.Function}}_complete() {
+    local cur opts IFS=$'\n'
+    COMPREPLY=()
+    cur="${COMP_WORDS[COMP_CWORD]}"
+    opts=$({{.Env}}={{.Shell}} "${COMP_WORDS[@]:0:$COMP_CWORD}" --generate-bash-completion 2>/dev/null)
+    COMPREPLY=($(compgen -W "${opts}" -- "${cur}"))
+    return 0
+}
+
+complete -o default -F _{{.Function}}_complete {{.Name}}
+`,
+	"zsh": `#compdef {{.Name}}
+# zsh completion for {{.Name}}
+# Enable it with: source <({{.Name}} completion zsh)
+# or save it as _{{.Name}} in a directory of your $fpath
+
+_{{.Function}}() {
+    local -a candidates
+    local line value
+    for line in "${(@f)$({{.Env}}={{.Shell}} "${words[@]:0:$((CURRENT-1))}" --generate-bash-completion 2>/dev/null)}"; do
+        [[ -z "$line" ]] && continue
+        value="${line%%$'\t'*}"
+        if [[ "$line" == *$'\t'* ]]; then
+            candidates+=("${value//:/\\:}:${line#*$'\t'}")
+        else
+            candidates+=("${value//:/\\:}")
+        fi
+    done
+
+    if (( ${#candidates} )); then
+        _describe -t values '{{.Name}}' candidates
+    else
+        _files
+    fi
+}
+
+if [[ "${funcstack[1]}" == "_{{.Function}}" ]]; then
+    _{{.Function}} "$@"
+else
+    compdef _{{.Function}} {{.Name}}
+fi
+`,
+	"fish": `# fish completion for {{.Name}}
+# Enable it with: {{.Name}} completion fish | source
+# or save it as ~/.config/fish/completions/{{.Name}}.fish
+
+function __{{.Function}}_complete
+    set -l args (commandline -opc)
+    env {{.Env}}={{.Shell}} $args --generate-bash-completion 2>/dev/null
+end
+
+complete -c {{.Name}} -f -a '(__{{.Function}}_complete)'
+`,
+	"powershell": `# PowerShell completion for {{.Name}}
+# Enable it with: {{.Name}} completion powershell | Out-String | Invoke-Expression
+
+Register-ArgumentCompleter -Native -CommandName '{{.Name}}' -ScriptBlock {
+    param($wordToComplete, $commandAst, $cursorPosition)
+
+    $words = @($commandAst.CommandElements | ForEach-Object { $_.ToString() })
+    if ($wordToComplete -ne '' -and $words.Count -gt 1) {
+        $words = $words[0..($words.Count - 2)]
+    }
+
+    $env:{{.Env}} = '{{.Shell}}'
+    $candidates = & $words[0] @($words | Select-Object -Skip 1) --generate-bash-completion 2>$null
+    Remove-Item Env:{{.Env}}
+
+    $candidates | Where-Object { $_ -ne '' } | ForEach-Object {
+        $value, $description = $_ -split [char]9, 2
+        if (-not $description) {
+            $description = $value
+        }
+        if ($value -like "$wordToComplete*") {
+            [System.Management.Automation.CompletionResult]::new($value, $value, 'ParameterValue', $description)
+        }
+    }
+}
+`,
+}
+
+// shells give the supported shell names, sorted
+func shells() []string {
+	var names []string
+	for name := range scripts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// GetCommand provide a ready to use cli.Command
+func (command *Command) GetCommand() cli.Command {
+	return cli.Command{
+		Name:      "completion",
+		Usage:     "Print the autocompletion script of a shell, one of " + strings.Join(shells(), ", "),
+		ArgsUsage: "<shell>",
+		Action:    command.CompletionAction,
+		BashComplete: func(c *cli.Context) {
+			if c.NArg() > 0 {
+				return
+			}
+			for _, shell := range shells() {
+				helper.PrintCompletion(c.App.Writer, shell, "")
+			}
+		},
+	}
+}
+
+// CompletionAction print the completion script of the requested shell
+func (command *Command) CompletionAction(context *cli.Context) error {
+	shell := context.Args().First()
+	if len(shell) == 0 {
+		return fmt.Errorf("a shell is required, one of %s", strings.Join(shells(), ", "))
+	}
+
+	script, ok := scripts[shell]
+	if !ok {
+		return fmt.Errorf("unsupported shell %s, must be one of %s", shell, strings.Join(shells(), ", "))
+	}
+
+	tmpl, err := template.New(shell).Parse(script)
+	if err != nil {
+		return err
+	}
+
+	name := context.App.Name
+	return tmpl.Execute(os.Stdout, scriptData{
+		Name:     name,
+		Function: strings.NewReplacer("-", "_", ".", "_").Replace(name),
+		Env:      helper.CompletionShellEnv,
+		Shell:    shell,
+	})
+}
@@ -0,0 +1,81 @@
+// Package credentials provide the login and logout actions, saving the passwords in the OS keyring or a credential
+// helper so they don't have to be given on every call
+package credentials
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/daeMOn63/bitadmin/helper"
+	"github.com/daeMOn63/bitadmin/settings"
+	"github.com/urfave/cli"
+)
+
+// LoginCommand define the command validating and saving the credentials of the global flags
+type LoginCommand struct {
+	Settings *settings.BitAdminSettings
+}
+
+// GetCommand provide a ready to use cli.Command
+func (command *LoginCommand) GetCommand() cli.Command {
+	return cli.Command{
+		Name:   "login",
+		Usage:  "Validate the password of --user on --url and save it with the --credential-helper. The password is read from --password, or prompted",
+		Action: command.LoginAction,
+	}
+}
+
+// LoginAction check the credentials against the server before saving them
+func (command *LoginCommand) LoginAction(context *cli.Context) error {
+	if err := requireIdentity(command.Settings); err != nil {
+		return err
+	}
+
+	store, err := command.Settings.GetCredentialStore()
+	if err != nil {
+		return err
+	}
+
+	server := command.Settings.GetServer()
+
+	if len(command.Settings.PasswordFile) == 0 {
+		password, err := helper.ReadSecret(fmt.Sprintf("Password for %s on %s: ", command.Settings.Username, server))
+		if err != nil {
+			return fmt.Errorf("cannot read the password: %w", err)
+		}
+		if len(password) == 0 {
+			return errors.New("password cannot be empty")
+		}
+		command.Settings.Password = password
+	}
+
+	restClient, err := command.Settings.GetRestClient()
+	if err != nil {
+		return err
+	}
+
+	if err := restClient.CheckCredentials(); err != nil {
+		return fmt.Errorf("cannot log in as %s on %s: %w", command.Settings.Username, server, err)
+	}
+
+	if err := store.Store(server, command.Settings.Username, command.Settings.Password); err != nil {
+		return fmt.Errorf("cannot save the password in %s: %w", store.Name(), err)
+	}
+
+	fmt.Printf("[OK] Logged in as %s on %s, password saved in %s\n", command.Settings.Username, server, store.Name())
+
+	return nil
+}
+
+// requireIdentity check the global flags identifying the credentials are provided
+func requireIdentity(settings *settings.BitAdminSettings) error {
+	if len(settings.Username) == 0 {
+		return errors.New("global flag --user is required")
+	}
+
+	if len(settings.URL) == 0 {
+		return errors.New("global flag --url is required")
+	}
+
+	return nil
+}
@@ -0,0 +1,53 @@
+// Package credentials provide the login and logout actions, saving the passwords in the OS keyring or a credential
+// helper so they don't have to be given on every call
+package credentials
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/daeMOn63/bitadmin/helper"
+	"github.com/daeMOn63/bitadmin/settings"
+	"github.com/urfave/cli"
+)
+
+// LogoutCommand define the command removing the saved credentials of the global flags
+type LogoutCommand struct {
+	Settings *settings.BitAdminSettings
+}
+
+// GetCommand provide a ready to use cli.Command
+func (command *LogoutCommand) GetCommand() cli.Command {
+	return cli.Command{
+		Name:   "logout",
+		Usage:  "Remove the password of --user on --url from the --credential-helper",
+		Action: command.LogoutAction,
+	}
+}
+
+// LogoutAction erase the saved password
+func (command *LogoutCommand) LogoutAction(context *cli.Context) error {
+	if err := requireIdentity(command.Settings); err != nil {
+		return err
+	}
+
+	store, err := command.Settings.GetCredentialStore()
+	if err != nil {
+		return err
+	}
+
+	server := command.Settings.GetServer()
+
+	err = store.Erase(server, command.Settings.Username)
+	if errors.Is(err, helper.ErrCredentialNotFound) {
+		fmt.Printf("[SKIP] No password saved for %s on %s in %s\n", command.Settings.Username, server, store.Name())
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("cannot remove the password from %s: %w", store.Name(), err)
+	}
+
+	fmt.Printf("[OK] Logged out %s from %s, password removed from %s\n", command.Settings.Username, server, store.Name())
+
+	return nil
+}
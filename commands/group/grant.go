@@ -3,6 +3,8 @@ package group
 
 import (
 	"fmt"
+	"os"
+
 	"github.com/daeMOn63/bitadmin/helper"
 	"github.com/daeMOn63/bitadmin/settings"
 	"github.com/daeMOn63/bitclient"
@@ -52,7 +54,9 @@ func (command *GrantCommand) GetCommand() cli.Command {
 			},
 		},
 		BashComplete: func(c *cli.Context) {
-			helper.AutoComplete(c, command.Settings.GetFileCache())
+			helper.AutoCompleteWith(c, command.Settings.GetFileCache(), helper.CompletionProviders{
+				"name": helper.GroupValues(),
+			})
 		},
 	}
 }
@@ -81,6 +85,11 @@ func (command *GrantCommand) GrantAction(context *cli.Context) error {
 		return err
 	}
 
+	cache, err := command.Settings.LoadFileCache()
+	if err != nil {
+		return err
+	}
+
 	for _, name := range command.flags.names {
 		params := bitclient.SetRepositoryGroupPermissionRequest{
 			Name:       name,
@@ -107,6 +116,11 @@ func (command *GrantCommand) GrantAction(context *cli.Context) error {
 			name,
 			command.flags.permission,
 		)
+
+		cache.UpdateGroupPermission(command.flags.project, command.flags.repository, name, command.flags.permission)
+		if err := cache.Save(); err != nil {
+			fmt.Fprintf(os.Stderr, "[WARN] cannot update the cached permissions: %s\n", err)
+		}
 	}
 
 	return nil
@@ -3,6 +3,7 @@ package group
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/daeMOn63/bitadmin/helper"
 	"github.com/daeMOn63/bitadmin/settings"
@@ -47,7 +48,9 @@ func (command *UnsetPermissionsCommand) GetCommand() cli.Command {
 			},
 		},
 		BashComplete: func(c *cli.Context) {
-			helper.AutoComplete(c, command.Settings.GetFileCache())
+			helper.AutoCompleteWith(c, command.Settings.GetFileCache(), helper.CompletionProviders{
+				"name": helper.GroupValues(),
+			})
 		},
 	}
 }
@@ -71,6 +74,12 @@ func (command *UnsetPermissionsCommand) UnsetPermissionsAction(context *cli.Cont
 	if err != nil {
 		return err
 	}
+
+	cache, err := command.Settings.LoadFileCache()
+	if err != nil {
+		return err
+	}
+
 	for _, group := range command.flags.groups {
 		params := bitclient.UnsetRepositoryGroupPermissionRequest{
 			Name: group,
@@ -94,6 +103,11 @@ func (command *UnsetPermissionsCommand) UnsetPermissionsAction(context *cli.Cont
 			command.flags.repository,
 			group,
 		)
+
+		cache.UpdateGroupPermission(command.flags.project, command.flags.repository, group, "")
+		if err := cache.Save(); err != nil {
+			fmt.Fprintf(os.Stderr, "[WARN] cannot update the cached permissions: %s\n", err)
+		}
 	}
 
 	return nil
@@ -0,0 +1,261 @@
+// Package hooks hold actions on the Bitbucket hooks
+package hooks
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/daeMOn63/bitadmin/helper"
+	"github.com/daeMOn63/bitadmin/settings"
+	"github.com/urfave/cli"
+)
+
+// Compliance statuses displayed in the report
+const (
+	complianceStatusCompliant    = "compliant"
+	complianceStatusNonCompliant = "non-compliant"
+	complianceStatusRemediated   = "remediated"
+)
+
+// HookComplianceCommand define the command to check hook settings against a baseline
+type HookComplianceCommand struct {
+	Settings *settings.BitAdminSettings
+	flags    *HookComplianceCommandFlags
+}
+
+// HookComplianceCommandFlags define the flags for the HookComplianceCommand
+type HookComplianceCommandFlags struct {
+	project       string
+	repository    string
+	key           string
+	baselineFile  string
+	normalization cli.StringSlice
+	format        string
+	remediate     bool
+	override      bool
+}
+
+// HookComplianceRow hold the compliance result of a repository
+type HookComplianceRow struct {
+	Project     string                      `json:"project"`
+	Repository  string                      `json:"repository"`
+	Enabled     bool                        `json:"enabled"`
+	Inherited   bool                        `json:"inherited"`
+	Status      string                      `json:"status"`
+	Differences []helper.SettingsDifference `json:"differences,omitempty"`
+}
+
+// GetCommand provide a ready to use cli.Command
+func (command *HookComplianceCommand) GetCommand() cli.Command {
+	return cli.Command{
+		Name:   "compliance",
+		Usage:  "Check the settings of a hook across repositories against a baseline, exit with an error when a repository is not compliant",
+		Action: command.ComplianceAction,
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:        "project",
+				Usage:       "The `<project_key>` to check, shell patterns are allowed. Default to all projects",
+				Destination: &command.flags.project,
+			},
+			cli.StringFlag{
+				Name:        "repository",
+				Usage:       "The `<repository_slug>` to check, shell patterns are allowed. Default to all repositories",
+				Destination: &command.flags.repository,
+			},
+			cli.StringFlag{
+				Name:        "key",
+				Usage:       "The `<hook_key>` of the hook to check",
+				Destination: &command.flags.key,
+			},
+			cli.StringFlag{
+				Name:        "baseline-file",
+				Usage:       "The `<file>` holding the expected JSON or YAML settings",
+				Destination: &command.flags.baselineFile,
+			},
+			cli.StringSliceFlag{
+				Name:  "normalize",
+				Usage: "Normalize `<field>=<mode>` before comparison, mode being one of strip-whitespace, trim, lowercase, ignore. Use * as field to apply on all fields. Can be repeated multiple times",
+				Value: &command.flags.normalization,
+			},
+			cli.StringFlag{
+				Name:        "format",
+				Usage:       "The output `<format>`: table, json or csv",
+				Value:       helper.FormatTable,
+				Destination: &command.flags.format,
+			},
+			cli.BoolFlag{
+				Name:        "remediate",
+				Usage:       "Push the baseline settings on the non compliant repositories. Repositories inheriting the hook are fixed on their project",
+				Destination: &command.flags.remediate,
+			},
+			cli.BoolFlag{
+				Name:        "override",
+				Usage:       "With --remediate, write the baseline on the repositories inheriting the hook instead of their project",
+				Destination: &command.flags.override,
+			},
+		},
+		BashComplete: func(c *cli.Context) {
+			helper.AutoComplete(c, command.Settings.GetFileCache())
+		},
+	}
+}
+
+// ComplianceAction contains logic to compare the hook settings of every selected repository with the baseline
+func (command *HookComplianceCommand) ComplianceAction(context *cli.Context) error {
+	if len(command.flags.key) <= 0 {
+		return errors.New("--key flag is required")
+	}
+	if len(command.flags.baselineFile) <= 0 {
+		return errors.New("--baseline-file flag is required")
+	}
+	if command.flags.override && !command.flags.remediate {
+		return errors.New("--override can only be used with --remediate")
+	}
+	if err := helper.ValidateFormat(command.flags.format); err != nil {
+		return err
+	}
+
+	normalization, err := helper.ParseSettingsNormalization(command.flags.normalization)
+	if err != nil {
+		return err
+	}
+
+	baseline, err := readHookSettings(command.flags.key, command.flags.baselineFile)
+	if err != nil {
+		return err
+	}
+
+	client, err := command.Settings.GetAPIClient()
+	if err != nil {
+		return err
+	}
+
+	restClient, err := command.Settings.GetRestClient()
+	if err != nil {
+		return err
+	}
+
+	selector := helper.RepositorySelector{
+		Project:    command.flags.project,
+		Repository: command.flags.repository,
+	}
+
+	repositories, err := selector.Select(client)
+	if err != nil {
+		return err
+	}
+
+	if len(repositories) == 0 {
+		return fmt.Errorf("no repository match %s", selector)
+	}
+
+	var rows []HookComplianceRow
+	nonCompliant := 0
+	remediatedProjects := make(map[string]bool)
+
+	for _, repository := range repositories {
+		hook, err := restClient.GetRepositoryHook(repository.Project.Key, repository.Slug, command.flags.key)
+		if err != nil {
+			return fmt.Errorf("cannot read hook %s of %s/%s: %w", command.flags.key, repository.Project.Key, repository.Slug, err)
+		}
+
+		hookSettings, err := restClient.GetRepositoryHookSettings(repository.Project.Key, repository.Slug, command.flags.key)
+		if err != nil {
+			return fmt.Errorf("cannot read %s settings of %s/%s: %w", command.flags.key, repository.Project.Key, repository.Slug, err)
+		}
+
+		differences, err := helper.CompareHookSettings(baseline, hookSettings, normalization)
+		if err != nil {
+			return fmt.Errorf("%s/%s: %w", repository.Project.Key, repository.Slug, err)
+		}
+
+		row := HookComplianceRow{
+			Project:     repository.Project.Key,
+			Repository:  repository.Slug,
+			Enabled:     hook.Enabled,
+			Inherited:   hook.Inherited(),
+			Status:      complianceStatusCompliant,
+			Differences: differences,
+		}
+
+		if len(differences) > 0 {
+			row.Status = complianceStatusNonCompliant
+
+			if command.flags.remediate {
+				if err := command.remediate(restClient, hook, repository.Project.Key, repository.Slug, baseline, remediatedProjects); err != nil {
+					return err
+				}
+
+				row.Status = complianceStatusRemediated
+			} else {
+				nonCompliant++
+			}
+		}
+
+		rows = append(rows, row)
+	}
+
+	if err := command.print(rows); err != nil {
+		return err
+	}
+
+	if nonCompliant > 0 {
+		return fmt.Errorf("%d repositories are not compliant with %s", nonCompliant, command.flags.baselineFile)
+	}
+
+	return nil
+}
+
+// remediate push the baseline where the hook state of the repository is defined: on the project when the repository
+// inherit it, unless --override ask for a repository level copy. Each project is only remediated once.
+func (command *HookComplianceCommand) remediate(
+	restClient *helper.RestClient,
+	hook helper.RepositoryHook,
+	projectKey string,
+	repositorySlug string,
+	baseline json.RawMessage,
+	remediatedProjects map[string]bool,
+) error {
+	target := repositorySlug
+	if hook.Inherited() && !command.flags.override {
+		if remediatedProjects[projectKey] {
+			return nil
+		}
+
+		target = ""
+		remediatedProjects[projectKey] = true
+	}
+
+	_, err := restClient.SetRepositoryHookSettings(projectKey, target, command.flags.key, baseline)
+	if err != nil {
+		return fmt.Errorf("cannot remediate %s settings of %s: %w", command.flags.key, hookTarget(projectKey, target), err)
+	}
+
+	return nil
+}
+
+func (command *HookComplianceCommand) print(rows []HookComplianceRow) error {
+	header := []string{"project", "repository", "enabled", "inherited", "status", "differences"}
+
+	var lines [][]string
+	for _, row := range rows {
+		var differences []string
+		for _, difference := range row.Differences {
+			differences = append(differences, difference.String())
+		}
+
+		lines = append(lines, []string{
+			row.Project,
+			row.Repository,
+			fmt.Sprintf("%t", row.Enabled),
+			fmt.Sprintf("%t", row.Inherited),
+			row.Status,
+			strings.Join(differences, "; "),
+		})
+	}
+
+	return helper.WriteFormatted(os.Stdout, command.flags.format, header, lines, rows)
+}
@@ -0,0 +1,409 @@
+// Package hooks hold actions on the Bitbucket hooks
+package hooks
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/daeMOn63/bitadmin/helper"
+	"github.com/daeMOn63/bitadmin/settings"
+	"github.com/daeMOn63/bitclient"
+	"github.com/urfave/cli"
+)
+
+// hookSettingsSchemas map the hook keys to the settings struct used to validate their configuration.
+// Bitbucket does not publish the settings schema of its hooks, so only the hooks bitadmin already model
+// are checked locally. Settings of other hooks are only checked to be a JSON object, then validated by the server.
+var hookSettingsSchemas = map[string]interface{}{
+	eolHookKey:  EolSettings{},
+	yaccHookKey: bitclient.YaccHookSettings{},
+}
+
+// hookFlags hold the flag values identifying a hook on a repository
+type hookFlags struct {
+	project      string
+	repository   string
+	key          string
+	projectLevel bool
+}
+
+// getFlags provide the flags identifying the hook
+func (flags *hookFlags) getFlags() []cli.Flag {
+	return []cli.Flag{
+		cli.StringFlag{
+			Name:        "project",
+			Usage:       "The `<project_key>` containing the repository",
+			Destination: &flags.project,
+		},
+		cli.StringFlag{
+			Name:        "repository",
+			Usage:       "The `<repository_name>` holding the hook",
+			Destination: &flags.repository,
+		},
+		projectLevelFlag(&flags.projectLevel),
+		cli.StringFlag{
+			Name:        "key",
+			Usage:       "The `<hook_key>` of the hook (ie: com.atlassian.bitbucket.server.bitbucket-bundled-hooks:force-push-hook)",
+			Destination: &flags.key,
+		},
+	}
+}
+
+// validate ensure the required flags are provided
+func (flags *hookFlags) validate() error {
+	if len(flags.project) <= 0 {
+		return errors.New("--project flag is required")
+	}
+	if len(flags.key) <= 0 {
+		return errors.New("--key flag is required")
+	}
+	if err := checkHookScope(flags.repository, flags.projectLevel); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// readHookSettings load a JSON or YAML settings file and validate it against the hook schema when known
+func readHookSettings(hookKey string, filename string) (json.RawMessage, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	if helper.IsYAMLFile(filename) {
+		if data, err = helper.YAMLToJSON(data); err != nil {
+			return nil, fmt.Errorf("%s: %s", filename, err)
+		}
+	}
+
+	if err := validateHookSettings(hookKey, data); err != nil {
+		return nil, fmt.Errorf("%s: %s", filename, err)
+	}
+
+	return json.RawMessage(data), nil
+}
+
+// validateHookSettings ensure settings is a JSON object, matching the hook schema when one is registered
+func validateHookSettings(hookKey string, settings []byte) error {
+	var object map[string]interface{}
+	if err := json.Unmarshal(settings, &object); err != nil {
+		return fmt.Errorf("settings must be a JSON object: %s", err)
+	}
+	if object == nil {
+		return errors.New("settings must be a JSON object, got null")
+	}
+
+	schema, ok := hookSettingsSchemas[hookKey]
+	if !ok {
+		return nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(settings))
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(reflect.New(reflect.TypeOf(schema)).Interface()); err != nil {
+		return fmt.Errorf("invalid settings for hook %s: %s", hookKey, err)
+	}
+
+	return nil
+}
+
+// settingsFileUsage describe the --settings-file flag, listing the hooks whose settings are checked locally
+func settingsFileUsage(usage string) string {
+	var keys []string
+	for key := range hookSettingsSchemas {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return fmt.Sprintf("%s. Only the settings of %s are checked before being sent, other hooks are checked by the server", usage, strings.Join(keys, ", "))
+}
+
+// HookEnableCommand define the command to enable any hook from its key
+type HookEnableCommand struct {
+	Settings *settings.BitAdminSettings
+	flags    *HookEnableCommandFlags
+}
+
+// HookEnableCommandFlags define the flags for the HookEnableCommand
+type HookEnableCommandFlags struct {
+	hookFlags
+	settingsFile string
+}
+
+// GetCommand provide a ready to use cli.Command
+func (command *HookEnableCommand) GetCommand() cli.Command {
+	return cli.Command{
+		Name:   "enable",
+		Usage:  "Enable a hook from its key and optionally set its configuration",
+		Action: command.EnableAction,
+		Flags: append(
+			command.flags.hookFlags.getFlags(),
+			cli.StringFlag{
+				Name:        "settings-file",
+				Usage:       settingsFileUsage("Configure the hook with the JSON or YAML settings from `<file>`"),
+				Destination: &command.flags.settingsFile,
+			},
+		),
+		BashComplete: func(c *cli.Context) {
+			helper.AutoComplete(c, command.Settings.GetFileCache())
+		},
+	}
+}
+
+// EnableAction contains logic to turn on the hook and set its configuration
+func (command *HookEnableCommand) EnableAction(context *cli.Context) error {
+	if err := command.flags.validate(); err != nil {
+		return err
+	}
+
+	var hookSettings interface{}
+	if len(command.flags.settingsFile) > 0 {
+		data, err := readHookSettings(command.flags.key, command.flags.settingsFile)
+		if err != nil {
+			return err
+		}
+		hookSettings = data
+	}
+
+	restClient, err := command.Settings.GetRestClient()
+	if err != nil {
+		return err
+	}
+
+	_, err = restClient.EnableRepositoryHook(
+		command.flags.project,
+		command.flags.repository,
+		command.flags.key,
+		hookSettings,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("[OK] Enabled hook %s on %s\n", command.flags.key, hookTarget(command.flags.project, command.flags.repository))
+
+	return nil
+}
+
+// HookDisableCommand define the command to disable any hook from its key
+type HookDisableCommand struct {
+	Settings *settings.BitAdminSettings
+	flags    *HookDisableCommandFlags
+}
+
+// HookDisableCommandFlags define the flags for the HookDisableCommand
+type HookDisableCommandFlags struct {
+	hookFlags
+}
+
+// GetCommand provide a ready to use cli.Command
+func (command *HookDisableCommand) GetCommand() cli.Command {
+	return cli.Command{
+		Name:   "disable",
+		Usage:  "Disable a hook from its key",
+		Action: command.DisableAction,
+		Flags:  command.flags.hookFlags.getFlags(),
+		BashComplete: func(c *cli.Context) {
+			helper.AutoComplete(c, command.Settings.GetFileCache())
+		},
+	}
+}
+
+// DisableAction contains logic to turn off the hook
+func (command *HookDisableCommand) DisableAction(context *cli.Context) error {
+	if err := command.flags.validate(); err != nil {
+		return err
+	}
+
+	restClient, err := command.Settings.GetRestClient()
+	if err != nil {
+		return err
+	}
+
+	err = restClient.DisableRepositoryHook(command.flags.project, command.flags.repository, command.flags.key)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("[OK] Disabled hook %s on %s\n", command.flags.key, hookTarget(command.flags.project, command.flags.repository))
+
+	return nil
+}
+
+// HookGetSettingsCommand define the command to print the settings of any hook
+type HookGetSettingsCommand struct {
+	Settings *settings.BitAdminSettings
+	flags    *HookGetSettingsCommandFlags
+}
+
+// HookGetSettingsCommandFlags define the flags for the HookGetSettingsCommand
+type HookGetSettingsCommandFlags struct {
+	hookFlags
+}
+
+// GetCommand provide a ready to use cli.Command
+func (command *HookGetSettingsCommand) GetCommand() cli.Command {
+	return cli.Command{
+		Name:   "get-settings",
+		Usage:  "Print the JSON settings of a hook, ready to be used with --settings-file",
+		Action: command.GetSettingsAction,
+		Flags:  command.flags.hookFlags.getFlags(),
+		BashComplete: func(c *cli.Context) {
+			helper.AutoComplete(c, command.Settings.GetFileCache())
+		},
+	}
+}
+
+// GetSettingsAction contains logic to print the hook settings
+func (command *HookGetSettingsCommand) GetSettingsAction(context *cli.Context) error {
+	if err := command.flags.validate(); err != nil {
+		return err
+	}
+
+	restClient, err := command.Settings.GetRestClient()
+	if err != nil {
+		return err
+	}
+
+	hookSettings, err := restClient.GetRepositoryHookSettings(command.flags.project, command.flags.repository, command.flags.key)
+	if err != nil {
+		return err
+	}
+
+	// Bitbucket answer with an empty body when the hook was never configured
+	if len(hookSettings) == 0 {
+		hookSettings = json.RawMessage("{}")
+	}
+
+	var out bytes.Buffer
+	if err := json.Indent(&out, hookSettings, "", "  "); err != nil {
+		return err
+	}
+
+	fmt.Println(out.String())
+
+	return nil
+}
+
+// HookSetSettingsCommand define the command to replace the settings of any hook
+type HookSetSettingsCommand struct {
+	Settings *settings.BitAdminSettings
+	flags    *HookSetSettingsCommandFlags
+}
+
+// HookSetSettingsCommandFlags define the flags for the HookSetSettingsCommand
+type HookSetSettingsCommandFlags struct {
+	hookFlags
+	settingsFile string
+}
+
+// GetCommand provide a ready to use cli.Command
+func (command *HookSetSettingsCommand) GetCommand() cli.Command {
+	return cli.Command{
+		Name:   "set-settings",
+		Usage:  "Replace the settings of a hook, without changing its enabled state",
+		Action: command.SetSettingsAction,
+		Flags: append(
+			command.flags.hookFlags.getFlags(),
+			cli.StringFlag{
+				Name:        "settings-file",
+				Usage:       settingsFileUsage("The `<file>` holding the JSON or YAML settings"),
+				Destination: &command.flags.settingsFile,
+			},
+		),
+		BashComplete: func(c *cli.Context) {
+			helper.AutoComplete(c, command.Settings.GetFileCache())
+		},
+	}
+}
+
+// SetSettingsAction contains logic to save the hook settings
+func (command *HookSetSettingsCommand) SetSettingsAction(context *cli.Context) error {
+	if err := command.flags.validate(); err != nil {
+		return err
+	}
+	if len(command.flags.settingsFile) <= 0 {
+		return errors.New("--settings-file flag is required")
+	}
+
+	hookSettings, err := readHookSettings(command.flags.key, command.flags.settingsFile)
+	if err != nil {
+		return err
+	}
+
+	restClient, err := command.Settings.GetRestClient()
+	if err != nil {
+		return err
+	}
+
+	_, err = restClient.SetRepositoryHookSettings(
+		command.flags.project,
+		command.flags.repository,
+		command.flags.key,
+		hookSettings,
+	)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("[OK] Saved settings of hook %s on %s\n", command.flags.key, hookTarget(command.flags.project, command.flags.repository))
+
+	return nil
+}
+
+// HookInheritCommand define the command to reset a repository hook to its project state
+type HookInheritCommand struct {
+	Settings *settings.BitAdminSettings
+	flags    *HookInheritCommandFlags
+}
+
+// HookInheritCommandFlags define the flags for the HookInheritCommand
+type HookInheritCommandFlags struct {
+	hookFlags
+}
+
+// GetCommand provide a ready to use cli.Command
+func (command *HookInheritCommand) GetCommand() cli.Command {
+	return cli.Command{
+		Name:   "inherit",
+		Usage:  "Drop the repository state and settings of a hook, so the project ones apply again",
+		Action: command.InheritAction,
+		Flags:  command.flags.hookFlags.getFlags(),
+		BashComplete: func(c *cli.Context) {
+			helper.AutoComplete(c, command.Settings.GetFileCache())
+		},
+	}
+}
+
+// InheritAction contains logic to reset the repository hook to the project defaults
+func (command *HookInheritCommand) InheritAction(context *cli.Context) error {
+	if err := command.flags.validate(); err != nil {
+		return err
+	}
+	if len(command.flags.repository) <= 0 {
+		return errors.New("--repository flag is required")
+	}
+
+	restClient, err := command.Settings.GetRestClient()
+	if err != nil {
+		return err
+	}
+
+	err = restClient.InheritRepositoryHook(command.flags.project, command.flags.repository, command.flags.key)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("[OK] Hook %s on %s now inherit from project %s\n", command.flags.key, hookTarget(command.flags.project, command.flags.repository), command.flags.project)
+
+	return nil
+}
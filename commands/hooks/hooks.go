@@ -7,7 +7,6 @@ import (
 
 	"github.com/daeMOn63/bitadmin/helper"
 	"github.com/daeMOn63/bitadmin/settings"
-	"github.com/daeMOn63/bitclient"
 	"github.com/urfave/cli"
 )
 
@@ -40,11 +39,53 @@ func (command *Command) GetCommand() cli.Command {
 		Settings: command.Settings,
 	}
 
+	hookEnableCommand := HookEnableCommand{
+		Settings: command.Settings,
+		flags:    &HookEnableCommandFlags{},
+	}
+
+	hookDisableCommand := HookDisableCommand{
+		Settings: command.Settings,
+		flags:    &HookDisableCommandFlags{},
+	}
+
+	hookGetSettingsCommand := HookGetSettingsCommand{
+		Settings: command.Settings,
+		flags:    &HookGetSettingsCommandFlags{},
+	}
+
+	hookSetSettingsCommand := HookSetSettingsCommand{
+		Settings: command.Settings,
+		flags:    &HookSetSettingsCommandFlags{},
+	}
+
+	hookInheritCommand := HookInheritCommand{
+		Settings: command.Settings,
+		flags:    &HookInheritCommandFlags{},
+	}
+
+	hookReportCommand := HookReportCommand{
+		Settings: command.Settings,
+		flags:    &HookReportCommandFlags{},
+	}
+
+	hookComplianceCommand := HookComplianceCommand{
+		Settings: command.Settings,
+		flags:    &HookComplianceCommandFlags{},
+	}
+
 	return cli.Command{
 		Name:  "hooks",
 		Usage: "Hooks operations",
 		Subcommands: []cli.Command{
 			listHookCommand.GetCommand(),
+			hookEnableCommand.GetCommand(),
+			hookDisableCommand.GetCommand(),
+			hookGetSettingsCommand.GetCommand(),
+			hookSetSettingsCommand.GetCommand(),
+			hookInheritCommand.GetCommand(),
+			hookReportCommand.GetCommand(),
+			hookComplianceCommand.GetCommand(),
 			eolHookCommand.GetCommand(),
 			pubHookCommand.GetCommand(),
 			yaccHookCommand.GetCommand(),
@@ -69,7 +110,7 @@ type ListHooksCommandFlags struct {
 func (command *ListHooksCommand) GetCommand() cli.Command {
 	return cli.Command{
 		Name:   "list",
-		Usage:  "List hooks on repository",
+		Usage:  "List hooks on repository or project, showing if the repository state is inherited or overridden",
 		Action: command.ListHooksAction,
 		Flags: []cli.Flag{
 			cli.StringFlag{
@@ -79,7 +120,7 @@ func (command *ListHooksCommand) GetCommand() cli.Command {
 			},
 			cli.StringFlag{
 				Name:        "repository",
-				Usage:       "The `<repository_name>` to list hooks for",
+				Usage:       "The `<repository_name>` to list hooks for, leave empty to list the project hooks",
 				Destination: &command.flags.repository,
 			},
 		},
@@ -91,7 +132,7 @@ func (command *ListHooksCommand) GetCommand() cli.Command {
 
 // ListHooksAction contains logic to list hooks on given repository
 func (command *ListHooksCommand) ListHooksAction(context *cli.Context) error {
-	client, err := command.Settings.GetAPIClient()
+	restClient, err := command.Settings.GetRestClient()
 	if err != nil {
 		return err
 	}
@@ -99,24 +140,60 @@ func (command *ListHooksCommand) ListHooksAction(context *cli.Context) error {
 	if len(command.flags.project) <= 0 {
 		return errors.New("--project flag is required")
 	}
-	if len(command.flags.repository) <= 0 {
-		return errors.New("--repository flag is required")
-	}
 
-	response, err := client.GetHooks(command.flags.project, command.flags.repository, bitclient.GetHooksRequest{})
+	hooks, err := restClient.GetRepositoryHooks(command.flags.project, command.flags.repository)
 
 	if err != nil {
 		return err
 	}
 
-	for _, hook := range response.Values {
+	for _, hook := range hooks {
 
 		status := "DISABLED"
 		if hook.Enabled == true {
 			status = "ENABLED "
 		}
 
-		fmt.Printf("[%s] %s (%s)\n", status, hook.Details.Name, hook.Details.Key)
+		// Hooks of a repository are either inherited from the project or overridden on the repository
+		scope := ""
+		if len(command.flags.repository) > 0 {
+			scope = " - overridden"
+			if hook.Inherited() {
+				scope = " - inherited"
+			}
+		}
+
+		fmt.Printf("[%s] %s (%s)%s\n", status, hook.Details.Name, hook.Details.Key, scope)
+	}
+
+	return nil
+}
+
+// hookTarget give a readable name of the scope changed: the repository, or the project when repositorySlug is empty
+func hookTarget(projectKey string, repositorySlug string) string {
+	if len(repositorySlug) == 0 {
+		return fmt.Sprintf("project %s (project level)", projectKey)
+	}
+
+	return fmt.Sprintf("repository %s/%s (repository level)", projectKey, repositorySlug)
+}
+
+// projectLevelFlag provide the flag required to change a hook on the project instead of a repository
+func projectLevelFlag(destination *bool) cli.Flag {
+	return cli.BoolFlag{
+		Name:        "project-level",
+		Usage:       "Target the hook of the project itself, applying to every repository inheriting it, instead of a --repository",
+		Destination: destination,
+	}
+}
+
+// checkHookScope ensure --repository is provided, unless --project-level explicitly target the project
+func checkHookScope(repositorySlug string, projectLevel bool) error {
+	if projectLevel && len(repositorySlug) > 0 {
+		return errors.New("--repository and --project-level cannot be used together")
+	}
+	if !projectLevel && len(repositorySlug) <= 0 {
+		return errors.New("--repository flag is required, or use --project-level to target the project")
 	}
 
 	return nil
@@ -47,8 +47,9 @@ type PubHookEnableCommand struct {
 
 // PubHookEnableCommandFlags define the flags for the PubHookEnableCommand
 type PubHookEnableCommandFlags struct {
-	project    string
-	repository string
+	project      string
+	repository   string
+	projectLevel bool
 }
 
 // GetCommand provide a ready to use cli.Command
@@ -65,9 +66,10 @@ func (command *PubHookEnableCommand) GetCommand() cli.Command {
 			},
 			cli.StringFlag{
 				Name:        "repository",
-				Usage:       "The `<repository_name>` to enable the hook on",
+				Usage:       "The `<repository_name>` holding the hook",
 				Destination: &command.flags.repository,
 			},
+			projectLevelFlag(&command.flags.projectLevel),
 		},
 		BashComplete: func(c *cli.Context) {
 			helper.AutoComplete(c, command.Settings.GetFileCache())
@@ -77,7 +79,7 @@ func (command *PubHookEnableCommand) GetCommand() cli.Command {
 
 // EnableAction contains logic to turn on the hook and set its configuration
 func (command *PubHookEnableCommand) EnableAction(context *cli.Context) error {
-	client, err := command.Settings.GetAPIClient()
+	restClient, err := command.Settings.GetRestClient()
 	if err != nil {
 		return err
 	}
@@ -85,11 +87,11 @@ func (command *PubHookEnableCommand) EnableAction(context *cli.Context) error {
 	if len(command.flags.project) <= 0 {
 		return errors.New("--project flag is required")
 	}
-	if len(command.flags.repository) <= 0 {
-		return errors.New("--repository flag is required")
+	if err := checkHookScope(command.flags.repository, command.flags.projectLevel); err != nil {
+		return err
 	}
 
-	err = client.EnableHook(
+	_, err = restClient.EnableRepositoryHook(
 		command.flags.project,
 		command.flags.repository,
 		pubHookKey,
@@ -100,7 +102,7 @@ func (command *PubHookEnableCommand) EnableAction(context *cli.Context) error {
 		return err
 	}
 
-	fmt.Printf("[OK] Enabled and configured Protect Unmerged Branch hook on %s/%s\n", command.flags.project, command.flags.repository)
+	fmt.Printf("[OK] Enabled and configured Protect Unmerged Branch hook on %s\n", hookTarget(command.flags.project, command.flags.repository))
 
 	return nil
 }
@@ -113,8 +115,9 @@ type PubHookDisableCommand struct {
 
 // PubHookDisableCommandFlags define the flags of the PubHookDisableCommand
 type PubHookDisableCommandFlags struct {
-	project    string
-	repository string
+	project      string
+	repository   string
+	projectLevel bool
 }
 
 // GetCommand provide a ready to use cli.Command
@@ -131,9 +134,10 @@ func (command *PubHookDisableCommand) GetCommand() cli.Command {
 			},
 			cli.StringFlag{
 				Name:        "repository",
-				Usage:       "The `<repository_name>` to create",
+				Usage:       "The `<repository_name>` holding the hook",
 				Destination: &command.flags.repository,
 			},
+			projectLevelFlag(&command.flags.projectLevel),
 		},
 		BashComplete: func(c *cli.Context) {
 			helper.AutoComplete(c, command.Settings.GetFileCache())
@@ -143,7 +147,7 @@ func (command *PubHookDisableCommand) GetCommand() cli.Command {
 
 // DisableAction contains logic to turn on the hook and set its configuration
 func (command *PubHookDisableCommand) DisableAction(context *cli.Context) error {
-	client, err := command.Settings.GetAPIClient()
+	restClient, err := command.Settings.GetRestClient()
 	if err != nil {
 		return err
 	}
@@ -151,17 +155,17 @@ func (command *PubHookDisableCommand) DisableAction(context *cli.Context) error
 	if len(command.flags.project) <= 0 {
 		return errors.New("--project flag is required")
 	}
-	if len(command.flags.repository) <= 0 {
-		return errors.New("--repository flag is required")
+	if err := checkHookScope(command.flags.repository, command.flags.projectLevel); err != nil {
+		return err
 	}
 
-	err = client.DisableHook(command.flags.project, command.flags.repository, pubHookKey)
+	err = restClient.DisableRepositoryHook(command.flags.project, command.flags.repository, pubHookKey)
 
 	if err != nil {
 		return err
 	}
 
-	fmt.Printf("[OK] Disabled Protect Unmerged Branch hook on %s/%s\n", command.flags.project, command.flags.repository)
+	fmt.Printf("[OK] Disabled Protect Unmerged Branch hook on %s\n", hookTarget(command.flags.project, command.flags.repository))
 
 	return nil
 }
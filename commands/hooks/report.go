@@ -0,0 +1,259 @@
+// Package hooks hold actions on the Bitbucket hooks
+package hooks
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/daeMOn63/bitadmin/helper"
+	"github.com/daeMOn63/bitadmin/settings"
+	"github.com/urfave/cli"
+)
+
+// Hook statuses displayed in the report
+const (
+	hookStatusEnabled           = "enabled"
+	hookStatusDisabled          = "disabled"
+	hookStatusInheritedEnabled  = "inherited-enabled"
+	hookStatusInheritedDisabled = "inherited-disabled"
+	hookStatusNotInstalled      = "not-installed"
+)
+
+// HookReportCommand define the command to report hooks state across repositories
+type HookReportCommand struct {
+	Settings *settings.BitAdminSettings
+	flags    *HookReportCommandFlags
+}
+
+// HookReportCommandFlags define the flags for the HookReportCommand
+type HookReportCommandFlags struct {
+	project    string
+	repository string
+	keys       cli.StringSlice
+	required   cli.StringSlice
+	format     string
+}
+
+// HookReportEntry hold the state of a single hook on a repository
+type HookReportEntry struct {
+	Status       string `json:"status"`
+	Enabled      bool   `json:"enabled"`
+	Inherited    bool   `json:"inherited"`
+	SettingsHash string `json:"settingsHash,omitempty"`
+}
+
+// HookReportRow hold the state of the reported hooks on a repository
+type HookReportRow struct {
+	Project    string                     `json:"project"`
+	Repository string                     `json:"repository"`
+	Hooks      map[string]HookReportEntry `json:"hooks"`
+	Missing    []string                   `json:"missing,omitempty"`
+}
+
+// GetCommand provide a ready to use cli.Command
+func (command *HookReportCommand) GetCommand() cli.Command {
+	return cli.Command{
+		Name:   "report",
+		Usage:  "Report the state of hooks across repositories, exit with an error when a required hook is not enabled",
+		Action: command.ReportAction,
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:        "project",
+				Usage:       "The `<project_key>` to report on, shell patterns are allowed. Default to all projects",
+				Destination: &command.flags.project,
+			},
+			cli.StringFlag{
+				Name:        "repository",
+				Usage:       "The `<repository_slug>` to report on, shell patterns are allowed. Default to all repositories",
+				Destination: &command.flags.repository,
+			},
+			cli.StringSliceFlag{
+				Name:  "key",
+				Usage: "Only report the hook with `<hook_key>`. Can be repeated multiple times, default to every installed hook",
+				Value: &command.flags.keys,
+			},
+			cli.StringSliceFlag{
+				Name:  "require",
+				Usage: "The hook with `<hook_key>` must be enabled on every repository. Can be repeated multiple times",
+				Value: &command.flags.required,
+			},
+			cli.StringFlag{
+				Name:        "format",
+				Usage:       "The output `<format>`: table, json or csv",
+				Value:       helper.FormatTable,
+				Destination: &command.flags.format,
+			},
+		},
+		BashComplete: func(c *cli.Context) {
+			helper.AutoComplete(c, command.Settings.GetFileCache())
+		},
+	}
+}
+
+// ReportAction contains logic to build and print the hooks report
+func (command *HookReportCommand) ReportAction(context *cli.Context) error {
+	if err := helper.ValidateFormat(command.flags.format); err != nil {
+		return err
+	}
+
+	client, err := command.Settings.GetAPIClient()
+	if err != nil {
+		return err
+	}
+
+	restClient, err := command.Settings.GetRestClient()
+	if err != nil {
+		return err
+	}
+
+	selector := helper.RepositorySelector{
+		Project:    command.flags.project,
+		Repository: command.flags.repository,
+	}
+
+	repositories, err := selector.Select(client)
+	if err != nil {
+		return err
+	}
+
+	if len(repositories) == 0 {
+		return fmt.Errorf("no repository match %s", selector)
+	}
+
+	keys := make(map[string]bool)
+	for _, key := range append(command.flags.keys, command.flags.required...) {
+		keys[key] = true
+	}
+	collectKeys := len(command.flags.keys) == 0
+
+	var rows []HookReportRow
+	missingCount := 0
+
+	for _, repository := range repositories {
+		hooks, err := restClient.GetRepositoryHooks(repository.Project.Key, repository.Slug)
+		if err != nil {
+			return fmt.Errorf("cannot read hooks of %s/%s: %w", repository.Project.Key, repository.Slug, err)
+		}
+
+		row := HookReportRow{
+			Project:    repository.Project.Key,
+			Repository: repository.Slug,
+			Hooks:      make(map[string]HookReportEntry),
+		}
+
+		for _, hook := range hooks {
+			if !collectKeys && !keys[hook.Details.Key] {
+				continue
+			}
+			keys[hook.Details.Key] = true
+
+			entry := HookReportEntry{
+				Status:    hookStatus(hook),
+				Enabled:   hook.Enabled,
+				Inherited: hook.Inherited(),
+			}
+
+			if hook.Configured {
+				hookSettings, err := restClient.GetRepositoryHookSettings(repository.Project.Key, repository.Slug, hook.Details.Key)
+				if err != nil {
+					return fmt.Errorf("cannot read %s settings of %s/%s: %w", hook.Details.Key, repository.Project.Key, repository.Slug, err)
+				}
+
+				if entry.SettingsHash, err = helper.HookSettingsHash(hookSettings); err != nil {
+					return err
+				}
+			}
+
+			row.Hooks[hook.Details.Key] = entry
+		}
+
+		for _, key := range command.flags.required {
+			if !row.Hooks[key].Enabled {
+				row.Missing = append(row.Missing, key)
+			}
+		}
+		if len(row.Missing) > 0 {
+			missingCount++
+		}
+
+		rows = append(rows, row)
+	}
+
+	var sortedKeys []string
+	for key := range keys {
+		sortedKeys = append(sortedKeys, key)
+	}
+	sort.Strings(sortedKeys)
+
+	if err := command.print(rows, sortedKeys); err != nil {
+		return err
+	}
+
+	if missingCount > 0 {
+		for _, row := range rows {
+			if len(row.Missing) > 0 {
+				fmt.Fprintf(os.Stderr, "[MISSING] %s/%s: %s\n", row.Project, row.Repository, strings.Join(row.Missing, ", "))
+			}
+		}
+
+		return fmt.Errorf("%d repositories do not have all the required hooks enabled", missingCount)
+	}
+
+	return nil
+}
+
+func (command *HookReportCommand) print(rows []HookReportRow, keys []string) error {
+	if len(keys) == 0 && command.flags.format != helper.FormatJSON {
+		return errors.New("no hook found on the selected repositories")
+	}
+
+	header := []string{"project", "repository"}
+	for _, key := range keys {
+		header = append(header, key)
+		if command.flags.format == helper.FormatCSV {
+			header = append(header, key+" settings")
+		}
+	}
+
+	var lines [][]string
+	for _, row := range rows {
+		line := []string{row.Project, row.Repository}
+
+		for _, key := range keys {
+			entry, ok := row.Hooks[key]
+			if !ok {
+				entry.Status = hookStatusNotInstalled
+			}
+
+			switch {
+			case command.flags.format == helper.FormatCSV:
+				line = append(line, entry.Status, entry.SettingsHash)
+			case len(entry.SettingsHash) > 0:
+				line = append(line, fmt.Sprintf("%s (%s)", entry.Status, entry.SettingsHash))
+			default:
+				line = append(line, entry.Status)
+			}
+		}
+
+		lines = append(lines, line)
+	}
+
+	return helper.WriteFormatted(os.Stdout, command.flags.format, header, lines, rows)
+}
+
+// hookStatus give the report status of the hook
+func hookStatus(hook helper.RepositoryHook) string {
+	switch {
+	case hook.Inherited() && hook.Enabled:
+		return hookStatusInheritedEnabled
+	case hook.Inherited():
+		return hookStatusInheritedDisabled
+	case hook.Enabled:
+		return hookStatusEnabled
+	}
+
+	return hookStatusDisabled
+}
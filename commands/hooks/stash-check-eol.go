@@ -53,9 +53,10 @@ type EolSettings struct {
 
 // EolHookEnableCommandFlags define the flags for the EolHookEnableCommand
 type EolHookEnableCommandFlags struct {
-	project    string
-	repository string
-	settings   EolSettings
+	project      string
+	repository   string
+	projectLevel bool
+	settings     EolSettings
 }
 
 // GetCommand provide a ready to use cli.Command
@@ -72,9 +73,10 @@ func (command *EolHookEnableCommand) GetCommand() cli.Command {
 			},
 			cli.StringFlag{
 				Name:        "repository",
-				Usage:       "The `<repository_name>` to enable the hook on",
+				Usage:       "The `<repository_name>` holding the hook",
 				Destination: &command.flags.repository,
 			},
+			projectLevelFlag(&command.flags.projectLevel),
 			cli.StringFlag{
 				Name:        "excludeFiles",
 				Usage:       "`<files>` that should not be checked for EOL - comma separated list of regexps",
@@ -94,7 +96,7 @@ func (command *EolHookEnableCommand) GetCommand() cli.Command {
 
 // EnableAction contains logic to turn on the hook and set its configuration
 func (command *EolHookEnableCommand) EnableAction(context *cli.Context) error {
-	client, err := command.Settings.GetAPIClient()
+	restClient, err := command.Settings.GetRestClient()
 	if err != nil {
 		return err
 	}
@@ -102,11 +104,11 @@ func (command *EolHookEnableCommand) EnableAction(context *cli.Context) error {
 	if len(command.flags.project) <= 0 {
 		return errors.New("--project flag is required")
 	}
-	if len(command.flags.repository) <= 0 {
-		return errors.New("--repository flag is required")
+	if err := checkHookScope(command.flags.repository, command.flags.projectLevel); err != nil {
+		return err
 	}
 
-	err = client.EnableHook(
+	_, err = restClient.EnableRepositoryHook(
 		command.flags.project,
 		command.flags.repository,
 		eolHookKey,
@@ -117,7 +119,7 @@ func (command *EolHookEnableCommand) EnableAction(context *cli.Context) error {
 		return err
 	}
 
-	fmt.Printf("[OK] Enabled and configured eol hook on %s/%s\n", command.flags.project, command.flags.repository)
+	fmt.Printf("[OK] Enabled and configured eol hook on %s\n", hookTarget(command.flags.project, command.flags.repository))
 
 	return nil
 }
@@ -130,8 +132,9 @@ type EolHookDisableCommand struct {
 
 // EolHookDisableCommandFlags define the flags of the EolHookDisableCommand
 type EolHookDisableCommandFlags struct {
-	project    string
-	repository string
+	project      string
+	repository   string
+	projectLevel bool
 }
 
 // GetCommand provide a ready to use cli.Command
@@ -148,9 +151,10 @@ func (command *EolHookDisableCommand) GetCommand() cli.Command {
 			},
 			cli.StringFlag{
 				Name:        "repository",
-				Usage:       "The `<repository_name>` to create",
+				Usage:       "The `<repository_name>` holding the hook",
 				Destination: &command.flags.repository,
 			},
+			projectLevelFlag(&command.flags.projectLevel),
 		},
 		BashComplete: func(c *cli.Context) {
 			helper.AutoComplete(c, command.Settings.GetFileCache())
@@ -160,7 +164,7 @@ func (command *EolHookDisableCommand) GetCommand() cli.Command {
 
 // DisableAction contains logic to turn on the hook and set its configuration
 func (command *EolHookDisableCommand) DisableAction(context *cli.Context) error {
-	client, err := command.Settings.GetAPIClient()
+	restClient, err := command.Settings.GetRestClient()
 	if err != nil {
 		return err
 	}
@@ -168,17 +172,17 @@ func (command *EolHookDisableCommand) DisableAction(context *cli.Context) error
 	if len(command.flags.project) <= 0 {
 		return errors.New("--project flag is required")
 	}
-	if len(command.flags.repository) <= 0 {
-		return errors.New("--repository flag is required")
+	if err := checkHookScope(command.flags.repository, command.flags.projectLevel); err != nil {
+		return err
 	}
 
-	err = client.DisableHook(command.flags.project, command.flags.repository, eolHookKey)
+	err = restClient.DisableRepositoryHook(command.flags.project, command.flags.repository, eolHookKey)
 
 	if err != nil {
 		return err
 	}
 
-	fmt.Printf("[OK] Disabled eol hook on %s/%s\n", command.flags.project, command.flags.repository)
+	fmt.Printf("[OK] Disabled eol hook on %s\n", hookTarget(command.flags.project, command.flags.repository))
 
 	return nil
 }
@@ -0,0 +1,284 @@
+// Package hooks hold actions on the Bitbucket hooks
+package hooks
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+
+	"github.com/daeMOn63/bitadmin/helper"
+	"github.com/daeMOn63/bitadmin/settings"
+	"github.com/daeMOn63/bitclient"
+	"github.com/urfave/cli"
+)
+
+// YaccHookTestCommand define the command to check local commits against YACC settings
+type YaccHookTestCommand struct {
+	Settings *settings.BitAdminSettings
+	flags    *YaccHookTestCommandFlags
+}
+
+// YaccHookTestCommandFlags define the flags of the YaccHookTestCommand
+type YaccHookTestCommandFlags struct {
+	settingsFile string
+	repoPath     string
+	revRange     string
+	branches     cli.StringSlice
+}
+
+// yaccRules hold the compiled regex based rules of the YACC settings, nil when the rule is not set
+type yaccRules struct {
+	settings       bitclient.YaccHookSettings
+	commitMessage  *regexp.Regexp
+	committerEmail *regexp.Regexp
+	branchName     *regexp.Regexp
+	excludeBy      *regexp.Regexp
+	excludeBranch  *regexp.Regexp
+}
+
+// GetCommand provide a ready to use cli.Command
+func (command *YaccHookTestCommand) GetCommand() cli.Command {
+	return cli.Command{
+		Name: "test",
+		Usage: "Check the commits of a local git repository against YACC settings, without contacting Bitbucket. " +
+			"Only the regex based rules are evaluated, Jira and user matching rules are ignored",
+		Action: command.TestAction,
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:        "settings-file",
+				Usage:       "The `<file>` holding the JSON or YAML YACC settings to test",
+				Destination: &command.flags.settingsFile,
+			},
+			cli.StringFlag{
+				Name:        "repo-path",
+				Usage:       "The `<path>` of the local git repository",
+				Value:       ".",
+				Destination: &command.flags.repoPath,
+			},
+			cli.StringFlag{
+				Name:        "range",
+				Usage:       "The `<revision_range>` of the commits to check (ie: origin/master..HEAD)",
+				Destination: &command.flags.revRange,
+			},
+			cli.StringSliceFlag{
+				Name:  "branch",
+				Usage: "The `<branch>` name the commits would be pushed to. Can be repeated multiple times, default to the checked out branch, or the CI branch variables on a detached HEAD",
+				Value: &command.flags.branches,
+			},
+		},
+	}
+}
+
+// TestAction contains logic to evaluate the YACC rules on the local commits
+func (command *YaccHookTestCommand) TestAction(context *cli.Context) error {
+	if len(command.flags.settingsFile) <= 0 {
+		return errors.New("--settings-file flag is required")
+	}
+	if len(command.flags.revRange) <= 0 {
+		return errors.New("--range flag is required")
+	}
+
+	yaccSettings := bitclient.YaccHookSettings{}
+	if err := loadYaccSettings(command.flags.settingsFile, &yaccSettings); err != nil {
+		return err
+	}
+
+	rules, err := newYaccRules(yaccSettings)
+	if err != nil {
+		return err
+	}
+
+	branches := []string(command.flags.branches)
+	if len(branches) == 0 {
+		branch, err := helper.CurrentGitBranch(command.flags.repoPath)
+		if errors.Is(err, helper.ErrGitDetachedHead) {
+			return fmt.Errorf("%w, use --branch to name the branch the commits would be pushed to", err)
+		}
+		if err != nil {
+			return err
+		}
+		branches = append(branches, branch)
+	}
+
+	commits, err := helper.ReadGitCommits(command.flags.repoPath, command.flags.revRange)
+	if err != nil {
+		return err
+	}
+
+	failures := 0
+	checkedBranches := 0
+
+	for _, branch := range branches {
+		if rules.excludeBranch != nil && rules.excludeBranch.MatchString(branch) {
+			fmt.Printf("[SKIP] branch %s match excludeBranchRegex\n", branch)
+			continue
+		}
+		checkedBranches++
+
+		if reason := rules.checkBranch(branch); len(reason) > 0 {
+			fmt.Printf("[FAIL] branch %s\n    - %s\n", branch, reason)
+			failures++
+		}
+	}
+
+	if checkedBranches == 0 {
+		fmt.Println("All the branches are excluded, no commit checked")
+
+		return nil
+	}
+
+	skipped := 0
+
+	for _, commit := range commits {
+		if rules.isExcluded(commit) {
+			skipped++
+			continue
+		}
+
+		reasons := rules.checkCommit(commit)
+		if len(reasons) == 0 {
+			continue
+		}
+
+		failures++
+		fmt.Printf("[FAIL] %s %s\n", commit.ShortHash(), commit.Subject())
+		for _, reason := range reasons {
+			fmt.Printf("    - %s\n", reason)
+		}
+	}
+
+	fmt.Printf("%d commits checked, %d skipped\n", len(commits)-skipped, skipped)
+
+	if failures > 0 {
+		return fmt.Errorf("%d commits or branches would be rejected", failures)
+	}
+
+	fmt.Println("[OK] no commit would be rejected")
+
+	return nil
+}
+
+// newYaccRules compile the regex rules of the YACC settings.
+// YACC use java matches(), requiring the whole value to match, except for excludeByRegex which use find().
+func newYaccRules(yaccSettings bitclient.YaccHookSettings) (yaccRules, error) {
+	rules := yaccRules{settings: yaccSettings}
+
+	compile := func(name string, expr string, flags string, whole bool) (*regexp.Regexp, error) {
+		if len(expr) == 0 {
+			return nil, nil
+		}
+		if whole {
+			expr = "^(?:" + expr + ")$"
+		}
+
+		re, err := regexp.Compile(flags + expr)
+		if construct := unsupportedJavaConstruct(err); len(construct) > 0 {
+			return nil, fmt.Errorf("%s use %s, a java regex construct unsupported by the tester", name, construct)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %s", name, err)
+		}
+
+		return re, nil
+	}
+
+	var err error
+	if rules.commitMessage, err = compile("commitMessageRegex", yaccSettings.CommitMessageRegex, "(?s)", true); err != nil {
+		return rules, err
+	}
+	if rules.committerEmail, err = compile("committerEmailRegex", yaccSettings.CommitterEmailRegex, "", true); err != nil {
+		return rules, err
+	}
+	if rules.branchName, err = compile("branchNameRegex", yaccSettings.BranchNameRegex, "", true); err != nil {
+		return rules, err
+	}
+	if rules.excludeBy, err = compile("excludeByRegex", yaccSettings.ExcludeByRegex, "", false); err != nil {
+		return rules, err
+	}
+	if rules.excludeBranch, err = compile("excludeBranchRegex", yaccSettings.ExcludeBranchRegex, "", true); err != nil {
+		return rules, err
+	}
+
+	return rules, nil
+}
+
+// unsupportedJavaConstruct describe the java regex construct go cannot compile, or return an empty string when err is
+// not caused by one
+func unsupportedJavaConstruct(err error) string {
+	var syntaxErr *syntax.Error
+	if !errors.As(err, &syntaxErr) {
+		return ""
+	}
+
+	switch {
+	case syntaxErr.Code == syntax.ErrInvalidPerlOp && strings.HasPrefix(syntaxErr.Expr, "(?="):
+		return "a lookahead `(?=`"
+	case syntaxErr.Code == syntax.ErrInvalidPerlOp && strings.HasPrefix(syntaxErr.Expr, "(?!"):
+		return "a negative lookahead `(?!`"
+	case strings.HasPrefix(syntaxErr.Expr, "(?<="):
+		return "a lookbehind `(?<=`"
+	case strings.HasPrefix(syntaxErr.Expr, "(?<!"):
+		return "a negative lookbehind `(?<!`"
+	case syntaxErr.Code == syntax.ErrInvalidPerlOp && strings.HasPrefix(syntaxErr.Expr, "(?>"):
+		return "an atomic group `(?>`"
+	case syntaxErr.Code == syntax.ErrInvalidEscape && len(syntaxErr.Expr) == 2 && syntaxErr.Expr[1] >= '1' && syntaxErr.Expr[1] <= '9':
+		return fmt.Sprintf("a backreference `%s`", syntaxErr.Expr)
+	case syntaxErr.Code == syntax.ErrInvalidRepeatOp && strings.HasSuffix(syntaxErr.Expr, "+"):
+		return fmt.Sprintf("a possessive quantifier `%s`", syntaxErr.Expr)
+	}
+
+	return ""
+}
+
+// checkBranch return the reason the branch would be rejected, or an empty string
+func (rules yaccRules) checkBranch(branch string) string {
+	if rules.branchName != nil && !rules.branchName.MatchString(branch) {
+		return rules.message(rules.settings.ErrorMessageBranchName, "branch name does not match branchNameRegex %s", rules.settings.BranchNameRegex)
+	}
+
+	return ""
+}
+
+// isExcluded tell if the commit is excluded from the checks
+func (rules yaccRules) isExcluded(commit helper.GitCommit) bool {
+	if rules.settings.ExcludeMergeCommits && commit.IsMerge() {
+		return true
+	}
+
+	return rules.excludeBy != nil && rules.excludeBy.MatchString(commit.Message)
+}
+
+// checkCommit return the reasons the commit would be rejected
+func (rules yaccRules) checkCommit(commit helper.GitCommit) []string {
+	var reasons []string
+
+	if rules.committerEmail != nil && !rules.committerEmail.MatchString(commit.CommitterEmail) {
+		reasons = append(reasons, rules.message(
+			rules.settings.ErrorMessageCommiterEmailRegex,
+			"committer email %s does not match committerEmailRegex %s",
+			commit.CommitterEmail,
+			rules.settings.CommitterEmailRegex,
+		))
+	}
+
+	if rules.commitMessage != nil && !rules.commitMessage.MatchString(commit.Message) {
+		reasons = append(reasons, rules.message(
+			rules.settings.ErrorMessageCommitRegex,
+			"commit message does not match commitMessageRegex %s",
+			rules.settings.CommitMessageRegex,
+		))
+	}
+
+	return reasons
+}
+
+// message return the custom error message when configured, the formatted default one otherwise
+func (rules yaccRules) message(custom string, format string, args ...interface{}) string {
+	if len(custom) > 0 {
+		return custom
+	}
+
+	return fmt.Sprintf(format, args...)
+}
@@ -2,14 +2,15 @@
 package hooks
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/daeMOn63/bitadmin/helper"
 	"github.com/daeMOn63/bitadmin/settings"
-	"github.com/urfave/cli"
-	"encoding/json"
 	"github.com/daeMOn63/bitclient"
 	"github.com/google/go-cmp/cmp"
+	"github.com/urfave/cli"
+	"os"
 	"strings"
 	"unicode"
 )
@@ -34,6 +35,16 @@ func (command *YaccHookCommand) GetCommand() cli.Command {
 		flags:    &YaccHookDisableCommandFlags{},
 	}
 
+	yaccSetSettingsCommand := YaccHookSetSettingsCommand{
+		Settings: command.Settings,
+		flags:    &YaccHookSetSettingsCommandFlags{},
+	}
+
+	yaccTestCommand := YaccHookTestCommand{
+		Settings: command.Settings,
+		flags:    &YaccHookTestCommandFlags{},
+	}
+
 	yaccHookSettingsCommand := YaccHookSettingsCommand{
 		Settings: command.Settings,
 		flags:    &YaccHookGetSettingsCommandFlags{},
@@ -51,7 +62,9 @@ func (command *YaccHookCommand) GetCommand() cli.Command {
 			yaccEnableCommand.GetCommand(),
 			yaccDisableCommand.GetCommand(),
 			yaccHookSettingsCommand.GetCommand(),
+			yaccSetSettingsCommand.GetCommand(),
 			yaccDiffHookSettingsCommand.GetCommand(),
+			yaccTestCommand.GetCommand(),
 		},
 	}
 }
@@ -62,36 +75,13 @@ type YaccHookEnableCommand struct {
 	flags    *YaccHookEnableCommandFlags
 }
 
-// YaccSettings define the settings of the YACC hook
-type YaccSettings struct {
-	RequireMatchingAuthorEmail     bool   `json:"requireMatchingAuthorEmail,omitempty"`
-	RequireMatchingAuthorName      bool   `json:"requireMatchingAuthorName,omitempty"`
-	CommitterEmailRegex            string `json:"committerEmailRegex,omitempty"`
-	CommitMessageRegex             string `json:"commitMessageRegex,omitempty"`
-	RequireJiraIssue               bool   `json:"requireJiraIssue,omitempty"`
-	IgnoreUnknownIssueProjectKeys  bool   `json:"ignoreUnknownIssueProjectKeys,omitempty"`
-	IssueJqlMatcher                string `json:"issueJqlMatcher,omitempty"`
-	BranchNameRegex                string `json:"branchNameRegex,omitempty"`
-	ErrorMessageHeader             string `json:"errorMessageHeader,omitempty"`
-	ErrorMessageCommiterEmail      string `json:"errorMessage.COMMITER_EMAIL,omitempty"`
-	ErrorMessageCommiterEmailRegex string `json:"errorMessage.COMMITER_EMAIL_REGEX,omitempty"`
-	ErrorMessageCommiterName       string `json:"errorMessage.COMMITER_NAME,omitempty"`
-	ErrorMessageCommitRegex        string `json:"errorMessage.COMMIT_REGEX,omitempty"`
-	ErrorMessageIssueJQL           string `json:"errorMessage.ISSUE_JQL,omitempty"`
-	ErrorMessageBranchName         string `json:"errorMessage.BRANCH_NAME,omitempty"`
-	ErrorMessageFooter             string `json:"errorMessageFooter,omitempty"`
-	ExcludeMergeCommits            bool   `json:"excludeMergeCommits,omitempty"`
-	ExcludeByRegex                 string `json:"excludeByRegex,omitempty"`
-	ExcludeBranchRegex             string `json:"excludeBranchRegex,omitempty"`
-	ExcludeServiceUserCommits      bool   `json:"excludeServiceUserCommits,omitempty"`
-	ExcludeUsers                   string `json:"excludeUsers,omitempty"`
-}
-
 // YaccHookEnableCommandFlags define the flags for the YaccHookEnableCommand
 type YaccHookEnableCommandFlags struct {
-	project    string
-	repository string
-	settings   YaccSettings
+	project      string
+	repository   string
+	projectLevel bool
+	settingsFile string
+	settings     bitclient.YaccHookSettings
 }
 
 // GetCommand provide a ready to use cli.Command
@@ -100,7 +90,7 @@ func (command *YaccHookEnableCommand) GetCommand() cli.Command {
 		Name:   "enable",
 		Usage:  "Enable Yet Another Commit Checker hook and set its configuration",
 		Action: command.EnableAction,
-		Flags: []cli.Flag{
+		Flags: append([]cli.Flag{
 			cli.StringFlag{
 				Name:        "project",
 				Usage:       "The `<project_key>` containing the repository to enable the hook on",
@@ -108,124 +98,74 @@ func (command *YaccHookEnableCommand) GetCommand() cli.Command {
 			},
 			cli.StringFlag{
 				Name:        "repository",
-				Usage:       "The `<repository_name>` to enable the hook on",
+				Usage:       "The `<repository_name>` holding the hook",
 				Destination: &command.flags.repository,
 			},
-			cli.BoolFlag{
-				Name:        "requireMatchingAuthorEmail",
-				Usage:       "Require that the commit committer's email matches the Stash user's email.",
-				Destination: &command.flags.settings.RequireMatchingAuthorEmail,
-			},
-			cli.BoolFlag{
-				Name:        "requireMatchingAuthorName",
-				Usage:       "Require that the commit committer's name matches the Stash user's name.",
-				Destination: &command.flags.settings.RequireMatchingAuthorName,
-			},
-			cli.StringFlag{
-				Name:        "committerEmailRegex",
-				Usage:       "Require that commit email match this regular expression.",
-				Destination: &command.flags.settings.CommitterEmailRegex,
-			},
-			cli.StringFlag{
-				Name:        "commitMessageRegex",
-				Usage:       "Require that commit messages match this regular expression.",
-				Destination: &command.flags.settings.CommitMessageRegex,
-			},
-			cli.BoolFlag{
-				Name:        "requireJiraIssue",
-				Usage:       "Require that the commit message contains valid JIRA issue(s).",
-				Destination: &command.flags.settings.RequireJiraIssue,
-			},
-			cli.BoolFlag{
-				Name:        "ignoreUnknownIssueProjectKeys",
-				Usage:       "Items in the commit message that do not contain a valid JIRA project key (such as UTF-8) will be ignored.",
-				Destination: &command.flags.settings.IgnoreUnknownIssueProjectKeys,
-			},
-			cli.StringFlag{
-				Name:        "issueJqlMatcher",
-				Usage:       "If present, JIRA issues must match this JQL query.",
-				Destination: &command.flags.settings.IssueJqlMatcher,
-			},
-			cli.StringFlag{
-				Name:        "branchNameRegex",
-				Usage:       "If present, only branches with names that match this regex will be allowed to be created.",
-				Destination: &command.flags.settings.BranchNameRegex,
-			},
-			cli.StringFlag{
-				Name:        "errorMessageHeader",
-				Usage:       "If present, the default error message header will be replaced by this text.",
-				Destination: &command.flags.settings.ErrorMessageHeader,
-			},
-			cli.StringFlag{
-				Name:        "errorMessageCommiterEmail",
-				Usage:       "If present, this text will be shown when the Require Matching Committer Email check fails.",
-				Destination: &command.flags.settings.ErrorMessageCommiterEmail,
-			},
-			cli.StringFlag{
-				Name:        "errorMessageCommiterEmailRegex",
-				Usage:       "If present, this text will be shown when the Committer Email Regex check fails.",
-				Destination: &command.flags.settings.ErrorMessageCommiterEmailRegex,
-			},
-			cli.StringFlag{
-				Name:        "errorMessageCommiterName",
-				Usage:       "If present, this text will be shown when the Require Matching Committer Name check fails.",
-				Destination: &command.flags.settings.ErrorMessageCommiterName,
-			},
-			cli.StringFlag{
-				Name:        "errorMessageCommitRegex",
-				Usage:       "If present, this text will be shown when the Commit Message Regex check fails.",
-				Destination: &command.flags.settings.ErrorMessageCommitRegex,
-			},
-			cli.StringFlag{
-				Name:        "errorMessageIssueJQL",
-				Usage:       "If present, this text will be shown when the Issue Jql Matcher check fails.",
-				Destination: &command.flags.settings.ErrorMessageIssueJQL,
-			},
-			cli.StringFlag{
-				Name:        "errorMessageBranchName",
-				Usage:       "If present, this text will be shown when the Branch Name Regex check fails.",
-				Destination: &command.flags.settings.ErrorMessageBranchName,
-			},
-			cli.StringFlag{
-				Name:        "errorMessageFooter",
-				Usage:       "If present, this text will be included at the end of the YACC error message.",
-				Destination: &command.flags.settings.ErrorMessageFooter,
-			},
-			cli.BoolFlag{
-				Name:        "excludeMergeCommits",
-				Usage:       "Exclude merge commits from commit requirements.",
-				Destination: &command.flags.settings.ExcludeMergeCommits,
-			},
+			projectLevelFlag(&command.flags.projectLevel),
 			cli.StringFlag{
-				Name:        "excludeByRegex",
-				Usage:       "Exclude commits if commit message matches this regex.",
-				Destination: &command.flags.settings.ExcludeByRegex,
+				Name:        "settings-file",
+				Usage:       "Replace the settings with the JSON or YAML `<file>`, flags provided along override its values. Without a file, flags are merged into the current settings",
+				Destination: &command.flags.settingsFile,
 			},
-			cli.StringFlag{
-				Name:        "excludeBranchRegex",
-				Usage:       "Exclude commits to branches matching this regex.",
-				Destination: &command.flags.settings.ExcludeBranchRegex,
-			},
-			cli.BoolFlag{
-				Name:        "excludeServiceUserCommits",
-				Usage:       "Exclude commits from service users with access keys (e.g. CI Server) from commit requirements.",
-				Destination: &command.flags.settings.ExcludeServiceUserCommits,
-			},
-			cli.StringFlag{
-				Name:        "excludeUsers",
-				Usage:       "Exclude commits from users. Separate multiple user names with a comma.",
-				Destination: &command.flags.settings.ExcludeUsers,
-			},
-		},
+		}, yaccSettingsFlags("", &command.flags.settings)...),
 		BashComplete: func(c *cli.Context) {
 			helper.AutoComplete(c, command.Settings.GetFileCache())
 		},
 	}
 }
 
+// yaccSettingField bind a YACC setting to its command line flag
+type yaccSettingField struct {
+	flag  string
+	usage string
+	// field return the address of the setting, either a *bool or a *string
+	field func(settings *bitclient.YaccHookSettings) interface{}
+}
+
+// yaccSettingFields list the YACC settings configurable from the command line
+var yaccSettingFields = []yaccSettingField{
+	{"requireMatchingAuthorEmail", "Require that the commit committer's email matches the Stash user's email.", func(s *bitclient.YaccHookSettings) interface{} { return &s.RequireMatchingAuthorEmail }},
+	{"requireMatchingAuthorName", "Require that the commit committer's name matches the Stash user's name.", func(s *bitclient.YaccHookSettings) interface{} { return &s.RequireMatchingAuthorName }},
+	{"committerEmailRegex", "Require that commit email match this regular expression.", func(s *bitclient.YaccHookSettings) interface{} { return &s.CommitterEmailRegex }},
+	{"commitMessageRegex", "Require that commit messages match this regular expression.", func(s *bitclient.YaccHookSettings) interface{} { return &s.CommitMessageRegex }},
+	{"requireJiraIssue", "Require that the commit message contains valid JIRA issue(s).", func(s *bitclient.YaccHookSettings) interface{} { return &s.RequireJiraIssue }},
+	{"ignoreUnknownIssueProjectKeys", "Items in the commit message that do not contain a valid JIRA project key (such as UTF-8) will be ignored.", func(s *bitclient.YaccHookSettings) interface{} { return &s.IgnoreUnknownIssueProjectKeys }},
+	{"issueJqlMatcher", "If present, JIRA issues must match this JQL query.", func(s *bitclient.YaccHookSettings) interface{} { return &s.IssueJqlMatcher }},
+	{"branchNameRegex", "If present, only branches with names that match this regex will be allowed to be created.", func(s *bitclient.YaccHookSettings) interface{} { return &s.BranchNameRegex }},
+	{"errorMessageHeader", "If present, the default error message header will be replaced by this text.", func(s *bitclient.YaccHookSettings) interface{} { return &s.ErrorMessageHeader }},
+	{"errorMessageCommiterEmail", "If present, this text will be shown when the Require Matching Committer Email check fails.", func(s *bitclient.YaccHookSettings) interface{} { return &s.ErrorMessageCommiterEmail }},
+	{"errorMessageCommiterEmailRegex", "If present, this text will be shown when the Committer Email Regex check fails.", func(s *bitclient.YaccHookSettings) interface{} { return &s.ErrorMessageCommiterEmailRegex }},
+	{"errorMessageCommiterName", "If present, this text will be shown when the Require Matching Committer Name check fails.", func(s *bitclient.YaccHookSettings) interface{} { return &s.ErrorMessageCommiterName }},
+	{"errorMessageCommitRegex", "If present, this text will be shown when the Commit Message Regex check fails.", func(s *bitclient.YaccHookSettings) interface{} { return &s.ErrorMessageCommitRegex }},
+	{"errorMessageIssueJQL", "If present, this text will be shown when the Issue Jql Matcher check fails.", func(s *bitclient.YaccHookSettings) interface{} { return &s.ErrorMessageIssueJQL }},
+	{"errorMessageBranchName", "If present, this text will be shown when the Branch Name Regex check fails.", func(s *bitclient.YaccHookSettings) interface{} { return &s.ErrorMessageBranchName }},
+	{"errorMessageFooter", "If present, this text will be included at the end of the YACC error message.", func(s *bitclient.YaccHookSettings) interface{} { return &s.ErrorMessageFooter }},
+	{"excludeMergeCommits", "Exclude merge commits from commit requirements.", func(s *bitclient.YaccHookSettings) interface{} { return &s.ExcludeMergeCommits }},
+	{"excludeByRegex", "Exclude commits if commit message matches this regex.", func(s *bitclient.YaccHookSettings) interface{} { return &s.ExcludeByRegex }},
+	{"excludeBranchRegex", "Exclude commits to branches matching this regex.", func(s *bitclient.YaccHookSettings) interface{} { return &s.ExcludeBranchRegex }},
+	{"excludeServiceUserCommits", "Exclude commits from service users with access keys (e.g. CI Server) from commit requirements.", func(s *bitclient.YaccHookSettings) interface{} { return &s.ExcludeServiceUserCommits }},
+	{"excludeUsers", "Exclude commits from users. Separate multiple user names with a comma.", func(s *bitclient.YaccHookSettings) interface{} { return &s.ExcludeUsers }},
+}
+
+// yaccSettingsFlags provide a flag for each YACC setting, bound to settings
+func yaccSettingsFlags(prefix string, settings *bitclient.YaccHookSettings) []cli.Flag {
+	var flags []cli.Flag
+
+	for _, setting := range yaccSettingFields {
+		switch destination := setting.field(settings).(type) {
+		case *bool:
+			flags = append(flags, cli.BoolFlag{Name: prefix + setting.flag, Usage: setting.usage, Destination: destination})
+		case *string:
+			flags = append(flags, cli.StringFlag{Name: prefix + setting.flag, Usage: setting.usage, Destination: destination})
+		}
+	}
+
+	return flags
+}
+
 // EnableAction contains logic to turn on the hook and set its configuration
 func (command *YaccHookEnableCommand) EnableAction(context *cli.Context) error {
-	client, err := command.Settings.GetAPIClient()
+	restClient, err := command.Settings.GetRestClient()
 	if err != nil {
 		return err
 	}
@@ -233,22 +173,161 @@ func (command *YaccHookEnableCommand) EnableAction(context *cli.Context) error {
 	if len(command.flags.project) <= 0 {
 		return errors.New("--project flag is required")
 	}
-	if len(command.flags.repository) <= 0 {
-		return errors.New("--repository flag is required")
+	if err := checkHookScope(command.flags.repository, command.flags.projectLevel); err != nil {
+		return err
+	}
+
+	// Without a settings file, the flags are merged into the current settings so the others are kept
+	yaccSettings := bitclient.YaccHookSettings{}
+	if len(command.flags.settingsFile) > 0 {
+		if err := loadYaccSettings(command.flags.settingsFile, &yaccSettings); err != nil {
+			return err
+		}
+	} else {
+		if yaccSettings, err = getYaccSettings(restClient, command.flags.project, command.flags.repository); err != nil {
+			return err
+		}
 	}
+	applyYaccSettingsFlags(context, &yaccSettings, command.flags.settings)
 
-	err = client.EnableHook(
+	_, err = restClient.EnableRepositoryHook(
 		command.flags.project,
 		command.flags.repository,
 		yaccHookKey,
-		command.flags.settings,
+		yaccSettings,
 	)
 
 	if err != nil {
 		return err
 	}
 
-	fmt.Printf("[OK] Enabled and configured yacc hook on %s/%s\n", command.flags.project, command.flags.repository)
+	fmt.Printf("[OK] Enabled and configured yacc hook on %s\n", hookTarget(command.flags.project, command.flags.repository))
+
+	return nil
+}
+
+// loadYaccSettings decode a JSON or YAML settings file over settings, fields absent from the file being left untouched
+func loadYaccSettings(filename string, settings *bitclient.YaccHookSettings) error {
+	data, err := readHookSettings(yaccHookKey, filename)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, settings)
+}
+
+// getYaccSettings retrieve the YACC settings of a repository, or of the project when repositorySlug is empty
+func getYaccSettings(restClient *helper.RestClient, projectKey string, repositorySlug string) (bitclient.YaccHookSettings, error) {
+	yaccSettings := bitclient.YaccHookSettings{}
+
+	data, err := restClient.GetRepositoryHookSettings(projectKey, repositorySlug, yaccHookKey)
+	if err != nil || len(data) == 0 {
+		return yaccSettings, err
+	}
+
+	err = json.Unmarshal(data, &yaccSettings)
+
+	return yaccSettings, err
+}
+
+// applyYaccSettingsFlags copy the settings provided on the command line from flagged to settings
+func applyYaccSettingsFlags(context *cli.Context, settings *bitclient.YaccHookSettings, flagged bitclient.YaccHookSettings) {
+	for _, setting := range yaccSettingFields {
+		if !context.IsSet(setting.flag) {
+			continue
+		}
+
+		switch target := setting.field(settings).(type) {
+		case *bool:
+			*target = *setting.field(&flagged).(*bool)
+		case *string:
+			*target = *setting.field(&flagged).(*string)
+		}
+	}
+}
+
+// YaccHookSetSettingsCommand define the command to update some of the YACC hook settings
+type YaccHookSetSettingsCommand struct {
+	Settings *settings.BitAdminSettings
+	flags    *YaccHookSetSettingsCommandFlags
+}
+
+// YaccHookSetSettingsCommandFlags define the flags of the YaccHookSetSettingsCommand
+type YaccHookSetSettingsCommandFlags struct {
+	project      string
+	repository   string
+	projectLevel bool
+	settingsFile string
+	settings     bitclient.YaccHookSettings
+}
+
+// GetCommand provide a ready to use cli.Command
+func (command *YaccHookSetSettingsCommand) GetCommand() cli.Command {
+	return cli.Command{
+		Name:   "set-settings",
+		Usage:  "Update Yet Another Commit Checker settings, only the provided fields are changed",
+		Action: command.SetSettingsAction,
+		Flags: append([]cli.Flag{
+			cli.StringFlag{
+				Name:        "project",
+				Usage:       "The `<project_key>` containing the repository",
+				Destination: &command.flags.project,
+			},
+			cli.StringFlag{
+				Name:        "repository",
+				Usage:       "The `<repository_name>` holding the hook",
+				Destination: &command.flags.repository,
+			},
+			projectLevelFlag(&command.flags.projectLevel),
+			cli.StringFlag{
+				Name:        "settings-file",
+				Usage:       "Merge the settings from a JSON or YAML `<file>`, flags provided along override its values",
+				Destination: &command.flags.settingsFile,
+			},
+		}, yaccSettingsFlags("", &command.flags.settings)...),
+		BashComplete: func(c *cli.Context) {
+			helper.AutoComplete(c, command.Settings.GetFileCache())
+		},
+	}
+}
+
+// SetSettingsAction contains logic to merge the provided settings into the current ones
+func (command *YaccHookSetSettingsCommand) SetSettingsAction(context *cli.Context) error {
+	restClient, err := command.Settings.GetRestClient()
+	if err != nil {
+		return err
+	}
+
+	if len(command.flags.project) <= 0 {
+		return errors.New("--project flag is required")
+	}
+	if err := checkHookScope(command.flags.repository, command.flags.projectLevel); err != nil {
+		return err
+	}
+
+	yaccSettings, err := getYaccSettings(restClient, command.flags.project, command.flags.repository)
+	if err != nil {
+		return err
+	}
+
+	if len(command.flags.settingsFile) > 0 {
+		if err := loadYaccSettings(command.flags.settingsFile, &yaccSettings); err != nil {
+			return err
+		}
+	}
+	applyYaccSettingsFlags(context, &yaccSettings, command.flags.settings)
+
+	data, err := json.Marshal(yaccSettings)
+	if err != nil {
+		return err
+	}
+
+	_, err = restClient.SetRepositoryHookSettings(command.flags.project, command.flags.repository, yaccHookKey, data)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("[OK] Updated yacc hook settings on %s\n", hookTarget(command.flags.project, command.flags.repository))
 
 	return nil
 }
@@ -261,8 +340,9 @@ type YaccHookDisableCommand struct {
 
 // YaccHookDisableCommandFlags define the flags of the YaccHookDisableCommand
 type YaccHookDisableCommandFlags struct {
-	project    string
-	repository string
+	project      string
+	repository   string
+	projectLevel bool
 }
 
 // GetCommand provide a ready to use cli.Command
@@ -279,9 +359,10 @@ func (command *YaccHookDisableCommand) GetCommand() cli.Command {
 			},
 			cli.StringFlag{
 				Name:        "repository",
-				Usage:       "The `<repository_name>` to create",
+				Usage:       "The `<repository_name>` holding the hook",
 				Destination: &command.flags.repository,
 			},
+			projectLevelFlag(&command.flags.projectLevel),
 		},
 		BashComplete: func(c *cli.Context) {
 			helper.AutoComplete(c, command.Settings.GetFileCache())
@@ -291,7 +372,7 @@ func (command *YaccHookDisableCommand) GetCommand() cli.Command {
 
 // DisableAction contains logic to turn on the hook and set its configuration
 func (command *YaccHookDisableCommand) DisableAction(context *cli.Context) error {
-	client, err := command.Settings.GetAPIClient()
+	restClient, err := command.Settings.GetRestClient()
 	if err != nil {
 		return err
 	}
@@ -299,17 +380,17 @@ func (command *YaccHookDisableCommand) DisableAction(context *cli.Context) error
 	if len(command.flags.project) <= 0 {
 		return errors.New("--project flag is required")
 	}
-	if len(command.flags.repository) <= 0 {
-		return errors.New("--repository flag is required")
+	if err := checkHookScope(command.flags.repository, command.flags.projectLevel); err != nil {
+		return err
 	}
 
-	err = client.DisableHook(command.flags.project, command.flags.repository, yaccHookKey)
+	err = restClient.DisableRepositoryHook(command.flags.project, command.flags.repository, yaccHookKey)
 
 	if err != nil {
 		return err
 	}
 
-	fmt.Printf("[OK] Disabled yacc hook on %s/%s\n", command.flags.project, command.flags.repository)
+	fmt.Printf("[OK] Disabled yacc hook on %s\n", hookTarget(command.flags.project, command.flags.repository))
 
 	return nil
 }
@@ -341,7 +422,7 @@ func (command *YaccHookSettingsCommand) GetCommand() cli.Command {
 			},
 			cli.StringFlag{
 				Name:        "repository",
-				Usage:       "The `<repository_name>` to create",
+				Usage:       "The `<repository_name>` holding the hook, leave empty to target the project",
 				Destination: &command.flags.repository,
 			},
 		},
@@ -353,7 +434,7 @@ func (command *YaccHookSettingsCommand) GetCommand() cli.Command {
 
 // GetSettings contains logic to get current yacc settings
 func (command *YaccHookSettingsCommand) GetSettings(context *cli.Context) error {
-	client, err := command.Settings.GetAPIClient()
+	restClient, err := command.Settings.GetRestClient()
 	if err != nil {
 		return err
 	}
@@ -361,14 +442,11 @@ func (command *YaccHookSettingsCommand) GetSettings(context *cli.Context) error
 	if len(command.flags.project) <= 0 {
 		return errors.New("--project flag is required")
 	}
-	if len(command.flags.repository) <= 0 {
-		return errors.New("--repository flag is required")
-	}
 
-	yacc, err := client.GetYACCHookSettings(
-		command.flags.project,
-		command.flags.repository,
-	)
+	yacc, err := getYaccSettings(restClient, command.flags.project, command.flags.repository)
+	if err != nil {
+		return err
+	}
 
 	data, err := json.Marshal(yacc)
 	if err != nil {
@@ -380,7 +458,6 @@ func (command *YaccHookSettingsCommand) GetSettings(context *cli.Context) error
 	return nil
 }
 
-
 // YaccHookSettingsCommand define the command to get setting for YACC hook
 type YaccHookDiffSettingsCommand struct {
 	Settings *settings.BitAdminSettings
@@ -398,9 +475,13 @@ type YaccHookDiffSettingsCommandFlags struct {
 func (command *YaccHookDiffSettingsCommand) GetCommand() cli.Command {
 	return cli.Command{
 		Name:   "diff-settings",
-		Usage:  "Diff YACC settings of a repository against default settings",
-		Action: command.DiffSettings,
-		Flags: []cli.Flag{
+		Usage:  "Deprecated, use hooks compliance",
+		Hidden: true,
+		Action: func(context *cli.Context) error {
+			fmt.Fprintln(os.Stderr, "[WARN] diff-settings is deprecated, use hooks compliance --key "+yaccHookKey)
+			return command.DiffSettings(context)
+		},
+		Flags: append([]cli.Flag{
 			cli.StringFlag{
 				Name:        "project",
 				Usage:       "The `<project_key>` where the repository will be created",
@@ -408,125 +489,19 @@ func (command *YaccHookDiffSettingsCommand) GetCommand() cli.Command {
 			},
 			cli.StringFlag{
 				Name:        "repository",
-				Usage:       "The `<repository_name>` to create",
+				Usage:       "The `<repository_name>` holding the hook, leave empty to target the project",
 				Destination: &command.flags.repository,
 			},
-			cli.BoolFlag{
-				Name:        "default-requireMatchingAuthorEmail",
-				Usage:       "Require that the commit committer's email matches the Stash user's email.",
-				Destination: &command.flags.defaultSettings.RequireMatchingAuthorEmail,
-			},
-			cli.BoolFlag{
-				Name:        "default-requireMatchingAuthorName",
-				Usage:       "Require that the commit committer's name matches the Stash user's name.",
-				Destination: &command.flags.defaultSettings.RequireMatchingAuthorName,
-			},
-			cli.StringFlag{
-				Name:        "default-committerEmailRegex",
-				Usage:       "Require that commit email match this regular expression.",
-				Destination: &command.flags.defaultSettings.CommitterEmailRegex,
-			},
-			cli.StringFlag{
-				Name:        "default-commitMessageRegex",
-				Usage:       "Require that commit messages match this regular expression.",
-				Destination: &command.flags.defaultSettings.CommitMessageRegex,
-			},
-			cli.BoolFlag{
-				Name:        "default-requireJiraIssue",
-				Usage:       "Require that the commit message contains valid JIRA issue(s).",
-				Destination: &command.flags.defaultSettings.RequireJiraIssue,
-			},
-			cli.BoolFlag{
-				Name:        "default-ignoreUnknownIssueProjectKeys",
-				Usage:       "Items in the commit message that do not contain a valid JIRA project key (such as UTF-8) will be ignored.",
-				Destination: &command.flags.defaultSettings.IgnoreUnknownIssueProjectKeys,
-			},
-			cli.StringFlag{
-				Name:        "default-issueJqlMatcher",
-				Usage:       "If present, JIRA issues must match this JQL query.",
-				Destination: &command.flags.defaultSettings.IssueJqlMatcher,
-			},
-			cli.StringFlag{
-				Name:        "default-branchNameRegex",
-				Usage:       "If present, only branches with names that match this regex will be allowed to be created.",
-				Destination: &command.flags.defaultSettings.BranchNameRegex,
-			},
-			cli.StringFlag{
-				Name:        "default-errorMessageHeader",
-				Usage:       "If present, the default error message header will be replaced by this text.",
-				Destination: &command.flags.defaultSettings.ErrorMessageHeader,
-			},
-			cli.StringFlag{
-				Name:        "default-errorMessageCommiterEmail",
-				Usage:       "If present, this text will be shown when the Require Matching Committer Email check fails.",
-				Destination: &command.flags.defaultSettings.ErrorMessageCommiterEmail,
-			},
-			cli.StringFlag{
-				Name:        "default-errorMessageCommiterEmailRegex",
-				Usage:       "If present, this text will be shown when the Committer Email Regex check fails.",
-				Destination: &command.flags.defaultSettings.ErrorMessageCommiterEmailRegex,
-			},
-			cli.StringFlag{
-				Name:        "default-errorMessageCommiterName",
-				Usage:       "If present, this text will be shown when the Require Matching Committer Name check fails.",
-				Destination: &command.flags.defaultSettings.ErrorMessageCommiterName,
-			},
-			cli.StringFlag{
-				Name:        "default-errorMessageCommitRegex",
-				Usage:       "If present, this text will be shown when the Commit Message Regex check fails.",
-				Destination: &command.flags.defaultSettings.ErrorMessageCommitRegex,
-			},
-			cli.StringFlag{
-				Name:        "default-errorMessageIssueJQL",
-				Usage:       "If present, this text will be shown when the Issue Jql Matcher check fails.",
-				Destination: &command.flags.defaultSettings.ErrorMessageIssueJQL,
-			},
-			cli.StringFlag{
-				Name:        "default-errorMessageBranchName",
-				Usage:       "If present, this text will be shown when the Branch Name Regex check fails.",
-				Destination: &command.flags.defaultSettings.ErrorMessageBranchName,
-			},
-			cli.StringFlag{
-				Name:        "default-errorMessageFooter",
-				Usage:       "If present, this text will be included at the end of the YACC error message.",
-				Destination: &command.flags.defaultSettings.ErrorMessageFooter,
-			},
-			cli.BoolFlag{
-				Name:        "default-excludeMergeCommits",
-				Usage:       "Exclude merge commits from commit requirements.",
-				Destination: &command.flags.defaultSettings.ExcludeMergeCommits,
-			},
-			cli.StringFlag{
-				Name:        "default-excludeByRegex",
-				Usage:       "Exclude commits if commit message matches this regex.",
-				Destination: &command.flags.defaultSettings.ExcludeByRegex,
-			},
-			cli.StringFlag{
-				Name:        "default-excludeBranchRegex",
-				Usage:       "Exclude commits to branches matching this regex.",
-				Destination: &command.flags.defaultSettings.ExcludeBranchRegex,
-			},
-			cli.BoolFlag{
-				Name:        "default-excludeServiceUserCommits",
-				Usage:       "Exclude commits from service users with access keys (e.g. CI Server) from commit requirements.",
-				Destination: &command.flags.defaultSettings.ExcludeServiceUserCommits,
-			},
-			cli.StringFlag{
-				Name:        "default-excludeUsers",
-				Usage:       "Exclude commits from users. Separate multiple user names with a comma.",
-				Destination: &command.flags.defaultSettings.ExcludeUsers,
-			},
-		},
+		}, yaccSettingsFlags("default-", &command.flags.defaultSettings)...),
 		BashComplete: func(c *cli.Context) {
 			helper.AutoComplete(c, command.Settings.GetFileCache())
 		},
 	}
 }
 
-
 // DiffSettings contains logic to get diff between defined default settings and current setting got for specific repository
 func (command *YaccHookDiffSettingsCommand) DiffSettings(context *cli.Context) error {
-	client, err := command.Settings.GetAPIClient()
+	restClient, err := command.Settings.GetRestClient()
 	if err != nil {
 		return err
 	}
@@ -534,14 +509,11 @@ func (command *YaccHookDiffSettingsCommand) DiffSettings(context *cli.Context) e
 	if len(command.flags.project) <= 0 {
 		return errors.New("--project flag is required")
 	}
-	if len(command.flags.repository) <= 0 {
-		return errors.New("--repository flag is required")
-	}
 
-	yacc, err := client.GetYACCHookSettings(
-		command.flags.project,
-		command.flags.repository,
-	)
+	yacc, err := getYaccSettings(restClient, command.flags.project, command.flags.repository)
+	if err != nil {
+		return err
+	}
 
 	data, err := json.Marshal(yacc)
 	if err != nil {
@@ -554,8 +526,8 @@ func (command *YaccHookDiffSettingsCommand) DiffSettings(context *cli.Context) e
 		return nil
 	}
 
-	isCorrect  := "NO"
-	isOK, diff := isYACCSettingsCorrectWithDiff(command.flags.defaultSettings,yacc)
+	isCorrect := "NO"
+	isOK, diff := isYACCSettingsCorrectWithDiff(command.flags.defaultSettings, yacc)
 
 	if isOK == true {
 		isCorrect = "YES"
@@ -619,4 +591,4 @@ func (r *DiffReporter) PopStep() {
 
 func (r *DiffReporter) String() string {
 	return strings.Join(r.diffs, "\n")
-}
\ No newline at end of file
+}
@@ -0,0 +1,283 @@
+// Package project hold actions on the Bitbucket projects
+package project
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/daeMOn63/bitadmin/helper"
+	"github.com/daeMOn63/bitadmin/settings"
+	"github.com/urfave/cli"
+)
+
+// AccessKeysCommand define base struct for project access keys actions
+type AccessKeysCommand struct {
+	Settings *settings.BitAdminSettings
+}
+
+// GetCommand provide a ready to use cli.Command
+func (command *AccessKeysCommand) GetCommand() cli.Command {
+
+	listCommand := &ListAccessKeysCommand{
+		Settings: command.Settings,
+		flags:    &ListAccessKeysCommandFlags{},
+	}
+
+	addCommand := &AddAccessKeyCommand{
+		Settings: command.Settings,
+		flags:    &AddAccessKeyCommandFlags{},
+	}
+
+	removeCommand := &RemoveAccessKeyCommand{
+		Settings: command.Settings,
+		flags:    &RemoveAccessKeyCommandFlags{},
+	}
+
+	return cli.Command{
+		Name:  "access-keys",
+		Usage: "Project access keys (ssh deploy keys) operations",
+		Subcommands: []cli.Command{
+			listCommand.GetCommand(),
+			addCommand.GetCommand(),
+			removeCommand.GetCommand(),
+		},
+	}
+}
+
+// ListAccessKeysCommand define base struct for ListAccessKeys actions
+type ListAccessKeysCommand struct {
+	Settings *settings.BitAdminSettings
+	flags    *ListAccessKeysCommandFlags
+}
+
+// ListAccessKeysCommandFlags hold flag values for the ListAccessKeysCommand
+type ListAccessKeysCommandFlags struct {
+	project string
+}
+
+// GetCommand provide a ready to use cli.Command
+func (command *ListAccessKeysCommand) GetCommand() cli.Command {
+	return cli.Command{
+		Name:   "list",
+		Usage:  "List access keys of given project",
+		Action: command.ListAccessKeysAction,
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:        "project",
+				Usage:       "The `<project_key>` to list access keys from",
+				Destination: &command.flags.project,
+			},
+		},
+		BashComplete: func(c *cli.Context) {
+			helper.AutoComplete(c, command.Settings.GetFileCache())
+		},
+	}
+}
+
+// ListAccessKeysAction print the access keys of given project
+func (command *ListAccessKeysCommand) ListAccessKeysAction(context *cli.Context) error {
+	if len(command.flags.project) <= 0 {
+		return errors.New("--project flag is required")
+	}
+
+	restClient, err := command.Settings.GetRestClient()
+	if err != nil {
+		return err
+	}
+
+	keys, err := restClient.GetProjectAccessKeys(command.flags.project)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		fmt.Println(key)
+	}
+
+	return nil
+}
+
+// AddAccessKeyCommand define base struct for AddAccessKey actions
+type AddAccessKeyCommand struct {
+	Settings *settings.BitAdminSettings
+	flags    *AddAccessKeyCommandFlags
+}
+
+// AddAccessKeyCommandFlags hold flag values for the AddAccessKeyCommand
+type AddAccessKeyCommandFlags struct {
+	project    string
+	keyFiles   cli.StringSlice
+	permission string
+}
+
+// GetCommand provide a ready to use cli.Command
+func (command *AddAccessKeyCommand) GetCommand() cli.Command {
+	return cli.Command{
+		Name:   "add",
+		Usage:  "Add access keys on a project",
+		Action: command.AddAccessKeyAction,
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:        "project",
+				Usage:       "The `<project_key>` to add the keys on",
+				Destination: &command.flags.project,
+			},
+			cli.StringSliceFlag{
+				Name:  "key-file",
+				Usage: "The `<file>` containing the public ssh key to add. Can be repeated multiple times",
+				Value: &command.flags.keyFiles,
+			},
+			cli.StringFlag{
+				Name:        "permission",
+				Value:       "read",
+				Usage:       "The `<permission>` granted to the keys (one of read, write)",
+				Destination: &command.flags.permission,
+			},
+		},
+		BashComplete: func(c *cli.Context) {
+			helper.AutoCompleteWith(c, command.Settings.GetFileCache(), helper.CompletionProviders{
+				"permission": helper.StaticValues("read", "write"),
+			})
+		},
+	}
+}
+
+// AddAccessKeyAction add the keys on given project. Keys already present only get their permission updated when it differs.
+func (command *AddAccessKeyCommand) AddAccessKeyAction(context *cli.Context) error {
+	if len(command.flags.project) <= 0 {
+		return errors.New("--project flag is required")
+	}
+	if len(command.flags.keyFiles) == 0 {
+		return errors.New("At least one --key-file is required")
+	}
+
+	permission, err := helper.AccessKeyPermission("PROJECT", command.flags.permission)
+	if err != nil {
+		return err
+	}
+
+	keys, err := helper.ReadSSHKeys(command.flags.keyFiles)
+	if err != nil {
+		return err
+	}
+
+	restClient, err := command.Settings.GetRestClient()
+	if err != nil {
+		return err
+	}
+
+	existingKeys, err := restClient.GetProjectAccessKeys(command.flags.project)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		fingerprint, err := key.Fingerprint()
+		if err != nil {
+			fmt.Printf("[FAIL] cannot read the fingerprint of key %s: %s\n", key.Label, err)
+			continue
+		}
+
+		if existing := helper.FindAccessKey(existingKeys, fingerprint); existing != nil {
+			if existing.Permission == permission {
+				fmt.Printf("[SKIP] key %s already present on project %s with %s\n", fingerprint, command.flags.project, permission)
+				continue
+			}
+
+			_, err = restClient.UpdateProjectAccessKeyPermission(command.flags.project, existing.Key.ID, permission)
+			if err != nil {
+				return fmt.Errorf("cannot update key %s on project %s: %w", fingerprint, command.flags.project, err)
+			}
+
+			fmt.Printf("[OK] key %s updated on project %s from %s to %s\n", fingerprint, command.flags.project, existing.Permission, permission)
+			continue
+		}
+
+		_, err = restClient.AddProjectAccessKey(command.flags.project, key, permission)
+		if err != nil {
+			return fmt.Errorf("cannot add key %s on project %s: %w", fingerprint, command.flags.project, err)
+		}
+
+		fmt.Printf("[OK] key %s added on project %s with %s\n", fingerprint, command.flags.project, permission)
+	}
+
+	return nil
+}
+
+// RemoveAccessKeyCommand define base struct for RemoveAccessKey actions
+type RemoveAccessKeyCommand struct {
+	Settings *settings.BitAdminSettings
+	flags    *RemoveAccessKeyCommandFlags
+}
+
+// RemoveAccessKeyCommandFlags hold flag values for the RemoveAccessKeyCommand
+type RemoveAccessKeyCommandFlags struct {
+	project     string
+	fingerprint string
+	keyFile     string
+}
+
+// GetCommand provide a ready to use cli.Command
+func (command *RemoveAccessKeyCommand) GetCommand() cli.Command {
+	return cli.Command{
+		Name:   "remove",
+		Usage:  "Remove an access key from a project",
+		Action: command.RemoveAccessKeyAction,
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:        "project",
+				Usage:       "The `<project_key>` to remove the key from",
+				Destination: &command.flags.project,
+			},
+			cli.StringFlag{
+				Name:        "fingerprint",
+				Usage:       "The `<fingerprint>` of the key to remove (SHA256 or MD5)",
+				Destination: &command.flags.fingerprint,
+			},
+			cli.StringFlag{
+				Name:        "key-file",
+				Usage:       "The `<file>` containing the public ssh key to remove",
+				Destination: &command.flags.keyFile,
+			},
+		},
+		BashComplete: func(c *cli.Context) {
+			helper.AutoComplete(c, command.Settings.GetFileCache())
+		},
+	}
+}
+
+// RemoveAccessKeyAction remove the key from given project
+func (command *RemoveAccessKeyCommand) RemoveAccessKeyAction(context *cli.Context) error {
+	if len(command.flags.project) <= 0 {
+		return errors.New("--project flag is required")
+	}
+
+	fingerprint, err := helper.ResolveFingerprint(command.flags.fingerprint, command.flags.keyFile)
+	if err != nil {
+		return err
+	}
+
+	restClient, err := command.Settings.GetRestClient()
+	if err != nil {
+		return err
+	}
+
+	keys, err := restClient.GetProjectAccessKeys(command.flags.project)
+	if err != nil {
+		return err
+	}
+
+	key := helper.FindAccessKey(keys, fingerprint)
+	if key == nil {
+		return fmt.Errorf("key %s is not installed on project %s", fingerprint, command.flags.project)
+	}
+
+	err = restClient.DeleteProjectAccessKey(command.flags.project, key.Key.ID)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("[OK] key %s removed from project %s\n", fingerprint, command.flags.project)
+
+	return nil
+}
@@ -0,0 +1,28 @@
+// Package project hold actions on the Bitbucket projects
+package project
+
+import (
+	"github.com/daeMOn63/bitadmin/settings"
+	"github.com/urfave/cli"
+)
+
+// Command define base struct for project subcommands and actions
+type Command struct {
+	Settings *settings.BitAdminSettings
+}
+
+// GetCommand provide a ready to use cli.Command
+func (command *Command) GetCommand() cli.Command {
+
+	accessKeysCommand := &AccessKeysCommand{
+		Settings: command.Settings,
+	}
+
+	return cli.Command{
+		Name:  "project",
+		Usage: "Project operations",
+		Subcommands: []cli.Command{
+			accessKeysCommand.GetCommand(),
+		},
+	}
+}
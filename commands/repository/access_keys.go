@@ -0,0 +1,504 @@
+// Package repository hold actions on the Bitbucket repositories
+package repository
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/daeMOn63/bitadmin/helper"
+	"github.com/daeMOn63/bitadmin/settings"
+	"github.com/urfave/cli"
+)
+
+// AccessKeysCommand define base struct for repository access keys actions
+type AccessKeysCommand struct {
+	Settings *settings.BitAdminSettings
+}
+
+// GetCommand provide a ready to use cli.Command
+func (command *AccessKeysCommand) GetCommand() cli.Command {
+
+	listCommand := &ListAccessKeysCommand{
+		Settings: command.Settings,
+		flags:    &ListAccessKeysCommandFlags{},
+	}
+
+	addCommand := &AddAccessKeyCommand{
+		Settings: command.Settings,
+		flags:    &AddAccessKeyCommandFlags{},
+	}
+
+	removeCommand := &RemoveAccessKeyCommand{
+		Settings: command.Settings,
+		flags:    &RemoveAccessKeyCommandFlags{},
+	}
+
+	findCommand := &FindAccessKeyCommand{
+		Settings: command.Settings,
+		flags:    &FindAccessKeyCommandFlags{},
+	}
+
+	return cli.Command{
+		Name:  "access-keys",
+		Usage: "Repository access keys (ssh deploy keys) operations",
+		Subcommands: []cli.Command{
+			listCommand.GetCommand(),
+			addCommand.GetCommand(),
+			removeCommand.GetCommand(),
+			findCommand.GetCommand(),
+		},
+	}
+}
+
+// ListAccessKeysCommand define base struct for ListAccessKeys actions
+type ListAccessKeysCommand struct {
+	Settings *settings.BitAdminSettings
+	flags    *ListAccessKeysCommandFlags
+}
+
+// ListAccessKeysCommandFlags hold flag values for the ListAccessKeysCommand
+type ListAccessKeysCommandFlags struct {
+	project    string
+	repository string
+}
+
+// GetCommand provide a ready to use cli.Command
+func (command *ListAccessKeysCommand) GetCommand() cli.Command {
+	return cli.Command{
+		Name:   "list",
+		Usage:  "List access keys of given repository",
+		Action: command.ListAccessKeysAction,
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:        "project",
+				Usage:       "The `<project_key>` of the repository",
+				Destination: &command.flags.project,
+			},
+			cli.StringFlag{
+				Name:        "repository",
+				Usage:       "The `<repository_slug>` to list access keys from",
+				Destination: &command.flags.repository,
+			},
+		},
+		BashComplete: func(c *cli.Context) {
+			helper.AutoComplete(c, command.Settings.GetFileCache())
+		},
+	}
+}
+
+// ListAccessKeysAction print the access keys of given repository
+func (command *ListAccessKeysCommand) ListAccessKeysAction(context *cli.Context) error {
+	if len(command.flags.project) <= 0 {
+		return errors.New("--project flag is required")
+	}
+	if len(command.flags.repository) <= 0 {
+		return errors.New("--repository flag is required")
+	}
+
+	restClient, err := command.Settings.GetRestClient()
+	if err != nil {
+		return err
+	}
+
+	keys, err := restClient.GetRepositoryAccessKeys(command.flags.project, command.flags.repository)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		fmt.Println(key)
+	}
+
+	return nil
+}
+
+// AddAccessKeyCommand define base struct for AddAccessKey actions
+type AddAccessKeyCommand struct {
+	Settings *settings.BitAdminSettings
+	flags    *AddAccessKeyCommandFlags
+}
+
+// AddAccessKeyCommandFlags hold flag values for the AddAccessKeyCommand
+type AddAccessKeyCommandFlags struct {
+	project    string
+	repository string
+	keyFiles   cli.StringSlice
+	permission string
+}
+
+// GetCommand provide a ready to use cli.Command
+func (command *AddAccessKeyCommand) GetCommand() cli.Command {
+	return cli.Command{
+		Name:   "add",
+		Usage:  "Add access keys on repositories",
+		Action: command.AddAccessKeyAction,
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:        "project",
+				Usage:       "The `<project_key>` of the repositories, shell patterns are allowed (ie: PRJ*)",
+				Destination: &command.flags.project,
+			},
+			cli.StringFlag{
+				Name:        "repository",
+				Usage:       "The `<repository_slug>` to add the keys on, shell patterns are allowed (ie: *-api)",
+				Destination: &command.flags.repository,
+			},
+			cli.StringSliceFlag{
+				Name:  "key-file",
+				Usage: "The `<file>` containing the public ssh key to add. Can be repeated multiple times",
+				Value: &command.flags.keyFiles,
+			},
+			cli.StringFlag{
+				Name:        "permission",
+				Value:       "read",
+				Usage:       "The `<permission>` granted to the keys (one of read, write)",
+				Destination: &command.flags.permission,
+			},
+		},
+		BashComplete: func(c *cli.Context) {
+			helper.AutoCompleteWith(c, command.Settings.GetFileCache(), helper.CompletionProviders{
+				"permission": helper.StaticValues("read", "write"),
+			})
+		},
+	}
+}
+
+// AddAccessKeyAction add the keys on every repository matching the project / repository flags.
+// Keys already present on a repository only get their permission updated when it differs.
+func (command *AddAccessKeyCommand) AddAccessKeyAction(context *cli.Context) error {
+	if len(command.flags.project) <= 0 {
+		return errors.New("--project flag is required")
+	}
+	if len(command.flags.repository) <= 0 {
+		return errors.New("--repository flag is required")
+	}
+	if len(command.flags.keyFiles) == 0 {
+		return errors.New("At least one --key-file is required")
+	}
+
+	permission, err := helper.AccessKeyPermission("REPO", command.flags.permission)
+	if err != nil {
+		return err
+	}
+
+	keys, err := helper.ReadSSHKeys(command.flags.keyFiles)
+	if err != nil {
+		return err
+	}
+
+	client, err := command.Settings.GetAPIClient()
+	if err != nil {
+		return err
+	}
+
+	restClient, err := command.Settings.GetRestClient()
+	if err != nil {
+		return err
+	}
+
+	selector := helper.RepositorySelector{Project: command.flags.project, Repository: command.flags.repository}
+	repositories, err := selector.Select(client)
+	if err != nil {
+		return err
+	}
+
+	if len(repositories) == 0 {
+		return fmt.Errorf("no repository matching %s", selector)
+	}
+
+	for _, repository := range repositories {
+		existingKeys, err := restClient.GetRepositoryAccessKeys(repository.Project.Key, repository.Slug)
+		if err != nil {
+			return err
+		}
+
+		for _, key := range keys {
+			fingerprint, err := key.Fingerprint()
+			if err != nil {
+				fmt.Printf("[FAIL] cannot read the fingerprint of key %s: %s\n", key.Label, err)
+				continue
+			}
+
+			if existing := helper.FindAccessKey(existingKeys, fingerprint); existing != nil {
+				if existing.Permission == permission {
+					fmt.Printf("[SKIP] key %s already present on %s/%s with %s\n", fingerprint, repository.Project.Key, repository.Slug, permission)
+					continue
+				}
+
+				_, err = restClient.UpdateRepositoryAccessKeyPermission(repository.Project.Key, repository.Slug, existing.Key.ID, permission)
+				if err != nil {
+					return fmt.Errorf("cannot update key %s on %s/%s: %w", fingerprint, repository.Project.Key, repository.Slug, err)
+				}
+
+				fmt.Printf(
+					"[OK] key %s updated on %s/%s from %s to %s\n",
+					fingerprint,
+					repository.Project.Key,
+					repository.Slug,
+					existing.Permission,
+					permission,
+				)
+				continue
+			}
+
+			_, err = restClient.AddRepositoryAccessKey(repository.Project.Key, repository.Slug, key, permission)
+			if err != nil {
+				return fmt.Errorf("cannot add key %s on %s/%s: %w", fingerprint, repository.Project.Key, repository.Slug, err)
+			}
+
+			fmt.Printf("[OK] key %s added on %s/%s with %s\n", fingerprint, repository.Project.Key, repository.Slug, permission)
+		}
+	}
+
+	return nil
+}
+
+// RemoveAccessKeyCommand define base struct for RemoveAccessKey actions
+type RemoveAccessKeyCommand struct {
+	Settings *settings.BitAdminSettings
+	flags    *RemoveAccessKeyCommandFlags
+}
+
+// RemoveAccessKeyCommandFlags hold flag values for the RemoveAccessKeyCommand
+type RemoveAccessKeyCommandFlags struct {
+	project     string
+	repository  string
+	fingerprint string
+	keyFile     string
+}
+
+// GetCommand provide a ready to use cli.Command
+func (command *RemoveAccessKeyCommand) GetCommand() cli.Command {
+	return cli.Command{
+		Name:   "remove",
+		Usage:  "Remove an access key from repositories",
+		Action: command.RemoveAccessKeyAction,
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:        "project",
+				Usage:       "The `<project_key>` of the repositories, shell patterns are allowed (ie: PRJ*)",
+				Destination: &command.flags.project,
+			},
+			cli.StringFlag{
+				Name:        "repository",
+				Usage:       "The `<repository_slug>` to remove the key from, shell patterns are allowed (ie: *-api)",
+				Destination: &command.flags.repository,
+			},
+			cli.StringFlag{
+				Name:        "fingerprint",
+				Usage:       "The `<fingerprint>` of the key to remove (SHA256 or MD5)",
+				Destination: &command.flags.fingerprint,
+			},
+			cli.StringFlag{
+				Name:        "key-file",
+				Usage:       "The `<file>` containing the public ssh key to remove",
+				Destination: &command.flags.keyFile,
+			},
+		},
+		BashComplete: func(c *cli.Context) {
+			helper.AutoComplete(c, command.Settings.GetFileCache())
+		},
+	}
+}
+
+// RemoveAccessKeyAction remove the key from every repository matching the project / repository flags
+func (command *RemoveAccessKeyCommand) RemoveAccessKeyAction(context *cli.Context) error {
+	if len(command.flags.project) <= 0 {
+		return errors.New("--project flag is required")
+	}
+	if len(command.flags.repository) <= 0 {
+		return errors.New("--repository flag is required")
+	}
+
+	fingerprint, err := helper.ResolveFingerprint(command.flags.fingerprint, command.flags.keyFile)
+	if err != nil {
+		return err
+	}
+
+	client, err := command.Settings.GetAPIClient()
+	if err != nil {
+		return err
+	}
+
+	restClient, err := command.Settings.GetRestClient()
+	if err != nil {
+		return err
+	}
+
+	selector := helper.RepositorySelector{Project: command.flags.project, Repository: command.flags.repository}
+	repositories, err := selector.Select(client)
+	if err != nil {
+		return err
+	}
+
+	removed := 0
+	for _, repository := range repositories {
+		keys, err := restClient.GetRepositoryAccessKeys(repository.Project.Key, repository.Slug)
+		if err != nil {
+			return err
+		}
+
+		key := helper.FindAccessKey(keys, fingerprint)
+		if key == nil {
+			continue
+		}
+
+		err = restClient.DeleteRepositoryAccessKey(repository.Project.Key, repository.Slug, key.Key.ID)
+		if err != nil {
+			return fmt.Errorf("cannot remove key %s from %s/%s: %w", fingerprint, repository.Project.Key, repository.Slug, err)
+		}
+
+		removed++
+		fmt.Printf("[OK] key %s removed from %s/%s\n", fingerprint, repository.Project.Key, repository.Slug)
+	}
+
+	if removed == 0 {
+		return fmt.Errorf("key %s is not installed on any repository matching %s", fingerprint, selector)
+	}
+
+	return nil
+}
+
+// FindAccessKeyCommand define base struct for FindAccessKey actions
+type FindAccessKeyCommand struct {
+	Settings *settings.BitAdminSettings
+	flags    *FindAccessKeyCommandFlags
+}
+
+// FindAccessKeyCommandFlags hold flag values for the FindAccessKeyCommand
+type FindAccessKeyCommandFlags struct {
+	project     string
+	repository  string
+	fingerprint string
+	keyFile     string
+	id          int
+}
+
+// GetCommand provide a ready to use cli.Command
+func (command *FindAccessKeyCommand) GetCommand() cli.Command {
+	return cli.Command{
+		Name:   "find",
+		Usage:  "Find every repository and project an access key is installed on",
+		Action: command.FindAccessKeyAction,
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:        "project",
+				Usage:       "Restrict the search to the `<project_key>`, shell patterns are allowed (ie: PRJ*)",
+				Destination: &command.flags.project,
+			},
+			cli.StringFlag{
+				Name:        "repository",
+				Usage:       "Restrict the search to the `<repository_slug>`, shell patterns are allowed (ie: *-api)",
+				Destination: &command.flags.repository,
+			},
+			cli.StringFlag{
+				Name:        "fingerprint",
+				Usage:       "The `<fingerprint>` of the key to find (SHA256 or MD5)",
+				Destination: &command.flags.fingerprint,
+			},
+			cli.StringFlag{
+				Name:        "key-file",
+				Usage:       "The `<file>` containing the public ssh key to find",
+				Destination: &command.flags.keyFile,
+			},
+			cli.IntFlag{
+				Name:        "id",
+				Usage:       "The `<id>` of the key to find, skip the lookup of the key by its fingerprint",
+				Destination: &command.flags.id,
+			},
+		},
+		BashComplete: func(c *cli.Context) {
+			helper.AutoComplete(c, command.Settings.GetFileCache())
+		},
+	}
+}
+
+// FindAccessKeyAction print the projects and repositories where the key is installed
+func (command *FindAccessKeyCommand) FindAccessKeyAction(context *cli.Context) error {
+	restClient, err := command.Settings.GetRestClient()
+	if err != nil {
+		return err
+	}
+
+	selector := helper.RepositorySelector{Project: command.flags.project, Repository: command.flags.repository}
+
+	keyID := command.flags.id
+	if keyID <= 0 {
+		keyID, err = command.findKeyID(restClient, selector)
+		if err != nil {
+			return err
+		}
+	}
+
+	projectKeys, err := restClient.GetAccessKeyProjects(keyID)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range projectKeys {
+		if selector.MatchProject(key.Project.Key) {
+			fmt.Printf("project %s [%s] %s\n", key.Project.Key, key.Permission, key.Key.Label)
+		}
+	}
+
+	repositoryKeys, err := restClient.GetAccessKeyRepositories(keyID)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range repositoryKeys {
+		if selector.Match(key.Repository) {
+			fmt.Printf("repository %s/%s [%s] %s\n", key.Repository.Project.Key, key.Repository.Slug, key.Permission, key.Key.Label)
+		}
+	}
+
+	return nil
+}
+
+// findKeyID look for the first project or repository of the selector holding the key, and return the key id.
+// Projects are searched first as they are far less numerous than repositories.
+func (command *FindAccessKeyCommand) findKeyID(restClient *helper.RestClient, selector helper.RepositorySelector) (int, error) {
+	fingerprint, err := helper.ResolveFingerprint(command.flags.fingerprint, command.flags.keyFile)
+	if err != nil {
+		return 0, err
+	}
+
+	client, err := command.Settings.GetAPIClient()
+	if err != nil {
+		return 0, err
+	}
+
+	projects, err := selector.SelectProjects(client)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, project := range projects {
+		keys, err := restClient.GetProjectAccessKeys(project)
+		if err != nil {
+			return 0, err
+		}
+
+		if key := helper.FindAccessKey(keys, fingerprint); key != nil {
+			return key.Key.ID, nil
+		}
+	}
+
+	repositories, err := selector.Select(client)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, repository := range repositories {
+		keys, err := restClient.GetRepositoryAccessKeys(repository.Project.Key, repository.Slug)
+		if err != nil {
+			return 0, err
+		}
+
+		if key := helper.FindAccessKey(keys, fingerprint); key != nil {
+			return key.Key.ID, nil
+		}
+	}
+
+	return 0, fmt.Errorf("no access key matching %s found on %s", fingerprint, selector)
+}
@@ -4,6 +4,8 @@ package repository
 import (
 	"errors"
 	"fmt"
+	"os"
+
 	"github.com/daeMOn63/bitadmin/helper"
 	"github.com/daeMOn63/bitadmin/settings"
 	"github.com/urfave/cli"
@@ -12,17 +14,126 @@ import (
 // BranchingModelCommand define base struct for BranchingModel actions
 type BranchingModelCommand struct {
 	Settings *settings.BitAdminSettings
-	flags    *BranchingModelCommandFlags
 }
 
-// BranchingModelCommandFlags define flags required by the ShowPermissionsAction
-type BranchingModelCommandFlags struct {
+// GetCommand provide a ready to use cli.Command
+func (command *BranchingModelCommand) GetCommand() cli.Command {
+
+	showCommand := &BranchingModelShowCommand{
+		Settings: command.Settings,
+		flags:    &BranchingModelShowCommandFlags{},
+	}
+
+	setCommand := &BranchingModelSetCommand{
+		Settings: command.Settings,
+		flags:    &BranchingModelSetCommandFlags{},
+	}
+
+	return cli.Command{
+		Name:  "branching-model",
+		Usage: "Branching model operations",
+		Subcommands: []cli.Command{
+			showCommand.GetCommand(),
+			setCommand.GetCommand(),
+		},
+	}
+}
+
+// BranchingModelShowCommand define the command printing the branching model of a repository
+type BranchingModelShowCommand struct {
+	Settings *settings.BitAdminSettings
+	flags    *BranchingModelShowCommandFlags
+}
+
+// BranchingModelShowCommandFlags define flags required by the ShowBranchingModelAction
+type BranchingModelShowCommandFlags struct {
+	project    string
+	repository string
+}
+
+// GetCommand provide a ready to use cli.Command
+func (command *BranchingModelShowCommand) GetCommand() cli.Command {
+	return cli.Command{
+		Name:   "show",
+		Usage:  "Show branching model options of given repository",
+		Action: command.ShowBranchingModelAction,
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:        "project",
+				Usage:       "The `<project>` of the repository",
+				Destination: &command.flags.project,
+			},
+			cli.StringFlag{
+				Name:        "repository",
+				Usage:       "The `<repository>` to show the branching model of",
+				Destination: &command.flags.repository,
+			},
+		},
+		BashComplete: func(c *cli.Context) {
+			helper.AutoComplete(c, command.Settings.GetFileCache())
+		},
+	}
+}
+
+// ShowBranchingModelAction print the branching model options of given repository
+func (command *BranchingModelShowCommand) ShowBranchingModelAction(context *cli.Context) error {
+
+	if len(command.flags.project) <= 0 {
+		return errors.New("--project flag is required")
+	}
+	if len(command.flags.repository) <= 0 {
+		return errors.New("--repository flag is required")
+	}
+
+	client, err := command.Settings.GetAPIClient()
+	if err != nil {
+		return err
+	}
+
+	branchingModel, err := client.GetBranchingModel(
+		command.flags.project,
+		command.flags.repository,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Branching model of %s/%s\n", command.flags.project, command.flags.repository)
+	fmt.Printf("development: %s\n", branchingModel.Development.RefId)
+	fmt.Printf("production: %s\n", branchingModel.Production.RefId)
+
+	for _, t := range branchingModel.Types {
+		status := "DISABLED"
+		if t.Enabled == true {
+			status = "ENABLED "
+		}
+
+		fmt.Printf("[%s] %s (%s)\n", status, t.Id, t.Prefix)
+	}
+
+	return nil
+}
+
+// BranchingModelSetCommand define the command updating the branching model of a repository
+type BranchingModelSetCommand struct {
+	Settings *settings.BitAdminSettings
+	flags    *BranchingModelSetCommandFlags
+}
+
+// BranchingModelSetCommandFlags define flags required by the SetBranchingModelAction
+type BranchingModelSetCommandFlags struct {
 	project          string
 	repository       string
+	inherit          bool
 	enableBugfix     bool
 	enableFeature    bool
 	enableHotfix     bool
 	enableRelease    bool
+	disableBugfix    bool
+	disableFeature   bool
+	disableHotfix    bool
+	disableRelease   bool
 	prefixBugfix     string
 	prefixFeature    string
 	prefixHotfix     string
@@ -32,22 +143,27 @@ type BranchingModelCommandFlags struct {
 }
 
 // GetCommand provide a ready to use cli.Command
-func (command *BranchingModelCommand) GetCommand() cli.Command {
+func (command *BranchingModelSetCommand) GetCommand() cli.Command {
 	return cli.Command{
-		Name:   "set-branching-model",
-		Usage:  "Set branching model options on given repository",
+		Name:   "set",
+		Usage:  "Set branching model options on given repository. Options not provided are left unchanged",
 		Action: command.SetBranchingModelAction,
 		Flags: []cli.Flag{
 			cli.StringFlag{
 				Name:        "project",
-				Usage:       "The `<rproject>` of the repository",
+				Usage:       "The `<project>` of the repository",
 				Destination: &command.flags.project,
 			},
 			cli.StringFlag{
 				Name:        "repository",
-				Usage:       "The `<repository>` which to dump permissions from",
+				Usage:       "The `<repository>` to set the branching model on",
 				Destination: &command.flags.repository,
 			},
+			cli.BoolFlag{
+				Name:        "inherit",
+				Usage:       "Drop the repository branching model and use the one inherited from the project",
+				Destination: &command.flags.inherit,
+			},
 			cli.BoolFlag{
 				Name:        "enable-bugfix",
 				Usage:       "Turn on the bugfix branch model",
@@ -68,6 +184,26 @@ func (command *BranchingModelCommand) GetCommand() cli.Command {
 				Usage:       "Turn on the release branch model",
 				Destination: &command.flags.enableRelease,
 			},
+			cli.BoolFlag{
+				Name:        "disable-bugfix",
+				Usage:       "Turn off the bugfix branch model",
+				Destination: &command.flags.disableBugfix,
+			},
+			cli.BoolFlag{
+				Name:        "disable-feature",
+				Usage:       "Turn off the feature branch model",
+				Destination: &command.flags.disableFeature,
+			},
+			cli.BoolFlag{
+				Name:        "disable-hotfix",
+				Usage:       "Turn off the hotfix branch model",
+				Destination: &command.flags.disableHotfix,
+			},
+			cli.BoolFlag{
+				Name:        "disable-release",
+				Usage:       "Turn off the release branch model",
+				Destination: &command.flags.disableRelease,
+			},
 			cli.StringFlag{
 				Name:        "prefix-bugfix",
 				Usage:       "Override default `<prefix>` for bugfix branch model",
@@ -105,8 +241,35 @@ func (command *BranchingModelCommand) GetCommand() cli.Command {
 	}
 }
 
+// GetDeprecatedCommand provide the command under its former set-branching-model name, kept so existing scripts
+// keep working
+func (command *BranchingModelSetCommand) GetDeprecatedCommand() cli.Command {
+	deprecated := command.GetCommand()
+	deprecated.Name = "set-branching-model"
+	deprecated.Usage = "Deprecated, use branching-model set"
+	deprecated.Hidden = true
+	deprecated.Action = func(context *cli.Context) error {
+		fmt.Fprintln(os.Stderr, "[WARN] set-branching-model is deprecated, use branching-model set")
+		return command.SetBranchingModelAction(context)
+	}
+
+	return deprecated
+}
+
+// branchTypeChange hold the requested changes for a single branch type
+type branchTypeChange struct {
+	enable  bool
+	disable bool
+	prefix  string
+}
+
+// requested tell if any change is asked for the branch type
+func (change branchTypeChange) requested() bool {
+	return change.enable || change.disable || len(change.prefix) > 0
+}
+
 // SetBranchingModelAction use flag values to set the branching model options on given repository
-func (command *BranchingModelCommand) SetBranchingModelAction(context *cli.Context) error {
+func (command *BranchingModelSetCommand) SetBranchingModelAction(context *cli.Context) error {
 
 	if len(command.flags.project) <= 0 {
 		return errors.New("--project flag is required")
@@ -115,6 +278,45 @@ func (command *BranchingModelCommand) SetBranchingModelAction(context *cli.Conte
 		return errors.New("--repository flag is required")
 	}
 
+	changes := map[string]branchTypeChange{
+		"BUGFIX":  {command.flags.enableBugfix, command.flags.disableBugfix, command.flags.prefixBugfix},
+		"FEATURE": {command.flags.enableFeature, command.flags.disableFeature, command.flags.prefixFeature},
+		"HOTFIX":  {command.flags.enableHotfix, command.flags.disableHotfix, command.flags.prefixHotfix},
+		"RELEASE": {command.flags.enableRelease, command.flags.disableRelease, command.flags.prefixRelease},
+	}
+
+	for id, change := range changes {
+		if change.enable && change.disable {
+			return fmt.Errorf("cannot both enable and disable the %s branch model", id)
+		}
+	}
+
+	if command.flags.inherit {
+		changed := len(command.flags.productionRefID) > 0 || len(command.flags.developmentRefID) > 0
+		for _, change := range changes {
+			changed = changed || change.requested()
+		}
+
+		if changed {
+			return errors.New("--inherit cannot be used with other branching model flags")
+		}
+
+		return command.inheritBranchingModel()
+	}
+
+	restClient, err := command.Settings.GetRestClient()
+	if err != nil {
+		return err
+	}
+
+	for _, refID := range []string{command.flags.productionRefID, command.flags.developmentRefID} {
+		if len(refID) > 0 {
+			if _, err := restClient.FindBranch(command.flags.project, command.flags.repository, refID); err != nil {
+				return err
+			}
+		}
+	}
+
 	client, err := command.Settings.GetAPIClient()
 	if err != nil {
 		return err
@@ -129,38 +331,36 @@ func (command *BranchingModelCommand) SetBranchingModelAction(context *cli.Conte
 		return err
 	}
 
-	if len(command.flags.productionRefID) >= 0 {
+	if len(command.flags.productionRefID) > 0 {
 		branchingModel.Production.RefId = command.flags.productionRefID
 	}
-	if len(command.flags.developmentRefID) >= 0 {
+	if len(command.flags.developmentRefID) > 0 {
 		branchingModel.Development.RefId = command.flags.developmentRefID
 	}
 
 	for i, t := range branchingModel.Types {
-		switch t.Id {
-		case "BUGFIX":
-			branchingModel.Types[i].Enabled = command.flags.enableBugfix
-			if len(command.flags.prefixBugfix) > 0 {
-				branchingModel.Types[i].Prefix = command.flags.prefixBugfix
-			}
-		case "FEATURE":
-			branchingModel.Types[i].Enabled = command.flags.enableFeature
-			if len(command.flags.prefixFeature) > 0 {
-				branchingModel.Types[i].Prefix = command.flags.prefixFeature
-			}
-		case "HOTFIX":
-			branchingModel.Types[i].Enabled = command.flags.enableHotfix
-			if len(command.flags.prefixHotfix) > 0 {
-				branchingModel.Types[i].Prefix = command.flags.prefixHotfix
-			}
-		case "RELEASE":
-			branchingModel.Types[i].Enabled = command.flags.enableRelease
-			if len(command.flags.prefixRelease) > 0 {
-				branchingModel.Types[i].Prefix = command.flags.prefixRelease
-			}
-		default:
+		change, ok := changes[t.Id]
+		if !ok {
 			return fmt.Errorf("unsupported branching model type %s", t.Id)
 		}
+		delete(changes, t.Id)
+
+		if change.enable {
+			branchingModel.Types[i].Enabled = true
+		}
+		if change.disable {
+			branchingModel.Types[i].Enabled = false
+		}
+		if len(change.prefix) > 0 {
+			branchingModel.Types[i].Prefix = change.prefix
+		}
+	}
+
+	// The changes left target branch types the model does not define, they cannot be applied
+	for id, change := range changes {
+		if change.requested() {
+			return fmt.Errorf("the branching model of %s/%s has no %s branch type", command.flags.project, command.flags.repository, id)
+		}
 	}
 
 	err = client.SetBranchingModel(
@@ -177,3 +377,25 @@ func (command *BranchingModelCommand) SetBranchingModelAction(context *cli.Conte
 
 	return nil
 }
+
+// inheritBranchingModel remove the repository own branching model, making it use the project one
+func (command *BranchingModelSetCommand) inheritBranchingModel() error {
+	restClient, err := command.Settings.GetRestClient()
+	if err != nil {
+		return err
+	}
+
+	err = restClient.Delete(fmt.Sprintf(
+		"branch-utils/1.0/projects/%s/repos/%s/branchmodel/configuration",
+		command.flags.project,
+		command.flags.repository,
+	))
+
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("[OK] repository %s/%s now use the project branching model\n", command.flags.project, command.flags.repository)
+
+	return nil
+}
@@ -3,6 +3,8 @@ package repository
 
 import (
 	"fmt"
+	"os"
+
 	"github.com/daeMOn63/bitadmin/helper"
 	"github.com/daeMOn63/bitadmin/settings"
 	"github.com/daeMOn63/bitclient"
@@ -104,8 +106,17 @@ func (command *CreateCommand) CreateRepositoryAction(context *cli.Context) error
 	helper.PrintLinks(resp.Links)
 	fmt.Println()
 
-	fileCache := command.Settings.GetFileCache()
-	fileCache.Repositories = append(fileCache.Repositories, resp)
-	fileCache.Save()
+	// The repository is created, a cache failure must not make the command fail
+	fileCache, err := command.Settings.LoadFileCache()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[WARN] cannot update the cache: %s\n", err)
+		return nil
+	}
+
+	fileCache.ReplaceRepository(resp.Project.Key, resp.Slug, resp)
+	if err := fileCache.Save(); err != nil {
+		fmt.Fprintf(os.Stderr, "[WARN] cannot update the cache: %s\n", err)
+	}
+
 	return nil
 }
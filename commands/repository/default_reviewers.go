@@ -4,6 +4,8 @@ package repository
 import (
 	"errors"
 	"fmt"
+	"os"
+	"strings"
 
 	"github.com/daeMOn63/bitadmin/helper"
 	"github.com/daeMOn63/bitadmin/settings"
@@ -11,6 +13,189 @@ import (
 	"github.com/urfave/cli"
 )
 
+// DefaultReviewersCommand define base struct for DefaultReviewers actions
+type DefaultReviewersCommand struct {
+	Settings *settings.BitAdminSettings
+}
+
+// GetCommand provide a ready to use cli.Command
+func (command *DefaultReviewersCommand) GetCommand() cli.Command {
+
+	listCommand := &ListDefaultReviewersCommand{
+		Settings: command.Settings,
+		flags:    &ListDefaultReviewersCommandFlags{},
+	}
+
+	setCommand := &SetDefaultReviewersCommand{
+		Settings: command.Settings,
+		flags:    &SetDefaultReviewersCommandFlags{},
+	}
+
+	removeCommand := &RemoveDefaultReviewersCommand{
+		Settings: command.Settings,
+		flags:    &RemoveDefaultReviewersCommandFlags{},
+	}
+
+	deleteConditionCommand := &DeleteDefaultReviewersConditionCommand{
+		Settings: command.Settings,
+		flags:    &DeleteDefaultReviewersConditionCommandFlags{},
+	}
+
+	return cli.Command{
+		Name:  "default-reviewers",
+		Usage: "Default reviewers operations",
+		Subcommands: []cli.Command{
+			listCommand.GetCommand(),
+			setCommand.GetCommand(),
+			removeCommand.GetCommand(),
+			deleteConditionCommand.GetCommand(),
+		},
+	}
+}
+
+// matcherFlags hold the flag values selecting the source and target refs of a default reviewers condition
+type matcherFlags struct {
+	sourceMatcherType string
+	sourceMatcher     string
+	targetMatcherType string
+	targetMatcher     string
+	branchRef         string
+}
+
+// getFlags provide the matcher related flags
+func (flags *matcherFlags) getFlags() []cli.Flag {
+	return []cli.Flag{
+		cli.StringFlag{
+			Name:        "sourceMatcherType",
+			Value:       helper.MatcherTypeAnyRef,
+			Usage:       "The `<type>` of the source ref matcher (one of ANY_REF, BRANCH, PATTERN, MODEL_CATEGORY, MODEL_BRANCH)",
+			Destination: &flags.sourceMatcherType,
+		},
+		cli.StringFlag{
+			Name:        "sourceMatcher",
+			Usage:       "The source ref matcher `<value>` (ie: refs/heads/feature, feature/*, FEATURE or development)",
+			Destination: &flags.sourceMatcher,
+		},
+		cli.StringFlag{
+			Name:        "targetMatcherType",
+			Value:       helper.MatcherTypeBranch,
+			Usage:       "The `<type>` of the target ref matcher (one of ANY_REF, BRANCH, PATTERN, MODEL_CATEGORY, MODEL_BRANCH)",
+			Destination: &flags.targetMatcherType,
+		},
+		cli.StringFlag{
+			Name:        "targetMatcher",
+			Usage:       "The target ref matcher `<value>` (ie: refs/heads/master, release/*, RELEASE or production)",
+			Destination: &flags.targetMatcher,
+		},
+		cli.StringFlag{
+			Name:        "branchRef",
+			Usage:       "Shortcut for a BRANCH target matcher on `<branchRef>` (ie: refs/heads/master)",
+			Destination: &flags.branchRef,
+		},
+	}
+}
+
+// getMatchers build the source and target matchers from the flag values
+func (flags *matcherFlags) getMatchers() (bitclient.Matcher, bitclient.Matcher, error) {
+	targetMatcherType := flags.targetMatcherType
+	targetMatcherValue := flags.targetMatcher
+
+	if len(flags.branchRef) > 0 {
+		if len(flags.targetMatcher) > 0 {
+			return bitclient.Matcher{}, bitclient.Matcher{}, errors.New("--branchRef and --targetMatcher cannot be used together")
+		}
+
+		targetMatcherType = helper.MatcherTypeBranch
+		targetMatcherValue = flags.branchRef
+	}
+
+	source, err := helper.NewRefMatcher(flags.sourceMatcherType, flags.sourceMatcher)
+	if err != nil {
+		return bitclient.Matcher{}, bitclient.Matcher{}, fmt.Errorf("invalid source matcher: %s", err)
+	}
+
+	target, err := helper.NewRefMatcher(targetMatcherType, targetMatcherValue)
+	if err != nil {
+		return bitclient.Matcher{}, bitclient.Matcher{}, fmt.Errorf("invalid target matcher: %s", err)
+	}
+
+	return source, target, nil
+}
+
+// ListDefaultReviewersCommand define base struct for ListDefaultReviewers actions
+type ListDefaultReviewersCommand struct {
+	Settings *settings.BitAdminSettings
+	flags    *ListDefaultReviewersCommandFlags
+}
+
+// ListDefaultReviewersCommandFlags hold flag values for the ListDefaultReviewersCommand
+type ListDefaultReviewersCommandFlags struct {
+	project    string
+	repository string
+}
+
+// GetCommand provide a ready to use cli.Command
+func (command *ListDefaultReviewersCommand) GetCommand() cli.Command {
+	return cli.Command{
+		Name:   "list",
+		Usage:  "List default reviewers conditions of given repository",
+		Action: command.ListDefaultReviewersAction,
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:        "project",
+				Usage:       "The `<project_key>` of the repository",
+				Destination: &command.flags.project,
+			},
+			cli.StringFlag{
+				Name:        "repository",
+				Usage:       "The `<repository_slug>` to list default reviewers from",
+				Destination: &command.flags.repository,
+			},
+		},
+		BashComplete: func(c *cli.Context) {
+			helper.AutoComplete(c, command.Settings.GetFileCache())
+		},
+	}
+}
+
+// ListDefaultReviewersAction print the default reviewers conditions of given repository
+func (command *ListDefaultReviewersCommand) ListDefaultReviewersAction(context *cli.Context) error {
+	if len(command.flags.project) <= 0 {
+		return errors.New("--project flag is required")
+	}
+	if len(command.flags.repository) <= 0 {
+		return errors.New("--repository flag is required")
+	}
+
+	client, err := command.Settings.GetAPIClient()
+	if err != nil {
+		return err
+	}
+
+	conditions, err := client.GetRepositoryDefaultReviewers(command.flags.project, command.flags.repository)
+	if err != nil {
+		return err
+	}
+
+	for _, condition := range conditions {
+		var slugs []string
+		for _, reviewer := range condition.Reviewers {
+			slugs = append(slugs, reviewer.Slug)
+		}
+
+		fmt.Printf(
+			"#%d %s -> %s, %d required approvals: %s\n",
+			condition.Id,
+			helper.FormatRefMatcher(condition.FromRefMatcher),
+			helper.FormatRefMatcher(condition.ToRefMatcher),
+			condition.RequiredApprovals,
+			strings.Join(slugs, ", "),
+		)
+	}
+
+	return nil
+}
+
 // SetDefaultReviewersCommand define base struct for SetDefaultReviewer actions
 type SetDefaultReviewersCommand struct {
 	Settings *settings.BitAdminSettings
@@ -19,52 +204,55 @@ type SetDefaultReviewersCommand struct {
 
 // SetDefaultReviewersCommandFlags hold flag values for the SetDefaultReviewerCommand
 type SetDefaultReviewersCommandFlags struct {
+	matcherFlags
 	project           string
 	repository        string
 	usernames         cli.StringSlice
-	branchRef         string
+	groups            cli.StringSlice
 	requiredApprovers uint
 	replace           bool
 }
 
 // GetCommand provide a ready to use cli.Command
 func (command *SetDefaultReviewersCommand) GetCommand() cli.Command {
+	flags := []cli.Flag{
+		cli.StringFlag{
+			Name:        "project",
+			Usage:       "The `<project_key>` of the repository",
+			Destination: &command.flags.project,
+		},
+		cli.StringFlag{
+			Name:        "repository",
+			Usage:       "The `<repository_slug>` to set default reviewers on",
+			Destination: &command.flags.repository,
+		},
+		cli.StringSliceFlag{
+			Name:  "username",
+			Usage: "The `<username>`, email address or display name of a user to be added on the repository. Can be repeated multiple times",
+			Value: &command.flags.usernames,
+		},
+		cli.StringSliceFlag{
+			Name:  "group",
+			Usage: "The `<group>` whose current members will be added on the repository. Bitbucket only store users, so later membership changes are not applied until set is run again. Can be repeated multiple times",
+			Value: &command.flags.groups,
+		},
+		cli.UintFlag{
+			Name:        "requiredApprovers",
+			Usage:       "`<requiredApprovers>` set the minimum number of approval required by default reviewers",
+			Destination: &command.flags.requiredApprovers,
+		},
+		cli.BoolFlag{
+			Name:        "replace",
+			Usage:       "Setting this flag will replace existing default reviewers by provided ones.",
+			Destination: &command.flags.replace,
+		},
+	}
+
 	return cli.Command{
-		Name:   "set-default-reviewers",
+		Name:   "set",
 		Usage:  "Set default reviewers on given repository",
 		Action: command.SetDefaultReviewersAction,
-		Flags: []cli.Flag{
-			cli.StringFlag{
-				Name:        "project",
-				Usage:       "The `<project_key>` where the repository will be created",
-				Destination: &command.flags.project,
-			},
-			cli.StringFlag{
-				Name:        "repository",
-				Usage:       "The `<repository_name>` to create",
-				Destination: &command.flags.repository,
-			},
-			cli.StringSliceFlag{
-				Name:  "username",
-				Usage: "The `<username>` to be added on the repository. Can be repeated multiple times",
-				Value: &command.flags.usernames,
-			},
-			cli.UintFlag{
-				Name:        "requiredApprovers",
-				Usage:       "`<requiredApprovers>` set the minimum number of approval required by default reviewers",
-				Destination: &command.flags.requiredApprovers,
-			},
-			cli.StringFlag{
-				Name:        "branchRef",
-				Usage:       "`<branchRef>` define the pull request's target branch where default reviewers will be set (ie: refs/heads/master)",
-				Destination: &command.flags.branchRef,
-			},
-			cli.BoolFlag{
-				Name:        "replace",
-				Usage:       "Setting this flag will replace existing default reviewers by provided ones.",
-				Destination: &command.flags.replace,
-			},
-		},
+		Flags:  append(flags, command.flags.matcherFlags.getFlags()...),
 		BashComplete: func(c *cli.Context) {
 			helper.AutoComplete(c, command.Settings.GetFileCache())
 		},
@@ -73,101 +261,478 @@ func (command *SetDefaultReviewersCommand) GetCommand() cli.Command {
 
 // SetDefaultReviewersAction allow to set the default reviewers on given repository.
 func (command *SetDefaultReviewersCommand) SetDefaultReviewersAction(context *cli.Context) error {
+	if len(command.flags.repository) <= 0 {
+		return errors.New("--repository flag is required")
+	}
+	if len(command.flags.project) <= 0 {
+		return errors.New("--project flag is required")
+	}
+	if len(command.flags.usernames) == 0 && len(command.flags.groups) == 0 {
+		return fmt.Errorf("At least one --username or --group is required")
+	}
+
+	sourceMatcher, targetMatcher, err := command.flags.getMatchers()
+	if err != nil {
+		return err
+	}
+
 	client, err := command.Settings.GetAPIClient()
 	if err != nil {
 		return err
 	}
 
-	cache := command.Settings.GetFileCache()
+	restClient, err := command.Settings.GetRestClient()
+	if err != nil {
+		return err
+	}
+
+	cache, err := command.Settings.LoadFileCache()
+	if err != nil {
+		return err
+	}
+
+	users, err := resolveReviewers(
+		cache,
+		restClient,
+		command.flags.usernames,
+		command.flags.groups,
+	)
+	if err != nil {
+		return err
+	}
+
+	if len(command.flags.groups) > 0 {
+		fmt.Fprintf(
+			os.Stderr,
+			"[WARN] %s expanded to their current members, run set again to apply later membership changes\n",
+			strings.Join(command.flags.groups, ", "),
+		)
+	}
+
+	conditions, err := client.GetRepositoryDefaultReviewers(command.flags.project, command.flags.repository)
+	if err != nil {
+		return err
+	}
+
+	// Users can be provided twice, either directly or through groups
+	reviewers := mergeReviewers(nil, users)
+
+	updated := 0
+	for _, condition := range conditions {
+		if !helper.RefMatchersEqual(condition.FromRefMatcher, sourceMatcher) ||
+			!helper.RefMatchersEqual(condition.ToRefMatcher, targetMatcher) {
+			continue
+		}
+
+		if command.flags.replace {
+			condition.Reviewers = reviewers
+		} else {
+			condition.Reviewers = mergeReviewers(condition.Reviewers, reviewers)
+		}
+
+		if context.IsSet("requiredApprovers") {
+			condition.RequiredApprovals = int(command.flags.requiredApprovers)
+		}
+
+		_, err := client.UpdateRepositoryDefaultReviewers(command.flags.project, command.flags.repository, condition)
+		if err != nil {
+			return fmt.Errorf("cannot update condition #%d: %w", condition.Id, err)
+		}
+
+		fmt.Printf(
+			"[OK] Updated condition #%d with %d default reviewers on %s -> %s for %s/%s\n",
+			condition.Id,
+			len(condition.Reviewers),
+			helper.FormatRefMatcher(sourceMatcher),
+			helper.FormatRefMatcher(targetMatcher),
+			command.flags.project,
+			command.flags.repository,
+		)
+		updated++
+	}
+
+	if updated > 0 {
+		return nil
+	}
+
+	// No default reviewers exists for given matchers, create it
+	condition, err := client.CreateRepositoryDefaultReviewers(
+		command.flags.project,
+		command.flags.repository,
+		bitclient.DefaultReviewers{
+			FromRefMatcher:    sourceMatcher,
+			ToRefMatcher:      targetMatcher,
+			Reviewers:         reviewers,
+			RequiredApprovals: int(command.flags.requiredApprovers),
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf(
+		"[OK] Created condition #%d with %d default reviewers on %s -> %s for %s/%s\n",
+		condition.Id,
+		len(reviewers),
+		helper.FormatRefMatcher(sourceMatcher),
+		helper.FormatRefMatcher(targetMatcher),
+		command.flags.project,
+		command.flags.repository,
+	)
+
+	return nil
+}
+
+// GetDeprecatedCommand provide the command under its former set-default-reviewers name, kept so existing scripts
+// keep working
+func (command *SetDefaultReviewersCommand) GetDeprecatedCommand() cli.Command {
+	deprecated := command.GetCommand()
+	deprecated.Name = "set-default-reviewers"
+	deprecated.Usage = "Deprecated, use default-reviewers set"
+	deprecated.Hidden = true
+	deprecated.Action = func(context *cli.Context) error {
+		fmt.Fprintln(os.Stderr, "[WARN] set-default-reviewers is deprecated, use default-reviewers set")
+		return command.SetDefaultReviewersAction(context)
+	}
+
+	return deprecated
+}
+
+// RemoveDefaultReviewersCommand define base struct for RemoveDefaultReviewers actions
+type RemoveDefaultReviewersCommand struct {
+	Settings *settings.BitAdminSettings
+	flags    *RemoveDefaultReviewersCommandFlags
+}
+
+// RemoveDefaultReviewersCommandFlags hold flag values for the RemoveDefaultReviewersCommand
+type RemoveDefaultReviewersCommandFlags struct {
+	matcherFlags
+	project    string
+	repository string
+	usernames  cli.StringSlice
+	groups     cli.StringSlice
+}
+
+// GetCommand provide a ready to use cli.Command
+func (command *RemoveDefaultReviewersCommand) GetCommand() cli.Command {
+	flags := []cli.Flag{
+		cli.StringFlag{
+			Name:        "project",
+			Usage:       "The `<project_key>` of the repository",
+			Destination: &command.flags.project,
+		},
+		cli.StringFlag{
+			Name:        "repository",
+			Usage:       "The `<repository_slug>` to remove default reviewers from",
+			Destination: &command.flags.repository,
+		},
+		cli.StringSliceFlag{
+			Name:  "username",
+			Usage: "The `<username>`, email address or display name of a user to be removed from the default reviewers. Can be repeated multiple times",
+			Value: &command.flags.usernames,
+		},
+		cli.StringSliceFlag{
+			Name:  "group",
+			Usage: "The `<group>` whose members will be removed from the default reviewers. Can be repeated multiple times",
+			Value: &command.flags.groups,
+		},
+	}
+
+	return cli.Command{
+		Name:   "remove",
+		Usage:  "Remove users from the default reviewers of given repository",
+		Action: command.RemoveDefaultReviewersAction,
+		Flags:  append(flags, command.flags.matcherFlags.getFlags()...),
+		BashComplete: func(c *cli.Context) {
+			helper.AutoComplete(c, command.Settings.GetFileCache())
+		},
+	}
+}
 
+// RemoveDefaultReviewersAction remove the given users from the condition matching the matcher flags.
+func (command *RemoveDefaultReviewersCommand) RemoveDefaultReviewersAction(context *cli.Context) error {
 	if len(command.flags.repository) <= 0 {
 		return errors.New("--repository flag is required")
 	}
 	if len(command.flags.project) <= 0 {
 		return errors.New("--project flag is required")
 	}
-	if len(command.flags.branchRef) <= 0 {
-		return errors.New("--branchRef flag is required")
+	if len(command.flags.usernames) == 0 && len(command.flags.groups) == 0 {
+		return fmt.Errorf("At least one --username or --group is required")
+	}
+
+	sourceMatcher, targetMatcher, err := command.flags.getMatchers()
+	if err != nil {
+		return err
 	}
-	if len(command.flags.usernames) == 0 {
-		return fmt.Errorf("At least one --username is required")
+
+	restClient, err := command.Settings.GetRestClient()
+	if err != nil {
+		return err
 	}
 
-	repo, err := cache.FindRepository(command.flags.project, command.flags.repository)
+	cache, err := command.Settings.LoadFileCache()
 	if err != nil {
 		return err
 	}
 
-	var users []bitclient.User
+	users, err := resolveReviewers(
+		cache,
+		restClient,
+		command.flags.usernames,
+		command.flags.groups,
+	)
+	if err != nil {
+		return err
+	}
 
-	for _, username := range command.flags.usernames {
+	client, err := command.Settings.GetAPIClient()
+	if err != nil {
+		return err
+	}
 
-		user, err := cache.FindUserByUsername(username)
-		if err != nil {
-			return err
+	conditions, err := client.GetRepositoryDefaultReviewers(command.flags.project, command.flags.repository)
+	if err != nil {
+		return err
+	}
+
+	var matching []bitclient.DefaultReviewers
+	for _, condition := range conditions {
+		if helper.RefMatchersEqual(condition.FromRefMatcher, sourceMatcher) &&
+			helper.RefMatchersEqual(condition.ToRefMatcher, targetMatcher) {
+			matching = append(matching, condition)
 		}
+	}
 
-		users = append(users, user)
+	if len(matching) == 0 {
+		return fmt.Errorf(
+			"cannot find any default reviewers condition %s -> %s on %s/%s",
+			helper.FormatRefMatcher(sourceMatcher),
+			helper.FormatRefMatcher(targetMatcher),
+			command.flags.project,
+			command.flags.repository,
+		)
 	}
 
-	settings, err := client.GetRepositoryDefaultReviewers(command.flags.project, command.flags.repository)
+	// Every condition is checked first, so none is updated when one would be left without reviewers
+	kept := make([][]bitclient.User, len(matching))
+	for i, condition := range matching {
+		for _, reviewer := range condition.Reviewers {
+			removed := false
+			for _, user := range users {
+				if reviewer.Slug == user.Slug {
+					removed = true
+					break
+				}
+			}
 
-	exists := false
+			if removed == false {
+				kept[i] = append(kept[i], reviewer)
+			}
+		}
 
-	for _, setting := range settings {
-		if setting.ToRefMatcher.Id == command.flags.branchRef {
+		if len(kept[i]) == 0 {
+			return fmt.Errorf(
+				"removing those users would leave condition #%d without reviewers, use delete-condition instead",
+				condition.Id,
+			)
+		}
+	}
 
-			if command.flags.replace == false {
+	removedTotal := 0
+	for i, condition := range matching {
+		removedTotal += len(condition.Reviewers) - len(kept[i])
+	}
 
-				for _, user := range users {
-					found := false
-					for _, revUser := range setting.Reviewers {
-						if revUser.Slug == user.Slug {
-							found = true
-							break
-						}
-					}
+	if removedTotal == 0 {
+		return fmt.Errorf(
+			"none of those users is a default reviewer of condition %s -> %s on %s/%s",
+			helper.FormatRefMatcher(sourceMatcher),
+			helper.FormatRefMatcher(targetMatcher),
+			command.flags.project,
+			command.flags.repository,
+		)
+	}
 
-					if found == false {
-						setting.Reviewers = append(setting.Reviewers, user)
-					}
-				}
-			} else {
-				setting.Reviewers = users
-			}
+	for i, condition := range matching {
+		removedCount := len(condition.Reviewers) - len(kept[i])
+		if removedCount == 0 {
+			fmt.Printf("[SKIP] none of those users is a default reviewer of condition #%d\n", condition.Id)
+			continue
+		}
+
+		condition.Reviewers = kept[i]
 
-			client.UpdateRepositoryDefaultReviewers(command.flags.project, command.flags.repository, setting)
-			exists = true
-			break
+		_, err := client.UpdateRepositoryDefaultReviewers(command.flags.project, command.flags.repository, condition)
+		if err != nil {
+			return fmt.Errorf("cannot update condition #%d: %w", condition.Id, err)
 		}
+
+		fmt.Printf(
+			"[OK] Removed %d default reviewers from condition #%d on %s/%s\n",
+			removedCount,
+			condition.Id,
+			command.flags.project,
+			command.flags.repository,
+		)
 	}
 
-	// No default reviewers exists for given branch, create it
-	if exists == false {
-		setting := bitclient.DefaultReviewers{
-			Repository: repo,
-			FromRefMatcher: bitclient.Matcher{
-				Id:   "ANY_REF_MATCHER_ID",
-				Type: bitclient.MatcherType{Id: "ANY_REF"},
+	return nil
+}
+
+// DeleteDefaultReviewersConditionCommand define base struct for DeleteDefaultReviewersCondition actions
+type DeleteDefaultReviewersConditionCommand struct {
+	Settings *settings.BitAdminSettings
+	flags    *DeleteDefaultReviewersConditionCommandFlags
+}
+
+// DeleteDefaultReviewersConditionCommandFlags hold flag values for the DeleteDefaultReviewersConditionCommand
+type DeleteDefaultReviewersConditionCommandFlags struct {
+	project    string
+	repository string
+	id         int
+}
+
+// GetCommand provide a ready to use cli.Command
+func (command *DeleteDefaultReviewersConditionCommand) GetCommand() cli.Command {
+	return cli.Command{
+		Name:   "delete-condition",
+		Usage:  "Delete a default reviewers condition from given repository",
+		Action: command.DeleteDefaultReviewersConditionAction,
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:        "project",
+				Usage:       "The `<project_key>` of the repository",
+				Destination: &command.flags.project,
+			},
+			cli.StringFlag{
+				Name:        "repository",
+				Usage:       "The `<repository_slug>` to delete the condition from",
+				Destination: &command.flags.repository,
 			},
-			ToRefMatcher: bitclient.Matcher{
-				Id:   command.flags.branchRef,
-				Type: bitclient.MatcherType{Id: "BRANCH"},
+			cli.IntFlag{
+				Name:        "id",
+				Usage:       "The condition `<id>`, as shown by the list command",
+				Destination: &command.flags.id,
 			},
-			RequiredApprovals: int(command.flags.requiredApprovers),
-			Reviewers:         users,
-		}
+		},
+		BashComplete: func(c *cli.Context) {
+			helper.AutoComplete(c, command.Settings.GetFileCache())
+		},
+	}
+}
 
-		client.CreateRepositoryDefaultReviewers(command.flags.project, command.flags.repository, setting)
+// DeleteDefaultReviewersConditionAction delete the condition with given id
+func (command *DeleteDefaultReviewersConditionCommand) DeleteDefaultReviewersConditionAction(context *cli.Context) error {
+	if len(command.flags.repository) <= 0 {
+		return errors.New("--repository flag is required")
+	}
+	if len(command.flags.project) <= 0 {
+		return errors.New("--project flag is required")
+	}
+	if command.flags.id <= 0 {
+		return errors.New("--id flag is required")
+	}
+
+	restClient, err := command.Settings.GetRestClient()
+	if err != nil {
+		return err
+	}
+
+	err = restClient.DeleteDefaultReviewersCondition(command.flags.project, command.flags.repository, command.flags.id)
+	if err != nil {
+		return err
 	}
 
 	fmt.Printf(
-		"Added %d users as default reviewers on %s for %s/%s\n",
-		len(users),
-		command.flags.branchRef,
+		"[OK] Deleted default reviewers condition #%d on %s/%s\n",
+		command.flags.id,
 		command.flags.project,
 		command.flags.repository,
 	)
 
 	return nil
 }
+
+// resolveReviewers lookup the given usernames, first from the cache then from the api when missing,
+// and expand the given groups to their current members, as the conditions only hold users.
+func resolveReviewers(cache *helper.FileCache, restClient *helper.RestClient, usernames []string, groups []string) ([]bitclient.User, error) {
+	var users []bitclient.User
+
+	for _, username := range usernames {
+		user, err := findCachedUser(cache, username)
+		if errors.Is(err, errAmbiguousDisplayName) {
+			return nil, err
+		}
+		if err != nil {
+			user, err = restClient.GetUser(username)
+			if err != nil {
+				return nil, fmt.Errorf("cannot find any user with %s username: %w", username, err)
+			}
+		}
+
+		users = append(users, user)
+	}
+
+	for _, group := range groups {
+		members, err := restClient.GetGroupMembers(group)
+		if err != nil {
+			return nil, fmt.Errorf("cannot retrieve members of group %s: %w", group, err)
+		}
+
+		users = append(users, members...)
+	}
+
+	return users, nil
+}
+
+// errAmbiguousDisplayName is returned when several users have the display name used to find a user
+var errAmbiguousDisplayName = errors.New("several users have this display name, use their username")
+
+// findCachedUser lookup a cached user from its username, email address or display name.
+// A display name shared by several users is rejected, as the wrong user could be picked.
+func findCachedUser(cache *helper.FileCache, name string) (bitclient.User, error) {
+	if user, err := cache.FindUserByUsername(name); err == nil {
+		return user, nil
+	}
+
+	if user, err := cache.FindUserByEmail(name); err == nil {
+		return user, nil
+	}
+
+	users := cache.FindUsersByDisplayName(name)
+	switch len(users) {
+	case 0:
+		return bitclient.User{}, fmt.Errorf("cannot find any cached user named %s", name)
+	case 1:
+		return users[0], nil
+	}
+
+	var slugs []string
+	for _, user := range users {
+		slugs = append(slugs, user.Slug)
+	}
+
+	return bitclient.User{}, fmt.Errorf("%s (%s): %w", name, strings.Join(slugs, ", "), errAmbiguousDisplayName)
+}
+
+// mergeReviewers append the reviewers of r2 missing in r1
+func mergeReviewers(r1, r2 []bitclient.User) []bitclient.User {
+	r := append([]bitclient.User(nil), r1...)
+
+	for _, reviewer := range r2 {
+		exists := false
+		for _, e := range r {
+			if reviewer.Slug == e.Slug {
+				exists = true
+				break
+			}
+		}
+
+		if exists == false {
+			r = append(r, reviewer)
+		}
+	}
+
+	return r
+}
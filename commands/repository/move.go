@@ -3,6 +3,10 @@ package repository
 
 import (
 	"fmt"
+	"os"
+	"sort"
+	"strings"
+
 	"github.com/daeMOn63/bitadmin/helper"
 	"github.com/daeMOn63/bitadmin/settings"
 	"github.com/daeMOn63/bitclient"
@@ -19,6 +23,7 @@ type MoveCommand struct {
 type MoveCommandFlags struct {
 	project          string
 	repository       string
+	pattern          string
 	targetProject    string
 	targetRepository string
 }
@@ -27,12 +32,12 @@ type MoveCommandFlags struct {
 func (command *MoveCommand) GetCommand() cli.Command {
 	return cli.Command{
 		Name:   "move",
-		Usage:  "Move / rename a repository",
+		Usage:  "Move / rename a repository, or move all the repositories matching a pattern to another project",
 		Action: command.MoveRepositoryAction,
 		Flags: []cli.Flag{
 			cli.StringFlag{
 				Name:        "project",
-				Usage:       "The `<prroject>` of the repository",
+				Usage:       "The `<project>` of the repository",
 				Destination: &command.flags.project,
 			},
 			cli.StringFlag{
@@ -40,6 +45,11 @@ func (command *MoveCommand) GetCommand() cli.Command {
 				Usage:       "The `<repository>` to move",
 				Destination: &command.flags.repository,
 			},
+			cli.StringFlag{
+				Name:        "pattern",
+				Usage:       "Move all the repositories of --project matching the shell `<pattern>` (ie: legacy-*) to --targetProject",
+				Destination: &command.flags.pattern,
+			},
 			cli.StringFlag{
 				Name:        "targetProject",
 				Usage:       "The target `<project>` where the repository will get moved",
@@ -52,9 +62,11 @@ func (command *MoveCommand) GetCommand() cli.Command {
 			},
 		},
 		BashComplete: func(c *cli.Context) {
-			// TODO: improve autocomplete. Here it's not perfect as we want targetProject / targetRepo
-			// flag to list everything, ignoring what can already be set in project / repository flags.
-			helper.AutoComplete(c, command.Settings.GetFileCache())
+			// The target repository is a new name, so the target project list every project
+			helper.AutoCompleteWith(c, command.Settings.GetFileCache(), helper.CompletionProviders{
+				"targetProject":    helper.ProjectValues(""),
+				"targetRepository": helper.RepositoryValues("targetProject"),
+			})
 		},
 	}
 }
@@ -65,16 +77,28 @@ func (command *MoveCommand) MoveRepositoryAction(context *cli.Context) error {
 		return fmt.Errorf("flag --project is required")
 	}
 
-	if len(command.flags.repository) == 0 {
-		return fmt.Errorf("flag --repository is required")
+	if len(command.flags.repository) == 0 && len(command.flags.pattern) == 0 {
+		return fmt.Errorf("one of flag --repository or --pattern is required")
 	}
 
-	if len(command.flags.targetProject) == 0 && len(command.flags.targetRepository) == 0 {
-		return fmt.Errorf("at least one flag --targetProject or --targetRepository is required")
+	if len(command.flags.repository) > 0 && len(command.flags.pattern) > 0 {
+		return fmt.Errorf("flags --repository and --pattern cannot be used together")
+	}
+
+	if len(command.flags.pattern) > 0 && len(command.flags.targetProject) == 0 {
+		return fmt.Errorf("flag --targetProject is required with --pattern")
 	}
 
-	if len(command.flags.targetRepository) == 0 {
-		command.flags.targetRepository = command.flags.repository
+	if len(command.flags.pattern) > 0 && command.flags.targetProject == command.flags.project {
+		return fmt.Errorf("flag --targetProject must differ from --project with --pattern")
+	}
+
+	if len(command.flags.pattern) > 0 && len(command.flags.targetRepository) > 0 {
+		return fmt.Errorf("flag --targetRepository cannot be used with --pattern")
+	}
+
+	if len(command.flags.targetProject) == 0 && len(command.flags.targetRepository) == 0 {
+		return fmt.Errorf("at least one flag --targetProject or --targetRepository is required")
 	}
 
 	if len(command.flags.targetProject) == 0 {
@@ -86,22 +110,279 @@ func (command *MoveCommand) MoveRepositoryAction(context *cli.Context) error {
 		return err
 	}
 
-	params := bitclient.UpdateRepositoryRequest{
-		Name:    command.flags.targetRepository,
-		Project: bitclient.Project{Key: command.flags.targetProject},
+	var repositories []bitclient.Repository
+
+	if len(command.flags.pattern) > 0 {
+		selector := helper.RepositorySelector{Project: command.flags.project, Repository: command.flags.pattern}
+
+		repositories, err = selector.Select(client)
+		if err != nil {
+			return err
+		}
+
+		if len(repositories) == 0 {
+			return fmt.Errorf("no repository match %s", selector)
+		}
+	} else {
+		repository, err := command.findRepository(client)
+		if err != nil {
+			return err
+		}
+
+		repositories = append(repositories, repository)
+	}
+
+	restClient, err := command.Settings.GetRestClient()
+	if err != nil {
+		return err
+	}
+
+	if command.flags.targetProject != command.flags.project {
+		if err := command.warnProjectChanges(restClient); err != nil {
+			return err
+		}
 	}
 
-	client.UpdateRepository(command.flags.project, command.flags.repository, params)
+	cache, err := command.Settings.LoadFileCache()
 	if err != nil {
 		return err
 	}
 
-	fmt.Printf("[OK]%s/%s moved to %s/%s\n",
-		command.flags.project,
-		command.flags.repository,
-		command.flags.targetProject,
-		command.flags.targetRepository,
-	)
+	var failed []string
+	moved := 0
+
+	for _, repository := range repositories {
+		targetName := command.flags.targetRepository
+		if len(targetName) == 0 {
+			targetName = repository.Name
+		}
+
+		movedRepository, err := restClient.MoveRepository(command.flags.project, repository.Slug, command.flags.targetProject, targetName)
+		if err != nil {
+			fmt.Printf("[FAIL] cannot move %s/%s: %s\n", command.flags.project, repository.Slug, err)
+			failed = append(failed, repository.Slug)
+			continue
+		}
+
+		// The target is read back, so a move accepted but not applied is not reported as done
+		movedRepository, err = restClient.GetRepository(command.flags.targetProject, movedRepository.Slug)
+		if err != nil {
+			fmt.Printf("[FAIL] %s/%s not found in %s after the move: %s\n", command.flags.project, repository.Slug, command.flags.targetProject, err)
+			failed = append(failed, repository.Slug)
+			continue
+		}
+
+		cache.ReplaceRepository(command.flags.project, repository.Slug, movedRepository)
+		moved++
+
+		fmt.Printf("[OK] %s/%s moved to %s/%s\n",
+			command.flags.project,
+			repository.Slug,
+			movedRepository.Project.Key,
+			movedRepository.Slug,
+		)
+	}
+
+	if err := cache.Save(); err != nil {
+		return fmt.Errorf("%d repositories moved but cache update failed: %w", moved, err)
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("%d repositories moved, %d failed: %s", moved, len(failed), strings.Join(failed, ", "))
+	}
+
+	return nil
+}
+
+// warnProjectChanges print the project permissions, branch restrictions and hooks the moved repositories will lose or gain
+func (command *MoveCommand) warnProjectChanges(restClient *helper.RestClient) error {
+	source, err := restClient.GetPermissions(command.flags.project, "")
+	if err != nil {
+		return fmt.Errorf("cannot read permissions of project %s: %w", command.flags.project, err)
+	}
+
+	target, err := restClient.GetPermissions(command.flags.targetProject, "")
+	if err != nil {
+		return fmt.Errorf("cannot read permissions of project %s: %w", command.flags.targetProject, err)
+	}
+
+	sourceRestrictions, err := restClient.GetProjectBranchRestrictions(command.flags.project)
+	if err != nil {
+		return fmt.Errorf("cannot read branch restrictions of project %s: %w", command.flags.project, err)
+	}
+
+	targetRestrictions, err := restClient.GetProjectBranchRestrictions(command.flags.targetProject)
+	if err != nil {
+		return fmt.Errorf("cannot read branch restrictions of project %s: %w", command.flags.targetProject, err)
+	}
+
+	sourceHooks, err := restClient.GetRepositoryHooks(command.flags.project, "")
+	if err != nil {
+		return fmt.Errorf("cannot read hooks of project %s: %w", command.flags.project, err)
+	}
+
+	targetHooks, err := restClient.GetRepositoryHooks(command.flags.targetProject, "")
+	if err != nil {
+		return fmt.Errorf("cannot read hooks of project %s: %w", command.flags.targetProject, err)
+	}
+
+	var changes []string
+	changes = append(changes, permissionChanges(source, target)...)
+	changes = append(changes, restrictionChanges(sourceRestrictions, targetRestrictions)...)
+	changes = append(changes, hookChanges(sourceHooks, targetHooks)...)
+
+	for _, change := range changes {
+		fmt.Fprintf(os.Stderr, "[WARN] %s on the moved repositories\n", change)
+	}
 
 	return nil
 }
+
+// permissionChanges list the users and groups whose project permission differ between source and target
+func permissionChanges(source helper.PermissionSnapshot, target helper.PermissionSnapshot) []string {
+	before := make(map[string]string)
+	after := make(map[string]string)
+
+	for _, permission := range source.Users {
+		before["user "+permission.User.Name] = permission.Permission
+	}
+	for _, permission := range source.Groups {
+		before["group "+permission.Group.Name] = permission.Permission
+	}
+	for _, permission := range target.Users {
+		after["user "+permission.User.Name] = permission.Permission
+	}
+	for _, permission := range target.Groups {
+		after["group "+permission.Group.Name] = permission.Permission
+	}
+
+	var changes []string
+
+	for name, permission := range before {
+		switch newPermission, ok := after[name]; {
+		case !ok:
+			changes = append(changes, fmt.Sprintf("%s lose %s", name, permission))
+		case newPermission != permission:
+			changes = append(changes, fmt.Sprintf("%s change from %s to %s", name, permission, newPermission))
+		}
+	}
+
+	for name, permission := range after {
+		if _, ok := before[name]; !ok {
+			changes = append(changes, fmt.Sprintf("%s gain %s", name, permission))
+		}
+	}
+
+	sort.Strings(changes)
+
+	return changes
+}
+
+// restrictionChanges list the project branch restrictions that differ between source and target
+func restrictionChanges(source []bitclient.BranchRestriction, target []bitclient.BranchRestriction) []string {
+	before := make(map[string]string)
+	after := make(map[string]string)
+
+	for _, restriction := range source {
+		before[restrictionName(restriction)] = restrictionExemptions(restriction)
+	}
+	for _, restriction := range target {
+		after[restrictionName(restriction)] = restrictionExemptions(restriction)
+	}
+
+	var changes []string
+
+	for name, exemptions := range before {
+		switch newExemptions, ok := after[name]; {
+		case !ok:
+			changes = append(changes, fmt.Sprintf("%s is removed", name))
+		case newExemptions != exemptions:
+			changes = append(changes, fmt.Sprintf("%s exemptions change from [%s] to [%s]", name, exemptions, newExemptions))
+		}
+	}
+
+	for name := range after {
+		if _, ok := before[name]; !ok {
+			changes = append(changes, fmt.Sprintf("%s is added", name))
+		}
+	}
+
+	sort.Strings(changes)
+
+	return changes
+}
+
+// restrictionName describe the restriction type and the refs it applies to
+func restrictionName(restriction bitclient.BranchRestriction) string {
+	return fmt.Sprintf("%s restriction on %s", restriction.Type, helper.FormatRefMatcher(restriction.Matcher))
+}
+
+// restrictionExemptions list the users and groups exempted from the restriction, sorted so they can be compared
+func restrictionExemptions(restriction bitclient.BranchRestriction) string {
+	var exemptions []string
+
+	for _, user := range restriction.Users {
+		exemptions = append(exemptions, "user "+user.Slug)
+	}
+	for _, group := range restriction.Groups {
+		exemptions = append(exemptions, "group "+group)
+	}
+
+	sort.Strings(exemptions)
+
+	return strings.Join(exemptions, ", ")
+}
+
+// hookChanges list the project hooks enabled in only one of source and target. They apply to the moved repositories
+// which inherit their hooks settings from the project.
+func hookChanges(source []helper.RepositoryHook, target []helper.RepositoryHook) []string {
+	before := make(map[string]bool)
+	after := make(map[string]bool)
+
+	for _, hook := range source {
+		before[hook.Details.Key] = hook.Enabled
+	}
+	for _, hook := range target {
+		after[hook.Details.Key] = hook.Enabled
+	}
+
+	var changes []string
+
+	for key, enabled := range before {
+		if enabled && !after[key] {
+			changes = append(changes, fmt.Sprintf("inherited hook %s is disabled", key))
+		}
+	}
+
+	for key, enabled := range after {
+		if enabled && !before[key] {
+			changes = append(changes, fmt.Sprintf("inherited hook %s is enabled", key))
+		}
+	}
+
+	sort.Strings(changes)
+
+	return changes
+}
+
+// findRepository lookup the repository to move, so its current name is kept when only the project change
+func (command *MoveCommand) findRepository(client *bitclient.BitClient) (bitclient.Repository, error) {
+	if cache, err := command.Settings.LoadFileCache(); err == nil {
+		if repository, err := cache.FindRepository(command.flags.project, command.flags.repository); err == nil {
+			return repository, nil
+		}
+	}
+
+	repositories, err := helper.GetAllRepositories(client, command.flags.project)
+	if err != nil {
+		return bitclient.Repository{}, err
+	}
+
+	for _, repository := range repositories {
+		if repository.Slug == command.flags.repository {
+			return repository, nil
+		}
+	}
+
+	return bitclient.Repository{}, fmt.Errorf("cannot find repository %s/%s", command.flags.project, command.flags.repository)
+}
@@ -83,6 +83,9 @@ func (command *PullRequestSettingsCommand) SetPullRequestSettingsAction(context
 	}
 
 	pullRequestSettings, err := client.GetPullRequestSettings(command.flags.project, command.flags.repository)
+	if err != nil {
+		return err
+	}
 
 	pullRequestSettings.RequiredAllApprovers = command.flags.requiredAllApprovers
 	pullRequestSettings.RequiredAllTasksComplete = command.flags.requiredAllTaskComplete
@@ -46,10 +46,18 @@ func (command *Command) GetCommand() cli.Command {
 
 	branchingModelCommand := &BranchingModelCommand{
 		Settings: command.Settings,
-		flags:    &BranchingModelCommandFlags{},
 	}
 
-	setDefaultReviewersCommand := &SetDefaultReviewersCommand{
+	deprecatedBranchingModelCommand := &BranchingModelSetCommand{
+		Settings: command.Settings,
+		flags:    &BranchingModelSetCommandFlags{},
+	}
+
+	defaultReviewersCommand := &DefaultReviewersCommand{
+		Settings: command.Settings,
+	}
+
+	deprecatedDefaultReviewersCommand := &SetDefaultReviewersCommand{
 		Settings: command.Settings,
 		flags:    &SetDefaultReviewersCommandFlags{},
 	}
@@ -59,6 +67,10 @@ func (command *Command) GetCommand() cli.Command {
 		flags:    &MoveCommandFlags{},
 	}
 
+	accessKeysCommand := &AccessKeysCommand{
+		Settings: command.Settings,
+	}
+
 	return cli.Command{
 		Name:  "repository",
 		Usage: "Repository operations",
@@ -70,8 +82,11 @@ func (command *Command) GetCommand() cli.Command {
 			setBranchRestrictionCommand.GetCommand(),
 			pullRequestSettingsCommand.GetCommand(),
 			branchingModelCommand.GetCommand(),
-			setDefaultReviewersCommand.GetCommand(),
+			deprecatedBranchingModelCommand.GetDeprecatedCommand(),
+			defaultReviewersCommand.GetCommand(),
+			deprecatedDefaultReviewersCommand.GetDeprecatedCommand(),
 			moveCommand.GetCommand(),
+			accessKeysCommand.GetCommand(),
 		},
 	}
 }
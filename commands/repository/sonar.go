@@ -2,14 +2,47 @@
 package repository
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/daeMOn63/bitadmin/helper"
 	"github.com/daeMOn63/bitadmin/settings"
+	"github.com/daeMOn63/bitclient"
 	"github.com/urfave/cli"
 )
 
+// sonarField map a command line flag to a field of the Sonar for Bitbucket settings
+type sonarField struct {
+	flag    string
+	section string
+	field   string
+	kind    string
+	usage   string
+}
+
+// sonarFields list the settings exposed as dedicated flags, parsed with their type so invalid values are rejected
+// before any call. Any other field can be changed with --set.
+var sonarFields = []sonarField{
+	{"enabled", "project", "sonarEnabled", "bool", "Enable the sonar plugin, use --enabled=false to disable it"},
+	{"serverConfigID", "project", "serverConfigId", "int", "The server config identifier to use"},
+	{"sonarMasterProjectKey", "project", "masterProjectKey", "string", "The sonar master project key to link this repository with"},
+	{"sonarProjectBaseKey", "project", "projectBaseKey", "string", "The sonar project base key to link this repository with. Not used when --useSonarBranchFeature is set"},
+	{"analysisMode", "project", "analysisMode", "string", "Sonar analysis mode (one of LEAK_PERIOD or BRANCH_DIFF)"},
+	{"buildType", "project", "buildType", "string", "The build type of the repository (ie: MAVEN, GRADLE, SONAR_SCANNER)"},
+	{"qualityGate", "project", "qualityGate", "string", "The quality gate the analysis must pass"},
+	{"incrementalMode", "project", "incrementalMode", "string", "Incremental analysis mode for pull requests (ie: NONE, ENABLED)"},
+	{"incrementalModeForMvnProjects", "project", "incrementalModeForMvnProjects", "bool", "Use the incremental mode on maven projects"},
+	{"matchingBranchesRegex", "project", "matchingBranchesRegex", "string", "Only analyse the branches matching this regex"},
+	{"useSonarBranchFeature", "project", "useSonarBranchFeature", "bool", "Enable the new branching feature introduced with commercial editions of SonarQube 6.7"},
+	{"showIssuesInSource", "project", "showIssuesInSource", "bool", "Show issues in sources"},
+	{"showOnlyNewOrChangedLines", "project", "showOnlyNewOrChangedLines", "bool", "Show issues only on changed lines"},
+	{"illegalBranchCharReplacement", "project", "illegalBranchCharReplacement", "string", "Replace illegal character in branches (for SonarQube <5.0)"},
+	{"projectCleanupEnabled", "project", "projectCleanupEnabled", "bool", "Enable project cleanup"},
+	{"forkCleanupEnabled", "project", "forkCleanupEnabled", "bool", "Enable cleanup of the fork projects"},
+}
+
 // SonarCommand define base struct for SonarCommand actions
 type SonarCommand struct {
 	Settings *settings.BitAdminSettings
@@ -18,142 +51,311 @@ type SonarCommand struct {
 
 // SonarCommandFlags define flags required by the SonarAction
 type SonarCommandFlags struct {
-	project                      string
-	repository                   string
-	enabled                      bool
-	serverConfigID               int
-	sonarMasterProjectKey        string
-	sonarProjectBaseKey          string
-	analysisMode                 string
-	useSonarBranchFeature        bool
-	showIssuesInSource           bool
-	showOnlyNewOrChangedLines    bool
-	illegalBranchCharReplacement string
-	projectCleanupEnabled        bool
-	// TODO: add more when needed :)
+	project      string
+	repository   string
+	projectLevel bool
+	set          cli.StringSlice
+}
+
+// getFlags provide the flags selecting the targets and the settings to update
+func (flags *SonarCommandFlags) getFlags() []cli.Flag {
+	cliFlags := []cli.Flag{
+		cli.StringFlag{
+			Name:        "project",
+			Usage:       "The `<project_key>` of the repositories, shell patterns are allowed (ie: PRJ*)",
+			Destination: &flags.project,
+		},
+		cli.StringFlag{
+			Name:        "repository",
+			Usage:       "The `<repository_name>`, shell patterns are allowed (ie: *-api)",
+			Destination: &flags.repository,
+		},
+		cli.BoolFlag{
+			Name:        "project-level",
+			Usage:       "Update the project defaults instead of a --repository",
+			Destination: &flags.projectLevel,
+		},
+		cli.StringSliceFlag{
+			Name:  "set",
+			Usage: "Change any other setting with `<section.field=value>` (ie: mergeChecks.qualityGatesEnabled=true). Can be repeated multiple times",
+			Value: &flags.set,
+		},
+	}
+
+	for _, field := range sonarFields {
+		switch field.kind {
+		case "bool":
+			cliFlags = append(cliFlags, cli.BoolFlag{Name: field.flag, Usage: field.usage})
+		case "int":
+			cliFlags = append(cliFlags, cli.IntFlag{Name: field.flag, Usage: field.usage})
+		default:
+			cliFlags = append(cliFlags, cli.StringFlag{Name: field.flag, Usage: field.usage})
+		}
+	}
+
+	return cliFlags
+}
+
+// sonarTargetFlags provide the flags selecting the repositories or projects to read
+func sonarTargetFlags(project *string, repository *string) []cli.Flag {
+	return []cli.Flag{
+		cli.StringFlag{
+			Name:        "project",
+			Usage:       "The `<project_key>` of the repositories, shell patterns are allowed (ie: PRJ*)",
+			Destination: project,
+		},
+		cli.StringFlag{
+			Name:        "repository",
+			Usage:       "The `<repository_name>`, shell patterns are allowed (ie: *-api). Leave empty to target the project defaults",
+			Destination: repository,
+		},
+	}
 }
 
 // GetCommand provide a ready to use cli.Command
 func (command *SonarCommand) GetCommand() cli.Command {
+	showCommand := &SonarShowCommand{
+		Settings: command.Settings,
+		flags:    &SonarShowCommandFlags{},
+	}
+
+	setCommand := &SonarCommand{
+		Settings: command.Settings,
+		flags:    &SonarCommandFlags{},
+	}
+
+	set := setCommand.getCommand()
+	set.Name = "set"
+
+	sonar := command.getCommand()
+	sonar.Subcommands = []cli.Command{
+		showCommand.GetCommand(),
+		set,
+	}
+
+	return sonar
+}
+
+// getCommand provide the command updating the settings, bound to the command own flags
+func (command *SonarCommand) getCommand() cli.Command {
 	return cli.Command{
 		Name:   "sonar",
-		Usage:  "Update sonar setting for a given repository",
+		Usage:  "Update sonar setting for repositories or project defaults, only the provided settings are changed",
 		Action: command.SonarAction,
-		Flags: []cli.Flag{
-			cli.StringFlag{
-				Name:        "project",
-				Usage:       "The `<project_key>` where the repository will be created",
-				Destination: &command.flags.project,
-			},
-			cli.StringFlag{
-				Name:        "repository",
-				Usage:       "The `<repository_name>` to create",
-				Destination: &command.flags.repository,
-			},
-			cli.BoolFlag{
-				Name:        "enabled",
-				Usage:       "Enable the sonar plugin",
-				Destination: &command.flags.enabled,
-			},
-			cli.IntFlag{
-				Name:        "serverConfigID",
-				Usage:       "The server config identifier to use",
-				Destination: &command.flags.serverConfigID,
-			},
-			cli.StringFlag{
-				Name:        "sonarMasterProjectKey",
-				Usage:       "The sonar master project key to link this repository with",
-				Destination: &command.flags.sonarMasterProjectKey,
-			},
-			cli.StringFlag{
-				Name:        "sonarProjectBaseKey",
-				Usage:       "The sonar project base key to link this repository with. Not used when --useSonarBranchFeature is set",
-				Destination: &command.flags.sonarProjectBaseKey,
-			},
-			cli.StringFlag{
-				Name:        "analysisMode",
-				Usage:       "Sonar analysis mode (one of LEAK_PERIOD or BRANCH_DIFF)",
-				Destination: &command.flags.analysisMode,
-			},
-			cli.BoolFlag{
-				Name:        "useSonarBranchFeature",
-				Usage:       "Enable the new branching feature introduced with commercial editions of SonarQube 6.7",
-				Destination: &command.flags.useSonarBranchFeature,
-			},
-			cli.BoolFlag{
-				Name:        "showIssuesInSource",
-				Usage:       "Show issues in sources",
-				Destination: &command.flags.showIssuesInSource,
-			},
-			cli.BoolFlag{
-				Name:        "showOnlyNewOrChangedLines",
-				Usage:       "Show issues only on changed lines",
-				Destination: &command.flags.showOnlyNewOrChangedLines,
-			},
-			cli.StringFlag{
-				Name:        "illegalBranchCharReplacement",
-				Usage:       "Replace illegal character in branches (for SonarQube <5.0)",
-				Destination: &command.flags.analysisMode,
-			},
-			cli.BoolFlag{
-				Name:        "projectCleanupEnabled",
-				Usage:       "Enable project cleanup",
-				Destination: &command.flags.projectCleanupEnabled,
-			},
-		},
+		Flags:  command.flags.getFlags(),
 		BashComplete: func(c *cli.Context) {
 			helper.AutoComplete(c, command.Settings.GetFileCache())
 		},
 	}
 }
 
-// SonarAction allow to turn on the sonar cleanup setting on all available repositories
+// SonarAction update the provided sonar settings on every selected repository, or on the project defaults
 func (command *SonarCommand) SonarAction(context *cli.Context) error {
-	client, _ := command.Settings.GetAPIClient()
-
-	if len(command.flags.repository) <= 0 {
-		return errors.New("--repository flag is required")
-	}
 	if len(command.flags.project) <= 0 {
 		return errors.New("--project flag is required")
 	}
-	if command.flags.serverConfigID <= 0 {
-		return errors.New("--serverConfigID is required")
+	if command.flags.projectLevel && len(command.flags.repository) > 0 {
+		return errors.New("--repository and --project-level cannot be used together")
+	}
+	if !command.flags.projectLevel && len(command.flags.repository) <= 0 {
+		return errors.New("--repository flag is required, or use --project-level to update the project defaults")
+	}
+
+	changes, err := command.getChanges(context)
+	if err != nil {
+		return err
+	}
+
+	if len(changes) == 0 {
+		return errors.New("no setting provided, nothing to update")
+	}
+
+	client, err := command.Settings.GetAPIClient()
+	if err != nil {
+		return err
+	}
+
+	restClient, err := command.Settings.GetRestClient()
+	if err != nil {
+		return err
+	}
+
+	targets, err := selectSonarTargets(client, command.flags.project, command.flags.repository)
+	if err != nil {
+		return err
+	}
+
+	for _, target := range targets {
+		sonarSettings, err := restClient.GetSonarSettings(target.Project.Key, target.Slug)
+		if err != nil {
+			return fmt.Errorf("cannot read sonar settings of %s: %w", sonarTarget(target.Project.Key, target.Slug), err)
+		}
+
+		for _, change := range changes {
+			sonarSettings.Set(change.section, change.field, change.value)
+		}
+
+		_, err = restClient.SetSonarSettings(target.Project.Key, target.Slug, sonarSettings)
+		if err != nil {
+			return fmt.Errorf("cannot update sonar settings of %s: %w", sonarTarget(target.Project.Key, target.Slug), err)
+		}
+
+		fmt.Printf("[OK] Updated sonar settings for %s\n", sonarTarget(target.Project.Key, target.Slug))
 	}
-	if len(command.flags.sonarMasterProjectKey) <= 0 {
-		return errors.New("--sonarMasterProjectKey flag is required")
+
+	return nil
+}
+
+// sonarChange hold a single settings update
+type sonarChange struct {
+	section string
+	field   string
+	value   interface{}
+}
+
+// getChanges collect the settings provided on the command line
+func (command *SonarCommand) getChanges(context *cli.Context) ([]sonarChange, error) {
+	var changes []sonarChange
+
+	for _, field := range sonarFields {
+		if !context.IsSet(field.flag) {
+			continue
+		}
+
+		change := sonarChange{section: field.section, field: field.field}
+
+		switch field.kind {
+		case "bool":
+			change.value = context.Bool(field.flag)
+		case "int":
+			change.value = context.Int(field.flag)
+		default:
+			change.value = context.String(field.flag)
+		}
+
+		changes = append(changes, change)
 	}
-	if len(command.flags.sonarProjectBaseKey) <= 0 {
-		return errors.New("--sonarProjectBaseKey flag is required")
+
+	for _, definition := range command.flags.set {
+		parts := strings.SplitN(definition, "=", 2)
+		path := strings.SplitN(parts[0], ".", 2)
+		if len(parts) != 2 || len(path) != 2 {
+			return nil, fmt.Errorf("invalid --set %s, expected <section.field=value>", definition)
+		}
+
+		// Values are decoded as JSON when possible (true, 42, "text"), and used as plain string otherwise
+		var value interface{}
+		if err := json.Unmarshal([]byte(parts[1]), &value); err != nil {
+			value = parts[1]
+		}
+
+		changes = append(changes, sonarChange{section: path[0], field: path[1], value: value})
 	}
 
-	sonarSettings, _ := client.GetSonarSettings(command.flags.project, command.flags.repository)
+	return changes, nil
+}
 
-	sonarSettings.Project.SonarEnabled = command.flags.enabled
-	sonarSettings.Project.ServerConfigId = command.flags.serverConfigID
-	sonarSettings.Project.MasterProjectKey = command.flags.sonarMasterProjectKey
-	sonarSettings.Project.ProjectBaseKey = command.flags.sonarProjectBaseKey
+// SonarShowCommand define the command printing the sonar settings
+type SonarShowCommand struct {
+	Settings *settings.BitAdminSettings
+	flags    *SonarShowCommandFlags
+}
 
-	if len(command.flags.analysisMode) > 0 {
-		sonarSettings.Project.AnalysisMode = command.flags.analysisMode
+// SonarShowCommandFlags define flags of the SonarShowCommand
+type SonarShowCommandFlags struct {
+	project    string
+	repository string
+}
+
+// GetCommand provide a ready to use cli.Command
+func (command *SonarShowCommand) GetCommand() cli.Command {
+	return cli.Command{
+		Name:   "show",
+		Usage:  "Show the sonar settings of repositories or project defaults",
+		Action: command.ShowAction,
+		Flags:  sonarTargetFlags(&command.flags.project, &command.flags.repository),
+		BashComplete: func(c *cli.Context) {
+			helper.AutoComplete(c, command.Settings.GetFileCache())
+		},
 	}
+}
 
-	sonarSettings.Project.UseSonarBranchFeature = command.flags.useSonarBranchFeature
-	sonarSettings.Project.ShowIssuesInSource = command.flags.showIssuesInSource
-	sonarSettings.Project.ShowOnlyNewOrChangedLines = command.flags.showOnlyNewOrChangedLines
+// ShowAction print the sonar settings of every selected repository, or of the project defaults
+func (command *SonarShowCommand) ShowAction(context *cli.Context) error {
+	if len(command.flags.project) <= 0 {
+		return errors.New("--project flag is required")
+	}
 
-	if len(command.flags.illegalBranchCharReplacement) > 0 {
-		sonarSettings.Project.IllegalBranchCharReplacement = command.flags.illegalBranchCharReplacement
+	client, err := command.Settings.GetAPIClient()
+	if err != nil {
+		return err
 	}
 
-	sonarSettings.Project.ProjectCleanupEnabled = command.flags.projectCleanupEnabled
+	restClient, err := command.Settings.GetRestClient()
+	if err != nil {
+		return err
+	}
 
-	err := client.SetSonarSettings(command.flags.project, command.flags.repository, sonarSettings)
+	targets, err := selectSonarTargets(client, command.flags.project, command.flags.repository)
 	if err != nil {
 		return err
 	}
 
-	fmt.Printf("[OK] Updated sonar cleanup settings for repository %s/%s\n", command.flags.project, command.flags.repository)
+	for _, target := range targets {
+		sonarSettings, err := restClient.GetSonarSettings(target.Project.Key, target.Slug)
+		if err != nil {
+			return fmt.Errorf("cannot read sonar settings of %s: %w", sonarTarget(target.Project.Key, target.Slug), err)
+		}
+
+		data, err := json.MarshalIndent(sonarSettings, "", "  ")
+		if err != nil {
+			return err
+		}
+
+		if len(targets) > 1 {
+			fmt.Printf("# %s\n", sonarTarget(target.Project.Key, target.Slug))
+		}
+		fmt.Println(string(data))
+	}
 
 	return nil
 }
+
+// selectSonarTargets return the repositories matching the patterns. When repository is empty, the matching projects
+// are returned as repositories without slug, standing for the project defaults.
+func selectSonarTargets(client *bitclient.BitClient, project string, repository string) ([]bitclient.Repository, error) {
+	selector := helper.RepositorySelector{Project: project, Repository: repository}
+
+	var targets []bitclient.Repository
+
+	if len(repository) > 0 {
+		repositories, err := selector.Select(client)
+		if err != nil {
+			return nil, err
+		}
+		targets = repositories
+	} else {
+		projectKeys, err := selector.SelectProjects(client)
+		if err != nil {
+			return nil, err
+		}
+		for _, projectKey := range projectKeys {
+			targets = append(targets, bitclient.Repository{Project: bitclient.Project{Key: projectKey}})
+		}
+	}
+
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("no repository match %s", selector)
+	}
+
+	return targets, nil
+}
+
+// sonarTarget give a readable name of the repository, or the project defaults when repositorySlug is empty
+func sonarTarget(projectKey string, repositorySlug string) string {
+	if len(repositorySlug) == 0 {
+		return fmt.Sprintf("project %s defaults", projectKey)
+	}
+
+	return fmt.Sprintf("repository %s/%s", projectKey, repositorySlug)
+}
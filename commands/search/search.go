@@ -0,0 +1,248 @@
+// Package search provide a fuzzy search over the Bitbucket users, projects and repositories
+package search
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/daeMOn63/bitadmin/helper"
+	"github.com/daeMOn63/bitadmin/settings"
+	"github.com/daeMOn63/bitclient"
+	"github.com/urfave/cli"
+)
+
+// Searchable entity types
+const (
+	typeUser       = "user"
+	typeProject    = "project"
+	typeRepository = "repository"
+)
+
+// Command define the search command
+type Command struct {
+	Settings *settings.BitAdminSettings
+	flags    *CommandFlags
+}
+
+// CommandFlags define the flags of the search command
+type CommandFlags struct {
+	types cli.StringSlice
+	limit int
+	api   bool
+}
+
+// result is a single search match
+type result struct {
+	kind  string
+	name  string
+	label string
+	score int
+	links bitclient.Links
+}
+
+// GetCommand provide a ready to use cli.Command
+func (command *Command) GetCommand() cli.Command {
+	if command.flags == nil {
+		command.flags = &CommandFlags{}
+	}
+
+	return cli.Command{
+		Name:      "search",
+		Usage:     "Fuzzy search users, projects and repositories in the cache, falling back on the api when nothing is found",
+		ArgsUsage: "<term>",
+		Action:    command.SearchAction,
+		Flags: []cli.Flag{
+			cli.StringSliceFlag{
+				Name:  "type",
+				Usage: "Only search the entities of `<type>`, one of user, project or repository. Can be repeated multiple times",
+				Value: &command.flags.types,
+			},
+			cli.IntFlag{
+				Name:        "limit",
+				Usage:       "Display at most `<count>` results",
+				Value:       10,
+				Destination: &command.flags.limit,
+			},
+			cli.BoolFlag{
+				Name:        "api",
+				Usage:       "Search with the api even when the cache has results",
+				Destination: &command.flags.api,
+			},
+		},
+		BashComplete: func(c *cli.Context) {
+			helper.AutoComplete(c, command.Settings.GetFileCache())
+		},
+	}
+}
+
+// SearchAction print the best matches of the term, with their links
+func (command *Command) SearchAction(context *cli.Context) error {
+	term := strings.TrimSpace(strings.Join(context.Args(), " "))
+	if len(term) == 0 {
+		return errors.New("a search term is required")
+	}
+
+	types := make(map[string]bool)
+	for _, kind := range command.flags.types {
+		switch kind {
+		case typeUser, typeProject, typeRepository:
+			types[kind] = true
+		default:
+			return fmt.Errorf("invalid --type %s, must be one of %s, %s or %s", kind, typeUser, typeProject, typeRepository)
+		}
+	}
+	if len(types) == 0 {
+		types = map[string]bool{typeUser: true, typeProject: true, typeRepository: true}
+	}
+
+	var results []result
+	if !command.flags.api {
+		cache, err := command.Settings.LoadFileCache()
+		if err != nil {
+			return err
+		}
+
+		results = searchCache(cache, term, types)
+	}
+
+	if len(results) == 0 {
+		if !command.flags.api {
+			fmt.Fprintln(os.Stderr, "Nothing found in the cache, searching with the api...")
+		}
+
+		restClient, err := command.Settings.GetRestClient()
+		if err != nil {
+			return err
+		}
+
+		results, err = searchAPI(restClient, term, types, uint(command.flags.limit))
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(results) == 0 {
+		return fmt.Errorf("nothing match %s", term)
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].score != results[j].score {
+			return results[i].score > results[j].score
+		}
+		return results[i].name < results[j].name
+	})
+
+	if command.flags.limit > 0 && len(results) > command.flags.limit {
+		results = results[:command.flags.limit]
+	}
+
+	for _, match := range results {
+		fmt.Printf("[%s] %s - %s\n", match.kind, match.name, match.label)
+		helper.PrintLinks(match.links)
+		fmt.Println()
+	}
+
+	return nil
+}
+
+// searchCache rate every cached entity against term
+func searchCache(cache *helper.FileCache, term string, types map[string]bool) []result {
+	var results []result
+
+	if types[typeUser] {
+		for _, user := range cache.Users() {
+			if score := helper.BestFuzzyScore(term, user.Slug, user.Name, user.DisplayName, user.EmailAddress); score > 0 {
+				results = append(results, userResult(user, score))
+			}
+		}
+	}
+
+	if types[typeProject] {
+		for _, project := range cache.Projects() {
+			if score := helper.BestFuzzyScore(term, project.Key, project.Name); score > 0 {
+				results = append(results, projectResult(project, score))
+			}
+		}
+	}
+
+	if types[typeRepository] {
+		for _, repository := range cache.Repositories() {
+			fullName := repository.Project.Key + "/" + repository.Slug
+			if score := helper.BestFuzzyScore(term, repository.Slug, repository.Name, fullName); score > 0 {
+				results = append(results, repositoryResult(repository, score))
+			}
+		}
+	}
+
+	return results
+}
+
+// searchAPI use the Bitbucket filtered listings, still rated so the best matches come first
+func searchAPI(restClient *helper.RestClient, term string, types map[string]bool, limit uint) ([]result, error) {
+	var results []result
+
+	if types[typeUser] {
+		users, err := restClient.SearchUsers(term, limit)
+		if err != nil {
+			return nil, err
+		}
+		for _, user := range users {
+			results = append(results, userResult(user, helper.BestFuzzyScore(term, user.Slug, user.Name, user.DisplayName, user.EmailAddress)))
+		}
+	}
+
+	if types[typeProject] {
+		projects, err := restClient.SearchProjects(term, limit)
+		if err != nil {
+			return nil, err
+		}
+		for _, project := range projects {
+			results = append(results, projectResult(project, helper.BestFuzzyScore(term, project.Key, project.Name)))
+		}
+	}
+
+	if types[typeRepository] {
+		repositories, err := restClient.SearchRepositories(term, limit)
+		if err != nil {
+			return nil, err
+		}
+		for _, repository := range repositories {
+			results = append(results, repositoryResult(repository, helper.BestFuzzyScore(term, repository.Slug, repository.Name)))
+		}
+	}
+
+	return results, nil
+}
+
+func userResult(user bitclient.User, score int) result {
+	return result{
+		kind:  typeUser,
+		name:  user.Slug,
+		label: fmt.Sprintf("%s <%s>", user.DisplayName, user.EmailAddress),
+		score: score,
+		links: user.Links,
+	}
+}
+
+func projectResult(project bitclient.Project, score int) result {
+	return result{
+		kind:  typeProject,
+		name:  project.Key,
+		label: project.Name,
+		score: score,
+		links: project.Links,
+	}
+}
+
+func repositoryResult(repository bitclient.Repository, score int) result {
+	return result{
+		kind:  typeRepository,
+		name:  repository.Project.Key + "/" + repository.Slug,
+		label: repository.Name,
+		score: score,
+		links: repository.Links,
+	}
+}
@@ -3,6 +3,7 @@ package user
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/daeMOn63/bitadmin/helper"
 	"github.com/daeMOn63/bitadmin/settings"
@@ -88,6 +89,11 @@ func (command *GrantCommand) GrantAction(context *cli.Context) error {
 		return err
 	}
 
+	cache, err := command.Settings.LoadFileCache()
+	if err != nil {
+		return err
+	}
+
 	for _, username := range command.flags.usernames {
 		params := bitclient.SetRepositoryUserPermissionRequest{
 			Username:   username,
@@ -114,6 +120,11 @@ func (command *GrantCommand) GrantAction(context *cli.Context) error {
 			username,
 			command.flags.permission,
 		)
+
+		cache.UpdateUserPermission(command.flags.project, command.flags.repository, username, command.flags.permission)
+		if err := cache.Save(); err != nil {
+			fmt.Fprintf(os.Stderr, "[WARN] cannot update the cached permissions: %s\n", err)
+		}
 	}
 
 	if command.flags.masterMerge {
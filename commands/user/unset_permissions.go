@@ -3,6 +3,7 @@ package user
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/daeMOn63/bitadmin/helper"
 	"github.com/daeMOn63/bitadmin/settings"
@@ -71,6 +72,12 @@ func (command *UnsetPermissionsCommand) UnsetPermissionsAction(context *cli.Cont
 	if err != nil {
 		return err
 	}
+
+	cache, err := command.Settings.LoadFileCache()
+	if err != nil {
+		return err
+	}
+
 	for _, username := range command.flags.usernames {
 		params := bitclient.UnsetRepositoryUserPermissionRequest{
 			Username: username,
@@ -94,6 +101,11 @@ func (command *UnsetPermissionsCommand) UnsetPermissionsAction(context *cli.Cont
 			command.flags.repository,
 			username,
 		)
+
+		cache.UpdateUserPermission(command.flags.project, command.flags.repository, username, "")
+		if err := cache.Save(); err != nil {
+			fmt.Fprintf(os.Stderr, "[WARN] cannot update the cached permissions: %s\n", err)
+		}
 	}
 
 	return nil
@@ -0,0 +1,149 @@
+// Package webhooks hold actions on the Bitbucket repository webhooks
+package webhooks
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/daeMOn63/bitadmin/helper"
+	"github.com/daeMOn63/bitadmin/settings"
+	"github.com/urfave/cli"
+)
+
+// CreateCommand define base struct for the webhooks create action
+type CreateCommand struct {
+	Settings *settings.BitAdminSettings
+	flags    *CreateCommandFlags
+}
+
+// CreateCommandFlags hold flag values for the CreateCommand
+type CreateCommandFlags struct {
+	selectorFlags
+	name           string
+	url            string
+	events         cli.StringSlice
+	secretFile     string
+	inactive       bool
+	allowDuplicate bool
+}
+
+// GetCommand provide a ready to use cli.Command
+func (command *CreateCommand) GetCommand() cli.Command {
+	return cli.Command{
+		Name:   "create",
+		Usage:  "Create a webhook on repositories",
+		Action: command.CreateAction,
+		Flags: append(
+			command.flags.selectorFlags.getFlags(),
+			cli.StringFlag{
+				Name:        "name",
+				Usage:       "The `<name>` of the webhook",
+				Destination: &command.flags.name,
+			},
+			cli.StringFlag{
+				Name:        "url",
+				Usage:       "The `<url>` the webhook will call",
+				Destination: &command.flags.url,
+			},
+			cli.StringSliceFlag{
+				Name:  "event",
+				Usage: "The `<event>` triggering the webhook (ie: push, pr-opened, pr-merged, pr-declined, pr-comment-added or a Bitbucket event key). Can be repeated multiple times",
+				Value: &command.flags.events,
+			},
+			cli.StringFlag{
+				Name:        "secret-file",
+				Usage:       "Read the webhook signing secret from `<file>`",
+				Destination: &command.flags.secretFile,
+			},
+			cli.BoolFlag{
+				Name:        "inactive",
+				Usage:       "Create the webhook disabled",
+				Destination: &command.flags.inactive,
+			},
+			cli.BoolFlag{
+				Name:        "allow-duplicate",
+				Usage:       "Create the webhook even if another one already point at the same url",
+				Destination: &command.flags.allowDuplicate,
+			},
+		),
+		BashComplete: func(c *cli.Context) {
+			helper.AutoComplete(c, command.Settings.GetFileCache())
+		},
+	}
+}
+
+// CreateAction create the webhook on every selected repository
+func (command *CreateCommand) CreateAction(context *cli.Context) error {
+	if len(command.flags.name) <= 0 {
+		return errors.New("--name flag is required")
+	}
+	if len(command.flags.url) <= 0 {
+		return errors.New("--url flag is required")
+	}
+	if len(command.flags.events) == 0 {
+		return errors.New("At least one --event is required")
+	}
+
+	events, err := helper.WebhookEvents(command.flags.events)
+	if err != nil {
+		return err
+	}
+
+	webhook := helper.Webhook{
+		Name:   command.flags.name,
+		URL:    command.flags.url,
+		Events: events,
+		Active: !command.flags.inactive,
+	}
+
+	if len(command.flags.secretFile) > 0 {
+		secret, err := readSecret(command.flags.secretFile)
+		if err != nil {
+			return err
+		}
+
+		webhook.Configuration = map[string]string{"secret": secret}
+	}
+
+	client, err := command.Settings.GetAPIClient()
+	if err != nil {
+		return err
+	}
+
+	restClient, err := command.Settings.GetRestClient()
+	if err != nil {
+		return err
+	}
+
+	repositories, err := command.flags.selectRepositories(client)
+	if err != nil {
+		return err
+	}
+
+	for _, repository := range repositories {
+		existing, err := restClient.GetWebhooks(repository.Project.Key, repository.Slug)
+		if err != nil {
+			return err
+		}
+
+		if duplicates := helper.FindWebhooksByURL(existing, webhook.URL); len(duplicates) > 0 && !command.flags.allowDuplicate {
+			fmt.Printf(
+				"[SKIP] %s/%s already have webhook #%d on %s\n",
+				repository.Project.Key,
+				repository.Slug,
+				duplicates[0].ID,
+				webhook.URL,
+			)
+			continue
+		}
+
+		created, err := restClient.CreateWebhook(repository.Project.Key, repository.Slug, webhook)
+		if err != nil {
+			return fmt.Errorf("cannot create webhook on %s/%s: %w", repository.Project.Key, repository.Slug, err)
+		}
+
+		fmt.Printf("[OK] created webhook #%d on %s/%s\n", created.ID, repository.Project.Key, repository.Slug)
+	}
+
+	return nil
+}
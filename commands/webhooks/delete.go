@@ -0,0 +1,105 @@
+// Package webhooks hold actions on the Bitbucket repository webhooks
+package webhooks
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/daeMOn63/bitadmin/helper"
+	"github.com/daeMOn63/bitadmin/settings"
+	"github.com/urfave/cli"
+)
+
+// DeleteCommand define base struct for the webhooks delete action
+type DeleteCommand struct {
+	Settings *settings.BitAdminSettings
+	flags    *DeleteCommandFlags
+}
+
+// DeleteCommandFlags hold flag values for the DeleteCommand
+type DeleteCommandFlags struct {
+	selectorFlags
+	id  int
+	url string
+}
+
+// GetCommand provide a ready to use cli.Command
+func (command *DeleteCommand) GetCommand() cli.Command {
+	return cli.Command{
+		Name:   "delete",
+		Usage:  "Delete a webhook from repositories",
+		Action: command.DeleteAction,
+		Flags: append(
+			command.flags.selectorFlags.getFlags(),
+			cli.IntFlag{
+				Name:        "id",
+				Usage:       "The `<id>` of the webhook to delete, only when a single repository is selected",
+				Destination: &command.flags.id,
+			},
+			cli.StringFlag{
+				Name:        "url",
+				Usage:       "Delete every webhook pointing at `<url>`",
+				Destination: &command.flags.url,
+			},
+		),
+		BashComplete: func(c *cli.Context) {
+			helper.AutoComplete(c, command.Settings.GetFileCache())
+		},
+	}
+}
+
+// DeleteAction remove the webhook from every selected repository
+func (command *DeleteCommand) DeleteAction(context *cli.Context) error {
+	if command.flags.id <= 0 && len(command.flags.url) <= 0 {
+		return errors.New("one of --id or --url flag is required")
+	}
+
+	client, err := command.Settings.GetAPIClient()
+	if err != nil {
+		return err
+	}
+
+	restClient, err := command.Settings.GetRestClient()
+	if err != nil {
+		return err
+	}
+
+	repositories, err := command.flags.selectRepositories(client)
+	if err != nil {
+		return err
+	}
+
+	if err := checkWebhookID(command.flags.id, repositories); err != nil {
+		return err
+	}
+
+	for _, repository := range repositories {
+		webhooks, err := restClient.GetWebhooks(repository.Project.Key, repository.Slug)
+		if err != nil {
+			return err
+		}
+
+		var targets []helper.Webhook
+		if command.flags.id > 0 {
+			webhook, err := findWebhook(webhooks, command.flags.id, "")
+			if err != nil {
+				fmt.Printf("[SKIP] %s/%s: %s\n", repository.Project.Key, repository.Slug, err)
+				continue
+			}
+			targets = append(targets, webhook)
+		} else {
+			targets = helper.FindWebhooksByURL(webhooks, command.flags.url)
+		}
+
+		for _, webhook := range targets {
+			err := restClient.DeleteWebhook(repository.Project.Key, repository.Slug, webhook.ID)
+			if err != nil {
+				return fmt.Errorf("cannot delete webhook #%d on %s/%s: %w", webhook.ID, repository.Project.Key, repository.Slug, err)
+			}
+
+			fmt.Printf("[OK] deleted webhook #%d on %s/%s\n", webhook.ID, repository.Project.Key, repository.Slug)
+		}
+	}
+
+	return nil
+}
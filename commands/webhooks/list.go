@@ -0,0 +1,84 @@
+// Package webhooks hold actions on the Bitbucket repository webhooks
+package webhooks
+
+import (
+	"fmt"
+
+	"github.com/daeMOn63/bitadmin/helper"
+	"github.com/daeMOn63/bitadmin/settings"
+	"github.com/urfave/cli"
+)
+
+// ListCommand define base struct for the webhooks list action
+type ListCommand struct {
+	Settings *settings.BitAdminSettings
+	flags    *ListCommandFlags
+}
+
+// ListCommandFlags hold flag values for the ListCommand
+type ListCommandFlags struct {
+	selectorFlags
+	duplicates bool
+}
+
+// GetCommand provide a ready to use cli.Command
+func (command *ListCommand) GetCommand() cli.Command {
+	return cli.Command{
+		Name:   "list",
+		Usage:  "List webhooks of repositories",
+		Action: command.ListAction,
+		Flags: append(
+			command.flags.selectorFlags.getFlags(),
+			cli.BoolFlag{
+				Name:        "duplicates",
+				Usage:       "Only show the webhooks pointing at the same url than another webhook of the repository",
+				Destination: &command.flags.duplicates,
+			},
+		),
+		BashComplete: func(c *cli.Context) {
+			helper.AutoComplete(c, command.Settings.GetFileCache())
+		},
+	}
+}
+
+// ListAction print the webhooks of every selected repository
+func (command *ListCommand) ListAction(context *cli.Context) error {
+	client, err := command.Settings.GetAPIClient()
+	if err != nil {
+		return err
+	}
+
+	restClient, err := command.Settings.GetRestClient()
+	if err != nil {
+		return err
+	}
+
+	repositories, err := command.flags.selectRepositories(client)
+	if err != nil {
+		return err
+	}
+
+	for _, repository := range repositories {
+		webhooks, err := restClient.GetWebhooks(repository.Project.Key, repository.Slug)
+		if err != nil {
+			return err
+		}
+
+		for _, webhook := range webhooks {
+			duplicated := len(helper.FindWebhooksByURL(webhooks, webhook.URL)) > 1
+
+			if command.flags.duplicates && !duplicated {
+				continue
+			}
+
+			line := fmt.Sprintf("%s/%s %s", repository.Project.Key, repository.Slug, webhook)
+			if duplicated {
+				line += " [DUPLICATE]"
+			}
+
+			fmt.Println(line)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,105 @@
+// Package webhooks hold actions on the Bitbucket repository webhooks
+package webhooks
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/daeMOn63/bitadmin/helper"
+	"github.com/daeMOn63/bitadmin/settings"
+	"github.com/urfave/cli"
+)
+
+// TestCommand define base struct for the webhooks test action
+type TestCommand struct {
+	Settings *settings.BitAdminSettings
+	flags    *TestCommandFlags
+}
+
+// TestCommandFlags hold flag values for the TestCommand
+type TestCommandFlags struct {
+	project    string
+	repository string
+	id         int
+	url        string
+}
+
+// GetCommand provide a ready to use cli.Command
+func (command *TestCommand) GetCommand() cli.Command {
+	return cli.Command{
+		Name:   "test",
+		Usage:  "Ask Bitbucket to call a webhook url and print the response",
+		Action: command.TestAction,
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:        "project",
+				Usage:       "The `<project_key>` of the repository",
+				Destination: &command.flags.project,
+			},
+			cli.StringFlag{
+				Name:        "repository",
+				Usage:       "The `<repository_slug>` the webhook belong to",
+				Destination: &command.flags.repository,
+			},
+			cli.IntFlag{
+				Name:        "id",
+				Usage:       "The `<id>` of the webhook to test",
+				Destination: &command.flags.id,
+			},
+			cli.StringFlag{
+				Name:        "url",
+				Usage:       "The `<url>` to test, when --id is not known",
+				Destination: &command.flags.url,
+			},
+		},
+		BashComplete: func(c *cli.Context) {
+			helper.AutoComplete(c, command.Settings.GetFileCache())
+		},
+	}
+}
+
+// TestAction trigger a test call to the webhook url
+func (command *TestCommand) TestAction(context *cli.Context) error {
+	if len(command.flags.project) <= 0 {
+		return errors.New("--project flag is required")
+	}
+	if len(command.flags.repository) <= 0 {
+		return errors.New("--repository flag is required")
+	}
+	if command.flags.id <= 0 && len(command.flags.url) <= 0 {
+		return errors.New("one of --id or --url flag is required")
+	}
+
+	restClient, err := command.Settings.GetRestClient()
+	if err != nil {
+		return err
+	}
+
+	url := command.flags.url
+	if command.flags.id > 0 {
+		webhooks, err := restClient.GetWebhooks(command.flags.project, command.flags.repository)
+		if err != nil {
+			return err
+		}
+
+		webhook, err := findWebhook(webhooks, command.flags.id, "")
+		if err != nil {
+			return err
+		}
+
+		url = webhook.URL
+	}
+
+	response, err := restClient.TestWebhook(command.flags.project, command.flags.repository, url)
+	if err != nil {
+		return err
+	}
+
+	if response.Response.StatusCode < 200 || response.Response.StatusCode >= 300 {
+		return fmt.Errorf("%s answered with status %d: %s", url, response.Response.StatusCode, response.Response.Body)
+	}
+
+	fmt.Printf("[OK] %s answered with status %d\n", url, response.Response.StatusCode)
+
+	return nil
+}
@@ -0,0 +1,174 @@
+// Package webhooks hold actions on the Bitbucket repository webhooks
+package webhooks
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/daeMOn63/bitadmin/helper"
+	"github.com/daeMOn63/bitadmin/settings"
+	"github.com/urfave/cli"
+)
+
+// UpdateCommand define base struct for the webhooks update action
+type UpdateCommand struct {
+	Settings *settings.BitAdminSettings
+	flags    *UpdateCommandFlags
+}
+
+// UpdateCommandFlags hold flag values for the UpdateCommand
+type UpdateCommandFlags struct {
+	selectorFlags
+	id         int
+	url        string
+	name       string
+	newURL     string
+	events     cli.StringSlice
+	secretFile string
+	active     bool
+	inactive   bool
+}
+
+// GetCommand provide a ready to use cli.Command
+func (command *UpdateCommand) GetCommand() cli.Command {
+	return cli.Command{
+		Name:   "update",
+		Usage:  "Update a webhook on repositories. Options not provided are left unchanged",
+		Action: command.UpdateAction,
+		Flags: append(
+			command.flags.selectorFlags.getFlags(),
+			cli.IntFlag{
+				Name:        "id",
+				Usage:       "The `<id>` of the webhook to update, only when a single repository is selected",
+				Destination: &command.flags.id,
+			},
+			cli.StringFlag{
+				Name:        "url",
+				Usage:       "Select the webhook to update from its current `<url>`, when --id is not known",
+				Destination: &command.flags.url,
+			},
+			cli.StringFlag{
+				Name:        "name",
+				Usage:       "The new `<name>` of the webhook",
+				Destination: &command.flags.name,
+			},
+			cli.StringFlag{
+				Name:        "new-url",
+				Usage:       "The new `<url>` the webhook will call",
+				Destination: &command.flags.newURL,
+			},
+			cli.StringSliceFlag{
+				Name:  "event",
+				Usage: "Replace the events triggering the webhook by `<event>`. Can be repeated multiple times",
+				Value: &command.flags.events,
+			},
+			cli.StringFlag{
+				Name:        "secret-file",
+				Usage:       "Read the new webhook signing secret from `<file>`",
+				Destination: &command.flags.secretFile,
+			},
+			cli.BoolFlag{
+				Name:        "active",
+				Usage:       "Enable the webhook",
+				Destination: &command.flags.active,
+			},
+			cli.BoolFlag{
+				Name:        "inactive",
+				Usage:       "Disable the webhook",
+				Destination: &command.flags.inactive,
+			},
+		),
+		BashComplete: func(c *cli.Context) {
+			helper.AutoComplete(c, command.Settings.GetFileCache())
+		},
+	}
+}
+
+// UpdateAction apply the provided changes on the webhook of every selected repository
+func (command *UpdateCommand) UpdateAction(context *cli.Context) error {
+	if command.flags.id <= 0 && len(command.flags.url) <= 0 {
+		return errors.New("one of --id or --url flag is required")
+	}
+	if command.flags.active && command.flags.inactive {
+		return errors.New("--active and --inactive cannot be used together")
+	}
+
+	var events []string
+	if len(command.flags.events) > 0 {
+		var err error
+		if events, err = helper.WebhookEvents(command.flags.events); err != nil {
+			return err
+		}
+	}
+
+	var secret string
+	if len(command.flags.secretFile) > 0 {
+		var err error
+		if secret, err = readSecret(command.flags.secretFile); err != nil {
+			return err
+		}
+	}
+
+	client, err := command.Settings.GetAPIClient()
+	if err != nil {
+		return err
+	}
+
+	restClient, err := command.Settings.GetRestClient()
+	if err != nil {
+		return err
+	}
+
+	repositories, err := command.flags.selectRepositories(client)
+	if err != nil {
+		return err
+	}
+
+	if err := checkWebhookID(command.flags.id, repositories); err != nil {
+		return err
+	}
+
+	for _, repository := range repositories {
+		webhooks, err := restClient.GetWebhooks(repository.Project.Key, repository.Slug)
+		if err != nil {
+			return err
+		}
+
+		webhook, err := findWebhook(webhooks, command.flags.id, command.flags.url)
+		if err != nil {
+			fmt.Printf("[SKIP] %s/%s: %s\n", repository.Project.Key, repository.Slug, err)
+			continue
+		}
+
+		if len(command.flags.name) > 0 {
+			webhook.Name = command.flags.name
+		}
+		if len(command.flags.newURL) > 0 {
+			webhook.URL = command.flags.newURL
+		}
+		if len(events) > 0 {
+			webhook.Events = events
+		}
+		if len(secret) > 0 {
+			if webhook.Configuration == nil {
+				webhook.Configuration = make(map[string]string)
+			}
+			webhook.Configuration["secret"] = secret
+		}
+		if command.flags.active {
+			webhook.Active = true
+		}
+		if command.flags.inactive {
+			webhook.Active = false
+		}
+
+		_, err = restClient.UpdateWebhook(repository.Project.Key, repository.Slug, webhook)
+		if err != nil {
+			return fmt.Errorf("cannot update webhook #%d on %s/%s: %w", webhook.ID, repository.Project.Key, repository.Slug, err)
+		}
+
+		fmt.Printf("[OK] updated webhook #%d on %s/%s\n", webhook.ID, repository.Project.Key, repository.Slug)
+	}
+
+	return nil
+}
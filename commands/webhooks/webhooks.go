@@ -0,0 +1,151 @@
+// Package webhooks hold actions on the Bitbucket repository webhooks
+package webhooks
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/daeMOn63/bitadmin/helper"
+	"github.com/daeMOn63/bitadmin/settings"
+	"github.com/daeMOn63/bitclient"
+	"github.com/urfave/cli"
+)
+
+// Command define base struct for webhooks subcommands and actions
+type Command struct {
+	Settings *settings.BitAdminSettings
+}
+
+// GetCommand provide a ready to use cli.Command
+func (command *Command) GetCommand() cli.Command {
+
+	listCommand := &ListCommand{
+		Settings: command.Settings,
+		flags:    &ListCommandFlags{},
+	}
+
+	createCommand := &CreateCommand{
+		Settings: command.Settings,
+		flags:    &CreateCommandFlags{},
+	}
+
+	updateCommand := &UpdateCommand{
+		Settings: command.Settings,
+		flags:    &UpdateCommandFlags{},
+	}
+
+	deleteCommand := &DeleteCommand{
+		Settings: command.Settings,
+		flags:    &DeleteCommandFlags{},
+	}
+
+	testCommand := &TestCommand{
+		Settings: command.Settings,
+		flags:    &TestCommandFlags{},
+	}
+
+	return cli.Command{
+		Name:  "webhooks",
+		Usage: "Repository webhooks operations",
+		Subcommands: []cli.Command{
+			listCommand.GetCommand(),
+			createCommand.GetCommand(),
+			updateCommand.GetCommand(),
+			deleteCommand.GetCommand(),
+			testCommand.GetCommand(),
+		},
+	}
+}
+
+// selectorFlags hold the flag values selecting the repositories to work on
+type selectorFlags struct {
+	project    string
+	repository string
+}
+
+// getFlags provide the repository selection flags
+func (flags *selectorFlags) getFlags() []cli.Flag {
+	return []cli.Flag{
+		cli.StringFlag{
+			Name:        "project",
+			Usage:       "The `<project_key>` of the repositories, shell patterns are allowed (ie: PRJ*)",
+			Destination: &flags.project,
+		},
+		cli.StringFlag{
+			Name:        "repository",
+			Usage:       "The `<repository_slug>`, shell patterns are allowed (ie: *-api)",
+			Destination: &flags.repository,
+		},
+	}
+}
+
+// selectRepositories return the repositories matching the selector flags
+func (flags *selectorFlags) selectRepositories(client *bitclient.BitClient) ([]bitclient.Repository, error) {
+	if len(flags.project) <= 0 {
+		return nil, errors.New("--project flag is required")
+	}
+	if len(flags.repository) <= 0 {
+		return nil, errors.New("--repository flag is required")
+	}
+
+	selector := helper.RepositorySelector{Project: flags.project, Repository: flags.repository}
+
+	repositories, err := selector.Select(client)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(repositories) == 0 {
+		return nil, fmt.Errorf("no repository matching %s", selector)
+	}
+
+	return repositories, nil
+}
+
+// checkWebhookID reject --id when several repositories are selected, as webhook ids are only unique within a repository
+func checkWebhookID(id int, repositories []bitclient.Repository) error {
+	if id > 0 && len(repositories) > 1 {
+		return fmt.Errorf("--id cannot be used when %d repositories are selected, webhook ids are specific to each repository: use --url instead", len(repositories))
+	}
+
+	return nil
+}
+
+// readSecret load the webhook secret from given file, which must not be readable by others
+func readSecret(filename string) (string, error) {
+	if err := helper.CheckSecretFilePermissions(filename); err != nil {
+		return "", err
+	}
+
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(data)), nil
+}
+
+// findWebhook lookup a webhook by id, or by url when id is not provided
+func findWebhook(webhooks []helper.Webhook, id int, url string) (helper.Webhook, error) {
+	if id > 0 {
+		for _, webhook := range webhooks {
+			if webhook.ID == id {
+				return webhook, nil
+			}
+		}
+
+		return helper.Webhook{}, fmt.Errorf("cannot find webhook #%d", id)
+	}
+
+	found := helper.FindWebhooksByURL(webhooks, url)
+	switch len(found) {
+	case 0:
+		return helper.Webhook{}, fmt.Errorf("cannot find any webhook on %s", url)
+	case 1:
+		return found[0], nil
+	}
+
+	return helper.Webhook{}, fmt.Errorf("%d webhooks point at %s, use --id to pick one", len(found), url)
+}
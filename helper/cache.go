@@ -2,12 +2,12 @@
 package helper
 
 import (
-	"encoding/json"
 	"fmt"
-	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/daeMOn63/bitclient"
 )
@@ -15,25 +15,356 @@ import (
 // Cache interface define contract for Cache implementations
 type Cache interface {
 	WriteObject(namespace string, object interface{}) error
+	ReadObject(namespace string, object interface{}) error
 	Clear(namespace string) error
 }
 
-// FileCache is a Cache implementation storing data in a file
+// Cached namespaces, each one having its own fetch time
+const (
+	CacheUsers        = "users"
+	CacheProjects     = "projects"
+	CacheRepositories = "repositories"
+)
+
+// CacheNamespaces list all the cached namespaces
+var CacheNamespaces = []string{CacheUsers, CacheProjects, CacheRepositories}
+
+// cacheMeta is always loaded, it hold what is needed to describe the cache without loading the namespaces
+type cacheMeta struct {
+	Version   int                  `json:"version"`
+	Server    string               `json:"server,omitempty"`
+	User      string               `json:"user,omitempty"`
+	FetchedAt map[string]time.Time `json:"fetchedAt,omitempty"`
+	Counts    map[string]int       `json:"counts,omitempty"`
+	// Projects lists the project keys having cached repositories, each one stored in its own file
+	Projects []string `json:"projects,omitempty"`
+}
+
+// FileCache is a Cache implementation storing each namespace in its own file, and repositories in one file per project.
+// Namespaces are loaded only when accessed. Persisted indexes of the repository slugs, and of the user slugs, emails
+// and display names, allow lookups loading only the matching files.
 type FileCache struct {
-	cacheDir     string
-	Users        []bitclient.User
-	Projects     []bitclient.Project
-	Repositories []bitclient.Repository
+	cacheDir string
+	ttl      time.Duration
+	quiet    bool
+	warned   map[string]bool
+	meta     cacheMeta
+
+	users          []bitclient.User
+	usersLoaded    bool
+	projects       []bitclient.Project
+	projectsLoaded bool
+	// repositories hold the loaded repositories by project key
+	repositories map[string][]bitclient.Repository
+
+	inventory cacheInventory
+
+	// dirty lists the namespaces to write on Save
+	dirty map[string]bool
+
+	// userShards hold the loaded users by shard, userIndex locate them without loading every shard
+	userShards map[int][]bitclient.User
+	userIndex  *userIndex
+	// slugIndex hold the project keys of every repository slug, loaded on first lookup
+	slugIndex       map[string][]string
+	slugIndexLoaded bool
 }
 
-// FindRepositoriesBySlug lookup for given repository slug in cached repositories
-func (c *FileCache) FindRepositoriesBySlug(slug string) []bitclient.Repository {
+// SetTTL define how long the cached data are considered fresh, 0 meaning forever
+func (c *FileCache) SetTTL(ttl time.Duration) {
+	c.ttl = ttl
+}
+
+// TTL give how long the cached data are considered fresh, 0 meaning forever
+func (c *FileCache) TTL() time.Duration {
+	return c.ttl
+}
+
+// SetQuiet disable the warnings printed on stderr (ie: during autocompletion)
+func (c *FileCache) SetQuiet(quiet bool) {
+	c.quiet = quiet
+}
+
+// SetIdentity record the server and user the cached data belong to
+func (c *FileCache) SetIdentity(server string, user string) {
+	if c.meta.Server != server || c.meta.User != user {
+		c.meta.Server = server
+		c.meta.User = user
+		c.markDirty("")
+	}
+}
+
+// Server give the url of the server the cached data belong to
+func (c *FileCache) Server() string {
+	return c.meta.Server
+}
 
+// User give the name of the user the cached data belong to
+func (c *FileCache) User() string {
+	return c.meta.User
+}
+
+// Count give the number of cached entities of namespace, without loading it
+func (c *FileCache) Count(namespace string) int {
+	return c.meta.Counts[namespace]
+}
+
+// Touch record that namespace has just been fetched from the server
+func (c *FileCache) Touch(namespace string) {
+	if c.meta.FetchedAt == nil {
+		c.meta.FetchedAt = make(map[string]time.Time)
+	}
+
+	c.meta.FetchedAt[namespace] = time.Now()
+	c.markDirty("")
+}
+
+// FetchedAt give when namespace was fetched, false when it never was
+func (c *FileCache) FetchedAt(namespace string) (time.Time, bool) {
+	fetchedAt, ok := c.meta.FetchedAt[namespace]
+
+	return fetchedAt, ok
+}
+
+// Age give the time elapsed since namespace was fetched, false when it never was
+func (c *FileCache) Age(namespace string) (time.Duration, bool) {
+	fetchedAt, ok := c.FetchedAt(namespace)
+	if !ok {
+		return 0, false
+	}
+
+	return time.Since(fetchedAt), true
+}
+
+// IsStale tells if namespace was never fetched or is older than the TTL
+func (c *FileCache) IsStale(namespace string) bool {
+	age, ok := c.Age(namespace)
+	if !ok {
+		return true
+	}
+
+	return c.ttl > 0 && age > c.ttl
+}
+
+// IsExpired tells if any namespace is stale
+func (c *FileCache) IsExpired() bool {
+	for _, namespace := range CacheNamespaces {
+		if c.IsStale(namespace) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// warnIfStale print a warning on stderr, once per namespace, when a lookup rely on data older than the TTL.
+// Never fetched namespaces are not reported, commands fallback on the api in that case.
+func (c *FileCache) warnIfStale(namespace string) {
+	age, ok := c.Age(namespace)
+	if c.quiet || !ok || c.warned[namespace] || !c.IsStale(namespace) {
+		return
+	}
+
+	if c.warned == nil {
+		c.warned = make(map[string]bool)
+	}
+	c.warned[namespace] = true
+
+	fmt.Fprintf(os.Stderr, "[WARN] cached %s are %s old, run: bitadmin cache refresh --%s\n", namespace, age.Round(time.Minute), namespace)
+}
+
+// warnLoadError report a namespace which cannot be read. Commands keep going as if it was empty.
+func (c *FileCache) warnLoadError(namespace string, err error) {
+	if c.quiet || os.IsNotExist(err) {
+		return
+	}
+
+	fmt.Fprintf(os.Stderr, "[WARN] cannot load cached %s: %s\n", namespace, err)
+}
+
+// markDirty flag namespace to be written on Save, an empty namespace standing for the metadata only
+func (c *FileCache) markDirty(namespace string) {
+	if c.dirty == nil {
+		c.dirty = make(map[string]bool)
+	}
+
+	c.dirty[namespace] = true
+}
+
+// Projects give all the cached projects
+func (c *FileCache) Projects() []bitclient.Project {
+	if !c.projectsLoaded {
+		c.projectsLoaded = true
+		if err := c.ReadObject(CacheProjects, &c.projects); err != nil {
+			c.warnLoadError(CacheProjects, err)
+		}
+	}
+
+	return c.projects
+}
+
+// SetProjects replace all the cached projects
+func (c *FileCache) SetProjects(projects []bitclient.Project) {
+	c.projects = projects
+	c.projectsLoaded = true
+	c.markDirty(CacheProjects)
+}
+
+// repositoriesNamespace give the namespace holding the repositories of given project
+func repositoriesNamespace(projectKey string) string {
+	return CacheRepositories + "/" + projectKey
+}
+
+// ProjectRepositories give the cached repositories of given project, loading only this project file
+func (c *FileCache) ProjectRepositories(projectKey string) []bitclient.Repository {
+	if c.repositories == nil {
+		c.repositories = make(map[string][]bitclient.Repository)
+	}
+
+	repositories, ok := c.repositories[projectKey]
+	if !ok {
+		if err := c.ReadObject(repositoriesNamespace(projectKey), &repositories); err != nil {
+			c.warnLoadError(repositoriesNamespace(projectKey), err)
+		}
+		c.repositories[projectKey] = repositories
+	}
+
+	return repositories
+}
+
+// Repositories give all the cached repositories, sorted by project
+func (c *FileCache) Repositories() []bitclient.Repository {
 	var repositories []bitclient.Repository
+	for _, projectKey := range c.meta.Projects {
+		repositories = append(repositories, c.ProjectRepositories(projectKey)...)
+	}
+
+	return repositories
+}
 
-	for _, repo := range c.Repositories {
-		if repo.Slug == slug {
-			repositories = append(repositories, repo)
+// SetRepositories replace all the cached repositories
+func (c *FileCache) SetRepositories(repositories []bitclient.Repository) {
+	for _, projectKey := range c.meta.Projects {
+		c.SetProjectRepositories(projectKey, nil)
+	}
+
+	byProject := make(map[string][]bitclient.Repository)
+	for _, repository := range repositories {
+		byProject[repository.Project.Key] = append(byProject[repository.Project.Key], repository)
+	}
+
+	for projectKey, projectRepositories := range byProject {
+		c.SetProjectRepositories(projectKey, projectRepositories)
+	}
+}
+
+// SetProjectRepositories replace all the cached repositories of given project
+func (c *FileCache) SetProjectRepositories(projectKey string, repositories []bitclient.Repository) {
+	if c.repositories == nil {
+		c.repositories = make(map[string][]bitclient.Repository)
+	}
+
+	c.repositories[projectKey] = repositories
+	c.markDirty(repositoriesNamespace(projectKey))
+	c.indexProjectRepositories(projectKey, repositories)
+
+	known := false
+	for _, cached := range c.meta.Projects {
+		known = known || cached == projectKey
+	}
+	if !known {
+		c.meta.Projects = append(c.meta.Projects, projectKey)
+		sort.Strings(c.meta.Projects)
+	}
+}
+
+// ReplaceRepository replace the cached repository projectKey/repoSlug by repository, or add it when not cached
+func (c *FileCache) ReplaceRepository(projectKey string, repoSlug string, repository bitclient.Repository) {
+	c.removeRepository(projectKey, repoSlug)
+
+	targetKey := repository.Project.Key
+	if len(targetKey) == 0 {
+		targetKey = projectKey
+	}
+	c.removeRepository(targetKey, repository.Slug)
+
+	c.SetProjectRepositories(targetKey, append(c.ProjectRepositories(targetKey), repository))
+}
+
+// removeRepository remove the cached repository projectKey/repoSlug, if any
+func (c *FileCache) removeRepository(projectKey string, repoSlug string) {
+	repositories := c.ProjectRepositories(projectKey)
+	for i, repo := range repositories {
+		if repo.Slug == repoSlug {
+			kept := append([]bitclient.Repository{}, repositories[:i]...)
+			c.SetProjectRepositories(projectKey, append(kept, repositories[i+1:]...))
+			return
+		}
+	}
+}
+
+// loadSlugIndex read the slug index on first use. A cache written before the index existed is indexed once,
+// loading every project.
+func (c *FileCache) loadSlugIndex() {
+	if c.slugIndexLoaded {
+		return
+	}
+	c.slugIndexLoaded = true
+
+	err := c.ReadObject(slugIndexNamespace, &c.slugIndex)
+	if err == nil {
+		return
+	}
+	c.warnLoadError(slugIndexNamespace, err)
+
+	c.slugIndex = make(map[string][]string)
+	for _, projectKey := range c.meta.Projects {
+		c.indexProjectRepositories(projectKey, c.ProjectRepositories(projectKey))
+	}
+}
+
+// indexProjectRepositories replace the slugs indexed for projectKey by the ones of repositories
+func (c *FileCache) indexProjectRepositories(projectKey string, repositories []bitclient.Repository) {
+	c.loadSlugIndex()
+	if c.slugIndex == nil {
+		c.slugIndex = make(map[string][]string)
+	}
+
+	for slug, projectKeys := range c.slugIndex {
+		var kept []string
+		for _, key := range projectKeys {
+			if key != projectKey {
+				kept = append(kept, key)
+			}
+		}
+
+		if len(kept) == 0 {
+			delete(c.slugIndex, slug)
+		} else {
+			c.slugIndex[slug] = kept
+		}
+	}
+
+	for _, repository := range repositories {
+		c.slugIndex[repository.Slug] = append(c.slugIndex[repository.Slug], projectKey)
+		sort.Strings(c.slugIndex[repository.Slug])
+	}
+
+	c.markDirty(slugIndexNamespace)
+}
+
+// FindRepositoriesBySlug lookup for given repository slug in cached repositories, loading only the projects
+// holding this slug
+func (c *FileCache) FindRepositoriesBySlug(slug string) []bitclient.Repository {
+	c.warnIfStale(CacheRepositories)
+	c.loadSlugIndex()
+
+	var repositories []bitclient.Repository
+	for _, projectKey := range c.slugIndex[slug] {
+		for _, repo := range c.ProjectRepositories(projectKey) {
+			if repo.Slug == slug {
+				repositories = append(repositories, repo)
+			}
 		}
 	}
 
@@ -42,10 +373,10 @@ func (c *FileCache) FindRepositoriesBySlug(slug string) []bitclient.Repository {
 
 // FindRepository lookup for a repository from slug and projectKey
 func (c *FileCache) FindRepository(projectKey string, repoSlug string) (bitclient.Repository, error) {
-	repos := c.FindRepositoriesBySlug(repoSlug)
+	c.warnIfStale(CacheRepositories)
 
-	for _, repo := range repos {
-		if repo.Project.Key == projectKey {
+	for _, repo := range c.ProjectRepositories(projectKey) {
+		if repo.Slug == repoSlug {
 			return repo, nil
 		}
 	}
@@ -53,60 +384,134 @@ func (c *FileCache) FindRepository(projectKey string, repoSlug string) (bitclien
 	return bitclient.Repository{}, fmt.Errorf("cannot find repository %s/%s", projectKey, repoSlug)
 }
 
-func (c *FileCache) FindUserByUsername(username string) (bitclient.User, error) {
-	for _, user := range c.Users {
-		if user.Slug == username {
-			return user, nil
-		}
-	}
-
-	return bitclient.User{}, fmt.Errorf("cannot find any user with %s username", username)
+// Dir give the directory holding the cache files
+func (c *FileCache) Dir() string {
+	return c.cacheDir
 }
 
-func (c *FileCache) getCacheFileName() string {
-	return fmt.Sprintf("%s/cache", c.cacheDir)
+// Remove delete the cache directory and everything inside
+func (c *FileCache) Remove() error {
+	return os.RemoveAll(c.cacheDir)
 }
 
-// Save write the cached data to the file
+// Save write the changed namespaces and the metadata
 func (c *FileCache) Save() error {
-	data, _ := json.Marshal(c)
-	filename := c.getCacheFileName()
+	if c.meta.Counts == nil {
+		c.meta.Counts = make(map[string]int)
+	}
 
-	os.Mkdir(filepath.Dir(filename), 0775)
+	for namespace := range c.dirty {
+		switch {
+		case namespace == CacheUsers:
+			c.meta.Counts[CacheUsers] = len(c.users)
+			if err := c.saveUsers(); err != nil {
+				return err
+			}
+		case namespace == CacheProjects:
+			c.meta.Counts[CacheProjects] = len(c.projects)
+			if err := c.WriteObject(namespace, c.projects); err != nil {
+				return err
+			}
+		case namespace == slugIndexNamespace:
+			if err := c.WriteObject(namespace, c.slugIndex); err != nil {
+				return err
+			}
+		case strings.HasPrefix(namespace, CacheRepositories+"/"):
+			projectKey := strings.TrimPrefix(namespace, CacheRepositories+"/")
+			if err := c.saveProjectRepositories(projectKey); err != nil {
+				return err
+			}
+		default:
+			if err := c.saveInventory(namespace); err != nil {
+				return err
+			}
+		}
+	}
 
-	err := ioutil.WriteFile(filename, data, 0644)
+	// Counting the repositories would load them all, so the count is updated from the written projects only
+	if c.dirty != nil {
+		total := 0
+		for _, projectKey := range c.meta.Projects {
+			if repositories, ok := c.repositories[projectKey]; ok {
+				c.meta.Counts[repositoriesNamespace(projectKey)] = len(repositories)
+			}
+			total += c.meta.Counts[repositoriesNamespace(projectKey)]
+		}
+		c.meta.Counts[CacheRepositories] = total
+	}
+
+	c.meta.Version = cacheStoreVersion
+	c.dirty = nil
 
-	return err
+	return c.WriteObject(metaNamespace, c.meta)
 }
 
-// Clear erase cached data both in memory and file
-func (c *FileCache) Clear() error {
+// saveProjectRepositories write the repositories of a project, removing the project file when it has no repository
+func (c *FileCache) saveProjectRepositories(projectKey string) error {
+	repositories := c.repositories[projectKey]
+	if len(repositories) > 0 {
+		return c.WriteObject(repositoriesNamespace(projectKey), repositories)
+	}
 
-	c.Users = nil
-	c.Projects = nil
-	c.Repositories = nil
+	var projects []string
+	for _, cached := range c.meta.Projects {
+		if cached != projectKey {
+			projects = append(projects, cached)
+		}
+	}
+	c.meta.Projects = projects
+	delete(c.repositories, projectKey)
+	delete(c.meta.Counts, repositoriesNamespace(projectKey))
 
-	return c.Save()
+	return c.Clear(repositoriesNamespace(projectKey))
 }
 
-// Load read file data and set them in memory
-func (c *FileCache) Load() error {
-	data, err := ioutil.ReadFile(c.getCacheFileName())
-	if err == nil {
-		json.Unmarshal(data, c)
+// ClearAll erase cached data both in memory and files
+func (c *FileCache) ClearAll() error {
+	c.users = nil
+	c.usersLoaded = true
+	c.projects = nil
+	c.projectsLoaded = true
+	c.repositories = nil
+	c.userShards = nil
+	c.userIndex = &userIndex{}
+	c.slugIndex = nil
+	c.slugIndexLoaded = true
+	c.dirty = nil
+	c.meta = cacheMeta{Server: c.meta.Server, User: c.meta.User}
+
+	for _, namespace := range []string{CacheUsers, userIndexNamespace, CacheProjects, slugIndexNamespace} {
+		if err := c.Clear(namespace); err != nil {
+			return err
+		}
 	}
 
-	return err
+	for _, dir := range []string{CacheUsers, CacheRepositories} {
+		if err := os.RemoveAll(filepath.Join(c.cacheDir, dir)); err != nil {
+			return err
+		}
+	}
+
+	if err := c.clearInventory(); err != nil {
+		return err
+	}
+
+	return c.Save()
+}
+
+// Load read the cache metadata, namespaces are loaded later on when accessed
+func (c *FileCache) Load() error {
+	return c.ReadObject(metaNamespace, &c.meta)
 }
 
 // String convert cached data to printable strings
 func (c *FileCache) String() string {
 	output := ""
-	for _, user := range c.Users {
+	for _, user := range c.Users() {
 		output += fmt.Sprintf("user %d - %s - %s - %s - %s\n", user.Id, user.EmailAddress, user.Name, user.DisplayName, user.Slug)
 	}
 
-	for _, project := range c.Projects {
+	for _, project := range c.Projects() {
 
 		var projectLinks []string
 		for _, sublinks := range project.Links {
@@ -118,7 +523,7 @@ func (c *FileCache) String() string {
 		output += fmt.Sprintf("project %s - %s - %s\n", project.Key, project.Name, strings.Join(projectLinks, " - "))
 	}
 
-	for _, repo := range c.Repositories {
+	for _, repo := range c.Repositories() {
 		var repoLinks []string
 		for _, sublinks := range repo.Links {
 			for _, link := range sublinks {
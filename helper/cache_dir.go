@@ -0,0 +1,92 @@
+// Package helper provides handy func and struct to be reused in commands
+package helper
+
+import (
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// unsafeCacheKeyChars are replaced in the cache keys, so they can be used as directory names
+var unsafeCacheKeyChars = regexp.MustCompile(`[^A-Za-z0-9@._-]+`)
+
+// DefaultCacheRoot give the directory holding the caches of every server, following the XDG base directory spec
+func DefaultCacheRoot() string {
+	if xdgCacheHome := os.Getenv("XDG_CACHE_HOME"); len(xdgCacheHome) > 0 {
+		return filepath.Join(xdgCacheHome, "bitadmin")
+	}
+
+	if home, err := os.UserHomeDir(); err == nil {
+		return filepath.Join(home, ".cache", "bitadmin")
+	}
+
+	return filepath.Join(os.TempDir(), "bitadmin")
+}
+
+// NormalizeServerURL give a canonical form of serverURL, so http://Stash.server.com/ and http://stash.server.com
+// share the same cache
+func NormalizeServerURL(serverURL string) string {
+	parsed, err := url.Parse(strings.TrimSpace(serverURL))
+	if err != nil || len(parsed.Host) == 0 {
+		return strings.TrimRight(strings.ToLower(strings.TrimSpace(serverURL)), "/")
+	}
+
+	parsed.Scheme = strings.ToLower(parsed.Scheme)
+	parsed.Host = strings.ToLower(parsed.Host)
+	parsed.Path = strings.TrimRight(parsed.Path, "/")
+	parsed.User = nil
+	parsed.RawQuery = ""
+	parsed.Fragment = ""
+
+	return parsed.String()
+}
+
+// CacheKey give the cache directory name of a server and user (ie: admin@stash.server.com)
+func CacheKey(serverURL string, username string) string {
+	server := NormalizeServerURL(serverURL)
+	if parsed, err := url.Parse(server); err == nil && len(parsed.Host) > 0 {
+		server = parsed.Host + parsed.Path
+	}
+
+	key := username + "@" + server
+
+	return strings.Trim(unsafeCacheKeyChars.ReplaceAllString(key, "_"), "_")
+}
+
+// ListFileCaches load the caches of every server and user found in root
+func ListFileCaches(root string) ([]*FileCache, error) {
+	entries, err := ioutil.ReadDir(root)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var caches []*FileCache
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		cache := NewFileCache(filepath.Join(root, entry.Name()))
+		if err := cache.Load(); err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+
+		caches = append(caches, cache)
+	}
+
+	sort.Slice(caches, func(i, j int) bool {
+		return caches[i].Dir() < caches[j].Dir()
+	})
+
+	return caches, nil
+}
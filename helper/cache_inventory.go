@@ -0,0 +1,227 @@
+// Package helper provides handy func and struct to be reused in commands
+package helper
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/daeMOn63/bitclient"
+)
+
+// Optional cached namespaces, only fetched on demand as they require many api calls
+const (
+	CacheGroups      = "groups"
+	CacheHooks       = "hooks"
+	CacheBranches    = "branches"
+	CachePermissions = "permissions"
+)
+
+// OptionalCacheNamespaces list the namespaces not fetched by a default refresh
+var OptionalCacheNamespaces = []string{CacheGroups, CacheHooks, CacheBranches, CachePermissions}
+
+// RepositoryBranches hold the branches of a repository
+type RepositoryBranches struct {
+	DefaultBranch string   `json:"defaultBranch"`
+	Branches      []string `json:"branches"`
+}
+
+// cacheInventory hold the optional namespaces, loaded on first access
+type cacheInventory struct {
+	groups       []string
+	groupsLoaded bool
+	hooks        []HookDetails
+	hooksLoaded  bool
+	// branches and permissions are stored per project, then by repository slug. The project level permissions use an
+	// empty slug.
+	branches    map[string]map[string]RepositoryBranches
+	permissions map[string]map[string]PermissionSnapshot
+}
+
+// Groups give all the cached group names
+func (c *FileCache) Groups() []string {
+	if !c.inventory.groupsLoaded {
+		c.inventory.groupsLoaded = true
+		if err := c.ReadObject(CacheGroups, &c.inventory.groups); err != nil {
+			c.warnLoadError(CacheGroups, err)
+		}
+	}
+
+	return c.inventory.groups
+}
+
+// SetGroups replace all the cached group names
+func (c *FileCache) SetGroups(groups []string) {
+	sort.Strings(groups)
+	c.inventory.groups = groups
+	c.inventory.groupsLoaded = true
+	c.markDirty(CacheGroups)
+}
+
+// Hooks give the catalogue of installed hooks
+func (c *FileCache) Hooks() []HookDetails {
+	if !c.inventory.hooksLoaded {
+		c.inventory.hooksLoaded = true
+		if err := c.ReadObject(CacheHooks, &c.inventory.hooks); err != nil {
+			c.warnLoadError(CacheHooks, err)
+		}
+	}
+
+	return c.inventory.hooks
+}
+
+// SetHooks replace the catalogue of installed hooks
+func (c *FileCache) SetHooks(hooks []HookDetails) {
+	c.inventory.hooks = hooks
+	c.inventory.hooksLoaded = true
+	c.markDirty(CacheHooks)
+}
+
+// ProjectBranches give the cached branches of every repository of given project, by repository slug
+func (c *FileCache) ProjectBranches(projectKey string) map[string]RepositoryBranches {
+	if c.inventory.branches == nil {
+		c.inventory.branches = make(map[string]map[string]RepositoryBranches)
+	}
+
+	branches, ok := c.inventory.branches[projectKey]
+	if !ok {
+		namespace := CacheBranches + "/" + projectKey
+		if err := c.ReadObject(namespace, &branches); err != nil {
+			c.warnLoadError(namespace, err)
+		}
+		c.inventory.branches[projectKey] = branches
+	}
+
+	return branches
+}
+
+// SetProjectBranches replace the cached branches of the repositories of given project
+func (c *FileCache) SetProjectBranches(projectKey string, branches map[string]RepositoryBranches) {
+	if c.inventory.branches == nil {
+		c.inventory.branches = make(map[string]map[string]RepositoryBranches)
+	}
+
+	c.inventory.branches[projectKey] = branches
+	c.markDirty(CacheBranches + "/" + projectKey)
+}
+
+// ProjectPermissions give the cached permission snapshots of given project, by repository slug.
+// The project permissions are stored with an empty slug.
+func (c *FileCache) ProjectPermissions(projectKey string) map[string]PermissionSnapshot {
+	if c.inventory.permissions == nil {
+		c.inventory.permissions = make(map[string]map[string]PermissionSnapshot)
+	}
+
+	permissions, ok := c.inventory.permissions[projectKey]
+	if !ok {
+		namespace := CachePermissions + "/" + projectKey
+		if err := c.ReadObject(namespace, &permissions); err != nil {
+			c.warnLoadError(namespace, err)
+		}
+		c.inventory.permissions[projectKey] = permissions
+	}
+
+	return permissions
+}
+
+// SetProjectPermissions replace the cached permission snapshots of given project
+func (c *FileCache) SetProjectPermissions(projectKey string, permissions map[string]PermissionSnapshot) {
+	if c.inventory.permissions == nil {
+		c.inventory.permissions = make(map[string]map[string]PermissionSnapshot)
+	}
+
+	c.inventory.permissions[projectKey] = permissions
+	c.markDirty(CachePermissions + "/" + projectKey)
+}
+
+// UpdateGroupPermission change the permission of a group in the cached snapshot of the repository, or of the project
+// when repositorySlug is empty. An empty permission remove the group. Snapshots not cached are left to the next refresh.
+func (c *FileCache) UpdateGroupPermission(projectKey string, repositorySlug string, group string, permission string) {
+	permissions := c.ProjectPermissions(projectKey)
+	snapshot, ok := permissions[repositorySlug]
+	if !ok {
+		return
+	}
+
+	var groups []bitclient.GroupPermission
+	for _, groupPermission := range snapshot.Groups {
+		if groupPermission.Group.Name != group {
+			groups = append(groups, groupPermission)
+		}
+	}
+	if len(permission) > 0 {
+		groups = append(groups, bitclient.GroupPermission{Group: bitclient.Group{Name: group}, Permission: permission})
+	}
+
+	snapshot.Groups = groups
+	permissions[repositorySlug] = snapshot
+	c.SetProjectPermissions(projectKey, permissions)
+}
+
+// UpdateUserPermission change the permission of a user in the cached snapshot of the repository, or of the project
+// when repositorySlug is empty. An empty permission remove the user. Snapshots not cached are left to the next refresh.
+func (c *FileCache) UpdateUserPermission(projectKey string, repositorySlug string, username string, permission string) {
+	permissions := c.ProjectPermissions(projectKey)
+	snapshot, ok := permissions[repositorySlug]
+	if !ok {
+		return
+	}
+
+	var users []bitclient.UserPermission
+	for _, userPermission := range snapshot.Users {
+		if userPermission.User.Name != username {
+			users = append(users, userPermission)
+		}
+	}
+	if len(permission) > 0 {
+		user, err := c.FindUserByUsername(username)
+		if err != nil {
+			user = bitclient.User{Name: username, Slug: username}
+		}
+		users = append(users, bitclient.UserPermission{User: user, Permission: permission})
+	}
+
+	snapshot.Users = users
+	permissions[repositorySlug] = snapshot
+	c.SetProjectPermissions(projectKey, permissions)
+}
+
+// saveInventory write a changed optional namespace, other namespaces are ignored
+func (c *FileCache) saveInventory(namespace string) error {
+	switch {
+	case namespace == CacheGroups:
+		c.meta.Counts[CacheGroups] = len(c.inventory.groups)
+		return c.WriteObject(namespace, c.inventory.groups)
+	case namespace == CacheHooks:
+		c.meta.Counts[CacheHooks] = len(c.inventory.hooks)
+		return c.WriteObject(namespace, c.inventory.hooks)
+	case strings.HasPrefix(namespace, CacheBranches+"/"):
+		projectKey := strings.TrimPrefix(namespace, CacheBranches+"/")
+		return c.WriteObject(namespace, c.inventory.branches[projectKey])
+	case strings.HasPrefix(namespace, CachePermissions+"/"):
+		projectKey := strings.TrimPrefix(namespace, CachePermissions+"/")
+		return c.WriteObject(namespace, c.inventory.permissions[projectKey])
+	}
+
+	return nil
+}
+
+// clearInventory erase the optional namespaces both in memory and files
+func (c *FileCache) clearInventory() error {
+	c.inventory = cacheInventory{groupsLoaded: true, hooksLoaded: true}
+
+	for _, namespace := range []string{CacheGroups, CacheHooks} {
+		if err := c.Clear(namespace); err != nil {
+			return err
+		}
+	}
+
+	for _, dir := range []string{CacheBranches, CachePermissions} {
+		if err := os.RemoveAll(filepath.Join(c.cacheDir, dir)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
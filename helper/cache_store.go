@@ -0,0 +1,130 @@
+// Package helper provides handy func and struct to be reused in commands
+package helper
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/daeMOn63/bitclient"
+)
+
+// cacheStoreVersion is the version of the cache directory layout
+const cacheStoreVersion = 2
+
+// metaNamespace hold the cache metadata: server, user, fetch times and entries count
+const metaNamespace = "meta"
+
+// slugIndexNamespace hold the project keys of every cached repository slug, so a slug lookup only load the
+// matching projects
+const slugIndexNamespace = "repositories-index"
+
+// namespaceFile give the path of the file holding namespace. Nested namespaces (ie: repositories/PRJ) are stored
+// in sub directories, each part being escaped.
+func (c *FileCache) namespaceFile(namespace string) string {
+	parts := strings.Split(namespace, "/")
+	for i, part := range parts {
+		parts[i] = url.PathEscape(part)
+	}
+
+	return filepath.Join(c.cacheDir, filepath.Join(parts...)+".json")
+}
+
+// WriteObject store object as the content of namespace
+func (c *FileCache) WriteObject(namespace string, object interface{}) error {
+	data, err := json.Marshal(object)
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(c.namespaceFile(namespace), data)
+}
+
+// ReadObject decode the content of namespace in object. A missing namespace return an os.IsNotExist error.
+func (c *FileCache) ReadObject(namespace string, object interface{}) error {
+	data, err := ioutil.ReadFile(c.namespaceFile(namespace))
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, object)
+}
+
+// Clear erase the stored content of namespace
+func (c *FileCache) Clear(namespace string) error {
+	err := os.Remove(c.namespaceFile(namespace))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+// writeFileAtomic write data to a temporary file first and rename it, so readers never see a partially written file.
+// The cache hold user emails and display names, so it is kept private.
+func writeFileAtomic(filename string, data []byte) error {
+	err := os.MkdirAll(filepath.Dir(filename), 0700)
+	if err != nil {
+		return err
+	}
+
+	tmpFile, err := ioutil.TempFile(filepath.Dir(filename), filepath.Base(filename)+".tmp")
+	if err != nil {
+		return err
+	}
+
+	_, err = tmpFile.Write(data)
+	if closeErr := tmpFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmpFile.Name(), 0600)
+	}
+	if err == nil {
+		err = os.Rename(tmpFile.Name(), filename)
+	}
+	if err != nil {
+		_ = os.Remove(tmpFile.Name())
+	}
+
+	return err
+}
+
+// LegacyCacheFile give the single JSON file holding the whole cache, shared by every server, in the released versions
+func LegacyCacheFile() string {
+	return filepath.Join(os.TempDir(), "bitadmin", "cache")
+}
+
+// legacyCache is the content of the legacy cache file
+type legacyCache struct {
+	Users        []bitclient.User
+	Projects     []bitclient.Project
+	Repositories []bitclient.Repository
+}
+
+// MigrateLegacyCache import the legacy cache file in this cache, then remove it. The legacy file does not record
+// which server it was fetched from, so the imported namespaces are left unfetched and refreshed on the next run.
+func (c *FileCache) MigrateLegacyCache(legacyFile string) error {
+	data, err := ioutil.ReadFile(legacyFile)
+	if err != nil {
+		return err
+	}
+
+	legacy := legacyCache{}
+	if err := json.Unmarshal(data, &legacy); err != nil {
+		return err
+	}
+
+	c.SetUsers(legacy.Users)
+	c.SetProjects(legacy.Projects)
+	c.SetRepositories(legacy.Repositories)
+
+	if err := c.Save(); err != nil {
+		return err
+	}
+
+	return os.Remove(legacyFile)
+}
@@ -0,0 +1,199 @@
+// Package helper provides handy func and struct to be reused in commands
+package helper
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strings"
+
+	"github.com/daeMOn63/bitclient"
+)
+
+// userShardCount is the number of files the users are spread over, so a lookup only decode a small part of them
+const userShardCount = 64
+
+// userIndexNamespace hold the userIndex
+const userIndexNamespace = "users-index"
+
+// userIndex locate the cached users: the shard of each slug, and the slugs of each email and display name, lower cased
+type userIndex struct {
+	Slugs        map[string]int      `json:"slugs"`
+	Emails       map[string][]string `json:"emails"`
+	DisplayNames map[string][]string `json:"displayNames"`
+}
+
+// userShard give the shard holding the user slug
+func userShard(slug string) int {
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(slug))
+
+	return int(hash.Sum32() % userShardCount)
+}
+
+// userShardNamespace give the namespace holding the users of shard
+func userShardNamespace(shard int) string {
+	return fmt.Sprintf("%s/%02d", CacheUsers, shard)
+}
+
+// newUserIndex index users
+func newUserIndex(users []bitclient.User) *userIndex {
+	index := &userIndex{
+		Slugs:        make(map[string]int),
+		Emails:       make(map[string][]string),
+		DisplayNames: make(map[string][]string),
+	}
+
+	for _, user := range users {
+		index.Slugs[user.Slug] = userShard(user.Slug)
+
+		if email := strings.ToLower(user.EmailAddress); len(email) > 0 {
+			index.Emails[email] = append(index.Emails[email], user.Slug)
+		}
+		if displayName := strings.ToLower(user.DisplayName); len(displayName) > 0 {
+			index.DisplayNames[displayName] = append(index.DisplayNames[displayName], user.Slug)
+		}
+	}
+
+	return index
+}
+
+// loadUserIndex read the user index on first use. Caches written before the users were sharded hold them in a
+// single file, which is read once and sharded on the next save.
+func (c *FileCache) loadUserIndex() {
+	if c.userIndex != nil {
+		return
+	}
+
+	index := &userIndex{}
+	err := c.ReadObject(userIndexNamespace, index)
+	if err == nil {
+		c.userIndex = index
+		return
+	}
+	c.warnLoadError(userIndexNamespace, err)
+
+	var users []bitclient.User
+	if err := c.ReadObject(CacheUsers, &users); err == nil {
+		c.SetUsers(users)
+		return
+	}
+
+	c.userIndex = newUserIndex(nil)
+}
+
+// shardUsers give the users of shard, loading only this shard file
+func (c *FileCache) shardUsers(shard int) []bitclient.User {
+	if c.userShards == nil {
+		c.userShards = make(map[int][]bitclient.User)
+	}
+
+	users, ok := c.userShards[shard]
+	if !ok {
+		if err := c.ReadObject(userShardNamespace(shard), &users); err != nil {
+			c.warnLoadError(userShardNamespace(shard), err)
+		}
+		c.userShards[shard] = users
+	}
+
+	return users
+}
+
+// Users give all the cached users, sorted by slug
+func (c *FileCache) Users() []bitclient.User {
+	c.loadUserIndex()
+
+	if !c.usersLoaded {
+		c.usersLoaded = true
+		for shard := 0; shard < userShardCount; shard++ {
+			c.users = append(c.users, c.shardUsers(shard)...)
+		}
+		sort.Slice(c.users, func(i, j int) bool {
+			return c.users[i].Slug < c.users[j].Slug
+		})
+	}
+
+	return c.users
+}
+
+// SetUsers replace all the cached users
+func (c *FileCache) SetUsers(users []bitclient.User) {
+	c.users = users
+	c.usersLoaded = true
+	c.userIndex = newUserIndex(users)
+
+	c.userShards = make(map[int][]bitclient.User)
+	for _, user := range users {
+		shard := userShard(user.Slug)
+		c.userShards[shard] = append(c.userShards[shard], user)
+	}
+
+	c.markDirty(CacheUsers)
+}
+
+// saveUsers write every shard and the user index, then drop the single file of the previous layout
+func (c *FileCache) saveUsers() error {
+	for shard := 0; shard < userShardCount; shard++ {
+		var err error
+		if users := c.userShards[shard]; len(users) > 0 {
+			err = c.WriteObject(userShardNamespace(shard), users)
+		} else {
+			err = c.Clear(userShardNamespace(shard))
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := c.WriteObject(userIndexNamespace, c.userIndex); err != nil {
+		return err
+	}
+
+	return c.Clear(CacheUsers)
+}
+
+// findUsers give the indexed users having one of slugs
+func (c *FileCache) findUsers(slugs []string) []bitclient.User {
+	var users []bitclient.User
+	for _, slug := range slugs {
+		if user, err := c.FindUserByUsername(slug); err == nil {
+			users = append(users, user)
+		}
+	}
+
+	return users
+}
+
+// FindUserByUsername lookup for a user from its slug, loading only the shard holding it
+func (c *FileCache) FindUserByUsername(username string) (bitclient.User, error) {
+	c.warnIfStale(CacheUsers)
+	c.loadUserIndex()
+
+	if shard, ok := c.userIndex.Slugs[username]; ok {
+		for _, user := range c.shardUsers(shard) {
+			if user.Slug == username {
+				return user, nil
+			}
+		}
+	}
+
+	return bitclient.User{}, fmt.Errorf("cannot find any user with %s username", username)
+}
+
+// FindUserByEmail lookup for a user from its email address, case insensitive
+func (c *FileCache) FindUserByEmail(email string) (bitclient.User, error) {
+	c.loadUserIndex()
+
+	if users := c.findUsers(c.userIndex.Emails[strings.ToLower(email)]); len(users) > 0 {
+		return users[0], nil
+	}
+
+	return bitclient.User{}, fmt.Errorf("cannot find any user with %s email", email)
+}
+
+// FindUsersByDisplayName lookup for the users having given display name, case insensitive
+func (c *FileCache) FindUsersByDisplayName(displayName string) []bitclient.User {
+	c.loadUserIndex()
+
+	return c.findUsers(c.userIndex.DisplayNames[strings.ToLower(displayName)])
+}
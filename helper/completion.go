@@ -0,0 +1,106 @@
+// Package helper provides handy func and struct to be reused in commands
+package helper
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/urfave/cli"
+)
+
+// CompletionShellEnv is set by the generated completion scripts to the name of the shell requesting candidates
+const CompletionShellEnv = "BITADMIN_COMPLETION"
+
+// completionFlag is appended by the shells to the typed arguments to request the completion candidates
+const completionFlag = "--generate-bash-completion"
+
+// completionDescriptions tells if the requesting shell display descriptions next to the candidates
+func completionDescriptions() bool {
+	switch os.Getenv(CompletionShellEnv) {
+	case "zsh", "fish", "powershell":
+		return true
+	}
+
+	return false
+}
+
+// PrintCompletion output a completion candidate, one per line. The description is appended after a tab
+// when the requesting shell support it.
+func PrintCompletion(w io.Writer, value, description string) {
+	description = strings.Join(strings.Fields(description), " ")
+	if len(description) > 0 && completionDescriptions() {
+		fmt.Fprintf(w, "%s\t%s\n", value, description)
+		return
+	}
+
+	fmt.Fprintln(w, value)
+}
+
+// printFlagCompletion output the names of flag, prefixed with - or --, with its usage as description
+func printFlagCompletion(w io.Writer, flag cli.Flag) {
+	for _, name := range strings.Split(flag.GetName(), ",") {
+		name = strings.TrimSpace(name)
+		if len(name) == 0 {
+			continue
+		}
+
+		prefix := "--"
+		if len(name) == 1 {
+			prefix = "-"
+		}
+		PrintCompletion(w, prefix+name, flagUsage(flag))
+	}
+}
+
+// flagUsage give the usage of flag, without the placeholder quotes
+func flagUsage(flag cli.Flag) string {
+	var usage string
+	switch f := flag.(type) {
+	case cli.StringFlag:
+		usage = f.Usage
+	case cli.BoolFlag:
+		usage = f.Usage
+	case cli.BoolTFlag:
+		usage = f.Usage
+	case cli.IntFlag:
+		usage = f.Usage
+	case cli.UintFlag:
+		usage = f.Usage
+	case cli.DurationFlag:
+		usage = f.Usage
+	case cli.StringSliceFlag:
+		usage = f.Usage
+	case cli.GenericFlag:
+		usage = f.Usage
+	}
+
+	return strings.Replace(usage, "`", "", -1)
+}
+
+// CommandAutoComplete print the visible commands of the current app, with their usage as description.
+// It replace cli.DefaultAppComplete which only print the names.
+func CommandAutoComplete(c *cli.Context) {
+	for _, command := range c.App.Commands {
+		if command.Hidden {
+			continue
+		}
+		for _, name := range command.Names() {
+			PrintCompletion(c.App.Writer, name, command.Usage)
+		}
+	}
+}
+
+// EnableCommandAutoComplete set CommandAutoComplete on every command having subcommands and no completion of its own
+func EnableCommandAutoComplete(commands []cli.Command) {
+	for i := range commands {
+		if len(commands[i].Subcommands) == 0 {
+			continue
+		}
+		if commands[i].BashComplete == nil {
+			commands[i].BashComplete = CommandAutoComplete
+		}
+		EnableCommandAutoComplete(commands[i].Subcommands)
+	}
+}
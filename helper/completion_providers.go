@@ -0,0 +1,283 @@
+// Package helper provides handy func and struct to be reused in commands
+package helper
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/urfave/cli"
+)
+
+// Completion is a completion candidate, with an optional description
+type Completion struct {
+	Value       string
+	Description string
+}
+
+// CompletionFlags hold the values of the flags already typed on the command line, by flag name
+type CompletionFlags map[string][]string
+
+// Get give the last value of the flag name, or an empty string when it has not been typed
+func (f CompletionFlags) Get(name string) string {
+	values := f[name]
+	if len(values) == 0 {
+		return ""
+	}
+
+	return values[len(values)-1]
+}
+
+// CompletionProvider give the candidates of a flag value, depending on the other flags already typed.
+// cache is nil when no cache can be selected.
+type CompletionProvider func(cache *FileCache, flags CompletionFlags) []Completion
+
+// CompletionProviders map the flag names to the provider of their values
+type CompletionProviders map[string]CompletionProvider
+
+// defaultCompletionProviders are used for the flags a command does not declare a provider for
+var defaultCompletionProviders = CompletionProviders{
+	"project":          ProjectValues("repository"),
+	"sourceProject":    ProjectValues("sourceRepository"),
+	"targetProject":    ProjectValues("targetRepository"),
+	"repository":       RepositoryValues("project"),
+	"sourceRepository": RepositoryValues("sourceProject"),
+	"targetRepository": RepositoryValues("targetProject"),
+	"user":             UserValues(),
+	"username":         UserValues(),
+	"permission":       StaticValues("REPO_READ", "REPO_WRITE", "REPO_ADMIN"),
+	"restriction":      StaticValues("read-only", "no-deletes", "fast-forward-only", "pull-request-only"),
+	"branchRef":        BranchValues("project", "repository"),
+	"group":            GroupValues(),
+	"key":              HookValues(),
+}
+
+// StaticValues provide a fixed list of values
+func StaticValues(values ...string) CompletionProvider {
+	return func(cache *FileCache, flags CompletionFlags) []Completion {
+		var completions []Completion
+		for _, value := range values {
+			completions = append(completions, Completion{Value: value})
+		}
+
+		return completions
+	}
+}
+
+// UserValues provide the cached user slugs, described by their display name
+func UserValues() CompletionProvider {
+	return func(cache *FileCache, flags CompletionFlags) []Completion {
+		if cache == nil {
+			return nil
+		}
+
+		var completions []Completion
+		for _, user := range cache.Users() {
+			completions = append(completions, Completion{Value: user.Slug, Description: user.DisplayName})
+		}
+
+		return completions
+	}
+}
+
+// GroupValues provide the cached group names
+func GroupValues() CompletionProvider {
+	return func(cache *FileCache, flags CompletionFlags) []Completion {
+		if cache == nil {
+			return nil
+		}
+
+		var completions []Completion
+		for _, group := range cache.Groups() {
+			completions = append(completions, Completion{Value: group})
+		}
+
+		return completions
+	}
+}
+
+// HookValues provide the cached hook keys, described by their name
+func HookValues() CompletionProvider {
+	return func(cache *FileCache, flags CompletionFlags) []Completion {
+		if cache == nil {
+			return nil
+		}
+
+		var completions []Completion
+		for _, hook := range cache.Hooks() {
+			completions = append(completions, Completion{Value: hook.Key, Description: hook.Name})
+		}
+
+		return completions
+	}
+}
+
+// ProjectValues provide the cached project keys. When the repositoryFlag has been typed, only the projects holding
+// a repository with this slug are given. An empty repositoryFlag give every project.
+func ProjectValues(repositoryFlag string) CompletionProvider {
+	return func(cache *FileCache, flags CompletionFlags) []Completion {
+		if cache == nil {
+			return nil
+		}
+
+		var completions []Completion
+
+		if repositorySlug := flags.Get(repositoryFlag); len(repositoryFlag) > 0 && len(repositorySlug) > 0 {
+			for _, repository := range cache.FindRepositoriesBySlug(repositorySlug) {
+				completions = append(completions, Completion{Value: repository.Project.Key, Description: repository.Project.Name})
+			}
+			return completions
+		}
+
+		for _, project := range cache.Projects() {
+			completions = append(completions, Completion{Value: project.Key, Description: project.Name})
+		}
+
+		return completions
+	}
+}
+
+// RepositoryValues provide the cached repository slugs. When the projectFlag has been typed, only the repositories of
+// this project are given, and only this project is loaded from the cache.
+func RepositoryValues(projectFlag string) CompletionProvider {
+	return func(cache *FileCache, flags CompletionFlags) []Completion {
+		if cache == nil {
+			return nil
+		}
+
+		var completions []Completion
+
+		if projectKey := flags.Get(projectFlag); len(projectFlag) > 0 && len(projectKey) > 0 {
+			for _, repository := range cache.ProjectRepositories(projectKey) {
+				completions = append(completions, Completion{Value: repository.Slug, Description: repository.Name})
+			}
+			return completions
+		}
+
+		for _, repository := range cache.Repositories() {
+			completions = append(completions, Completion{
+				Value:       repository.Slug,
+				Description: repository.Project.Key + " - " + repository.Name,
+			})
+		}
+
+		return completions
+	}
+}
+
+// BranchValues provide the cached branches of the repository from repositoryFlag, or of every repository of the project
+// from projectFlag when the repository is unknown. Default branches come first. The usual default branches are
+// suggested when nothing is cached.
+func BranchValues(projectFlag, repositoryFlag string) CompletionProvider {
+	return func(cache *FileCache, flags CompletionFlags) []Completion {
+		projectKey := flags.Get(projectFlag)
+		repositorySlug := flags.Get(repositoryFlag)
+
+		var defaults, others []Completion
+		seen := make(map[string]bool)
+
+		if cache != nil && len(projectKey) > 0 {
+			projectBranches := cache.ProjectBranches(projectKey)
+
+			slugs := make([]string, 0, len(projectBranches))
+			for slug := range projectBranches {
+				if len(repositorySlug) == 0 || slug == repositorySlug {
+					slugs = append(slugs, slug)
+				}
+			}
+			sort.Strings(slugs)
+
+			for _, slug := range slugs {
+				branches := projectBranches[slug]
+				for _, branch := range branches.Branches {
+					if seen[branch] {
+						continue
+					}
+					seen[branch] = true
+
+					if branch == branches.DefaultBranch {
+						defaults = append(defaults, Completion{Value: branch, Description: "default branch"})
+					} else {
+						others = append(others, Completion{Value: branch})
+					}
+				}
+			}
+		}
+
+		if len(seen) == 0 {
+			return []Completion{
+				{Value: "refs/heads/master", Description: "branches not cached"},
+				{Value: "refs/heads/main", Description: "branches not cached"},
+			}
+		}
+
+		return append(defaults, others...)
+	}
+}
+
+// parseCompletionFlags read the flag values from args. Values are given as --flag value or --flag=value, flags missing
+// from definitions are expected to hold a value.
+func parseCompletionFlags(args []string, definitions []cli.Flag) CompletionFlags {
+	flags := make(CompletionFlags)
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if len(arg) < 2 || arg[0] != '-' || arg == "--" {
+			continue
+		}
+
+		name := strings.TrimLeft(arg, "-")
+		if parts := strings.SplitN(name, "=", 2); len(parts) == 2 {
+			flags[parts[0]] = append(flags[parts[0]], parts[1])
+			continue
+		}
+
+		if isBoolFlag(findFlag(definitions, name)) {
+			continue
+		}
+
+		if i+1 < len(args) && !strings.HasPrefix(args[i+1], "-") {
+			flags[name] = append(flags[name], args[i+1])
+			i++
+		}
+	}
+
+	return flags
+}
+
+// printProviderCompletion print the candidates of the provider, skipping the values already given to the flag name,
+// so the repeatable flags do not suggest them again
+func printProviderCompletion(c *cli.Context, cache *FileCache, provider CompletionProvider, name string, flags CompletionFlags) {
+	used := make(map[string]bool)
+	for _, value := range flags[name] {
+		used[value] = true
+	}
+
+	for _, completion := range provider(cache, flags) {
+		if !used[completion.Value] {
+			PrintCompletion(c.App.Writer, completion.Value, completion.Description)
+		}
+	}
+}
+
+// findFlag give the flag of definitions matching name, or nil
+func findFlag(definitions []cli.Flag, name string) cli.Flag {
+	for _, flag := range definitions {
+		for _, flagName := range strings.Split(flag.GetName(), ",") {
+			if strings.TrimSpace(flagName) == name {
+				return flag
+			}
+		}
+	}
+
+	return nil
+}
+
+// isBoolFlag tells if flag does not take a value
+func isBoolFlag(flag cli.Flag) bool {
+	switch flag.(type) {
+	case cli.BoolFlag, cli.BoolTFlag:
+		return true
+	}
+
+	return false
+}
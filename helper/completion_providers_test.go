@@ -0,0 +1,56 @@
+package helper
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/urfave/cli"
+)
+
+func TestParseCompletionFlags(t *testing.T) {
+	definitions := []cli.Flag{
+		cli.StringFlag{Name: "project"},
+		cli.StringFlag{Name: "repository, r"},
+		cli.StringSliceFlag{Name: "name"},
+		cli.BoolFlag{Name: "force"},
+		cli.BoolTFlag{Name: "color"},
+	}
+
+	tests := []struct {
+		args  []string
+		flags CompletionFlags
+	}{
+		{[]string{}, CompletionFlags{}},
+		{[]string{"--project", "PRJ"}, CompletionFlags{"project": {"PRJ"}}},
+		{[]string{"--project=PRJ"}, CompletionFlags{"project": {"PRJ"}}},
+		{[]string{"-r", "api"}, CompletionFlags{"r": {"api"}}},
+		{[]string{"--name", "a", "--name=b"}, CompletionFlags{"name": {"a", "b"}}},
+		// Boolean flags never take the next argument
+		{[]string{"--force", "PRJ", "--color", "--project", "PRJ"}, CompletionFlags{"project": {"PRJ"}}},
+		{[]string{"--force=false", "--project", "PRJ"}, CompletionFlags{"force": {"false"}, "project": {"PRJ"}}},
+		// A flag followed by another flag, or last on the line, has no value yet
+		{[]string{"--project", "--repository", "api"}, CompletionFlags{"repository": {"api"}}},
+		{[]string{"--repository", "api", "--project"}, CompletionFlags{"repository": {"api"}}},
+		// Unknown flags are expected to hold a value
+		{[]string{"--url", "http://stash.server.com", "--project", "PRJ"}, CompletionFlags{"url": {"http://stash.server.com"}, "project": {"PRJ"}}},
+		{[]string{"positional", "--", "--project", "PRJ"}, CompletionFlags{"project": {"PRJ"}}},
+		{[]string{"-", "--project", "PRJ"}, CompletionFlags{"project": {"PRJ"}}},
+	}
+
+	for _, test := range tests {
+		if flags := parseCompletionFlags(test.args, definitions); !reflect.DeepEqual(flags, test.flags) {
+			t.Errorf("parseCompletionFlags(%q) = %v, expected %v", test.args, flags, test.flags)
+		}
+	}
+}
+
+func TestCompletionFlagsGet(t *testing.T) {
+	flags := CompletionFlags{"name": {"a", "b"}}
+
+	if value := flags.Get("name"); value != "b" {
+		t.Errorf("Get(name) = %q, expected the last value b", value)
+	}
+	if value := flags.Get("project"); value != "" {
+		t.Errorf("Get(project) = %q, expected an empty string", value)
+	}
+}
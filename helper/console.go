@@ -3,6 +3,7 @@ package helper
 
 import (
 	"fmt"
+	"os"
 	"strings"
 
 	"github.com/daeMOn63/bitclient"
@@ -23,131 +24,86 @@ func PrintLinks(l bitclient.Links) {
 	}
 }
 
-// AppAutoComplete extends the default autocomplete provided by urfave/cli by filtering on flags when - or -- is typed
-func AppAutoComplete(c *cli.Context) {
-	flags := c.GlobalFlagNames()
-	for _, flag := range flags {
-		names := strings.Split(flag, ",")
-		for _, n := range names {
-			n = strings.TrimSpace(n)
-			if len(n) > 0 && !c.GlobalIsSet(n) {
-				s := "--"
-				if len(n) == 1 {
-					s = "-"
-				}
-				fmt.Printf("%s%s ", s, n)
-			}
-		}
+// AppAutoComplete extends the default autocomplete provided by urfave/cli by printing the global flags not set yet,
+// and the commands with their usage. The values of the global flags are completed with the default providers.
+func AppAutoComplete(c *cli.Context, cache *FileCache) {
+	// A global flag missing its value fail the parsing, so the typed arguments are read from os.Args
+	args := os.Args[1:]
+	if len(args) > 0 && args[len(args)-1] == completionFlag {
+		args = args[:len(args)-1]
 	}
 
-	cli.DefaultAppComplete(c)
-}
-
-// AutoComplete enhance the autocompletion by responding to project / user / username / repository... flags and printing
-// available values from the cache.
-// Everything that get printed by this function could be used as autocompletion value. Space is used as separator.
-func AutoComplete(c *cli.Context, cache *FileCache) {
-	args := c.Parent().Args()
-	lastArg := args[len(args)-1]
-
-	switch lastArg {
-	case "--project", "--sourceProject", "--targetProject":
-		autocompleteProject(c, cache)
-	case "--user":
-	case "--username":
-		for _, user := range cache.Users {
-			fmt.Fprintln(c.App.Writer, user.Slug)
-		}
-	case "--repository", "--sourceRepository", "--targetRepository":
-		autocompleteRepository(c, cache)
-	case "--permission":
-		fmt.Println("REPO_READ REPO_WRITE REPO_ADMIN")
-	case "--restriction":
-		fmt.Println("read-only no-deletes fast-forward-only pull-request-only")
-	case "--branchRef":
-		fmt.Println("refs/heads/master")
-	default:
-		if len(lastArg) > 2 && lastArg[:2] == "--" {
-			flag, err := getFlag(c, lastArg[2:])
-			if err == nil {
-				if _, ok := flag.(cli.BoolFlag); ok == false {
-					return
-				}
+	if len(args) > 0 && len(args[len(args)-1]) > 1 && args[len(args)-1][0] == '-' {
+		name := strings.TrimLeft(args[len(args)-1], "-")
+		if flag := findFlag(c.App.Flags, name); flag != nil && !isBoolFlag(flag) {
+			if provider, ok := defaultCompletionProviders[name]; ok {
+				printProviderCompletion(c, cache, provider, name, parseCompletionFlags(args, c.App.Flags))
 			}
+			return
 		}
+	}
 
-		flags := c.Command.Flags
-		for _, flag := range flags {
-			name := flag.GetName()
-			_, isStringSliceFlag := flag.(cli.StringSliceFlag)
-			if !c.IsSet(name) || isStringSliceFlag {
-				s := "--"
-				if len(name) == 1 {
-					s = "-"
-				}
-				fmt.Printf("%s%s ", s, name)
-			}
+	for _, flag := range c.App.Flags {
+		name := strings.TrimSpace(strings.Split(flag.GetName(), ",")[0])
+		if len(name) > 0 && !c.GlobalIsSet(name) {
+			printFlagCompletion(c.App.Writer, flag)
 		}
 	}
 
+	CommandAutoComplete(c)
 }
 
-// autocompleteRepository search if project have been provided in previous arguments and autocomplete with
-// only those repositories.
-// Otherwise it will display the full repository list
-func autocompleteRepository(c *cli.Context, cache *FileCache) {
-
-	var projectKey *string
+// AutoComplete enhance the autocompletion by responding to project / user / username / repository... flags and printing
+// available values from the cache.
+// Everything that get printed by this function could be used as autocompletion value, one per line, optionally
+// followed by a tab and a description.
+func AutoComplete(c *cli.Context, cache *FileCache) {
+	AutoCompleteWith(c, cache, nil)
+}
 
+// AutoCompleteWith behave like AutoComplete, using the providers of the command first and the default ones for the
+// flags it does not declare. When the last argument is a flag expecting a value, the candidates of its provider are
+// printed, otherwise the flags of the command are.
+func AutoCompleteWith(c *cli.Context, cache *FileCache, providers CompletionProviders) {
 	args := c.Parent().Args()
-	for i, arg := range args {
-		if arg == "--project" {
-			projectKey = &args[i+1]
-		}
+	if len(args) == 0 {
+		return
 	}
+	lastArg := args[len(args)-1]
 
-	for _, repo := range cache.Repositories {
-		if projectKey != nil {
-			if repo.Project.Key == *projectKey {
-				fmt.Fprintln(c.App.Writer, repo.Slug)
-			}
-		} else {
-			fmt.Fprintln(c.App.Writer, repo.Slug)
-		}
-	}
-}
+	if len(lastArg) > 1 && lastArg[0] == '-' {
+		name := strings.TrimLeft(lastArg, "-")
+		flag, err := getFlag(c, name)
 
-// autocompleteProject search if a repository have been provided in previous arguments and autocomplete with
-// only those projects.
-// Otherwise it will display the full project list.
-func autocompleteProject(c *cli.Context, cache *FileCache) {
+		provider, ok := providers[name]
+		if !ok {
+			provider, ok = defaultCompletionProviders[name]
+		}
 
-	var repositorySlug *string
+		if ok && (err != nil || !isBoolFlag(flag)) {
+			printProviderCompletion(c, cache, provider, name, parseCompletionFlags(args, c.Command.Flags))
+			return
+		}
 
-	args := c.Parent().Args()
-	for i, arg := range args {
-		if arg == "--repository" {
-			repositorySlug = &args[i+1]
+		// A flag expecting a value without provider, let the shell complete it
+		if err == nil && !isBoolFlag(flag) {
+			return
 		}
 	}
 
-	if repositorySlug != nil {
-		repos := cache.FindRepositoriesBySlug(*repositorySlug)
-		for _, repo := range repos {
-			fmt.Fprintln(c.App.Writer, repo.Project.Key)
-		}
-	} else {
-		for _, project := range cache.Projects {
-			fmt.Fprintln(c.App.Writer, project.Key)
+	flags := c.Command.Flags
+	for _, flag := range flags {
+		name := flag.GetName()
+		_, isStringSliceFlag := flag.(cli.StringSliceFlag)
+		if !c.IsSet(name) || isStringSliceFlag {
+			printFlagCompletion(c.App.Writer, flag)
 		}
 	}
 }
 
 func getFlag(c *cli.Context, name string) (cli.Flag, error) {
-	for _, flag := range c.Command.Flags {
-		if flag.GetName() == name {
-			return flag, nil
-		}
+	if flag := findFlag(c.Command.Flags, name); flag != nil {
+		return flag, nil
 	}
 
 	return nil, fmt.Errorf("cannot find flag %s", name)
@@ -0,0 +1,275 @@
+// Package helper provides handy func and struct to be reused in commands
+package helper
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Credential store names
+const (
+	CredentialStoreNone          = "none"
+	CredentialStoreSecretService = "secret-service"
+)
+
+// ErrCredentialNotFound is returned when no credential is stored for a server and user
+var ErrCredentialNotFound = errors.New("credential not found")
+
+// ErrCredentialStoreUnavailable is returned when the credential store cannot be used on this system
+var ErrCredentialStoreUnavailable = errors.New("credential store unavailable")
+
+// CredentialStore save and retrieve the password or token of a user on a server
+type CredentialStore interface {
+	// Name describe the store in messages
+	Name() string
+	// Get give the stored secret, or ErrCredentialNotFound
+	Get(server, user string) (string, error)
+	// Store save the secret, replacing any previous one
+	Store(server, user, secret string) error
+	// Erase remove the stored secret, or return ErrCredentialNotFound
+	Erase(server, user string) error
+}
+
+// credentialStores hold the builtin stores, by name
+var credentialStores = map[string]func() CredentialStore{
+	CredentialStoreSecretService: func() CredentialStore { return &SecretServiceStore{Command: "secret-tool"} },
+}
+
+// RegisterCredentialStore make a store available to NewCredentialStore under name
+func RegisterCredentialStore(name string, factory func() CredentialStore) {
+	credentialStores[name] = factory
+}
+
+// CredentialStoreNames give the names of the builtin stores, sorted
+func CredentialStoreNames() []string {
+	var names []string
+	for name := range credentialStores {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// NewCredentialStore give the store matching name. Unknown names are run as external credential helpers.
+// It return nil when the stores are disabled with none or an empty name.
+func NewCredentialStore(name string) CredentialStore {
+	if len(name) == 0 || name == CredentialStoreNone {
+		return nil
+	}
+
+	if factory, ok := credentialStores[name]; ok {
+		return factory()
+	}
+
+	return &CredentialHelperStore{Helper: name}
+}
+
+// SecretServiceStore keep the secrets in the OS keyring through the freedesktop Secret Service api
+// (GNOME Keyring, KWallet...), using the secret-tool command from libsecret
+type SecretServiceStore struct {
+	Command string
+}
+
+// Name implements CredentialStore
+func (s *SecretServiceStore) Name() string {
+	return CredentialStoreSecretService
+}
+
+// attributes give the attributes identifying a secret in the keyring
+func (s *SecretServiceStore) attributes(server, user string) []string {
+	return []string{"service", "bitadmin", "server", server, "user", user}
+}
+
+// run execute secret-tool with args, writing input on its standard input
+func (s *SecretServiceStore) run(input string, args ...string) (string, error) {
+	if _, err := exec.LookPath(s.Command); err != nil {
+		return "", fmt.Errorf("%w: %s not found, install libsecret-tools or use another --credential-helper", ErrCredentialStoreUnavailable, s.Command)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(s.Command, args...)
+	cmd.Stdin = strings.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		// secret-tool exit with 1 and no message when nothing match the attributes
+		if errors.As(err, &exitErr) && len(strings.TrimSpace(stderr.String())) == 0 {
+			return "", ErrCredentialNotFound
+		}
+		return "", fmt.Errorf("%s %s: %s", s.Command, args[0], strings.TrimSpace(stderr.String()))
+	}
+
+	return stdout.String(), nil
+}
+
+// Get implements CredentialStore
+func (s *SecretServiceStore) Get(server, user string) (string, error) {
+	secret, err := s.run("", append([]string{"lookup"}, s.attributes(server, user)...)...)
+	if err != nil {
+		return "", err
+	}
+	if len(secret) == 0 {
+		return "", ErrCredentialNotFound
+	}
+
+	return secret, nil
+}
+
+// Store implements CredentialStore
+func (s *SecretServiceStore) Store(server, user, secret string) error {
+	label := fmt.Sprintf("--label=bitadmin %s@%s", user, server)
+	_, err := s.run(secret, append([]string{"store", label}, s.attributes(server, user)...)...)
+
+	return err
+}
+
+// Erase implements CredentialStore
+func (s *SecretServiceStore) Erase(server, user string) error {
+	if _, err := s.Get(server, user); err != nil {
+		return err
+	}
+
+	_, err := s.run("", append([]string{"clear"}, s.attributes(server, user)...)...)
+
+	return err
+}
+
+// CredentialHelperStore delegate to an external credential helper speaking the git credential protocol:
+// key=value lines on the standard input and output, called with get, store or erase.
+// Like git, the helper is a shell snippet when starting with !, an absolute path, or the name of a git credential
+// helper (ie: store run git credential-store).
+type CredentialHelperStore struct {
+	Helper string
+}
+
+// Name implements CredentialStore
+func (s *CredentialHelperStore) Name() string {
+	return "credential helper " + s.Helper
+}
+
+// command give the shell command running the helper with operation
+func (s *CredentialHelperStore) command(operation string) string {
+	switch {
+	case strings.HasPrefix(s.Helper, "!"):
+		return s.Helper[1:] + " " + operation
+	case filepath.IsAbs(s.Helper):
+		return s.Helper + " " + operation
+	}
+
+	return "git credential-" + s.Helper + " " + operation
+}
+
+// description give the protocol attributes describing the server and user
+func (s *CredentialHelperStore) description(server, user string) []string {
+	lines := []string{}
+
+	if parsed, err := url.Parse(server); err == nil && len(parsed.Host) > 0 {
+		lines = append(lines, "protocol="+parsed.Scheme, "host="+parsed.Host)
+		if path := strings.Trim(parsed.Path, "/"); len(path) > 0 {
+			lines = append(lines, "path="+path)
+		}
+	} else {
+		lines = append(lines, "host="+server)
+	}
+
+	return append(lines, "username="+user)
+}
+
+// run execute the helper operation with the attributes, and give the attributes it answered
+func (s *CredentialHelperStore) run(operation string, attributes []string) (map[string]string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("sh", "-c", s.command(operation))
+	cmd.Stdin = strings.NewReader(strings.Join(attributes, "\n") + "\n\n")
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("credential helper %s %s failed: %s", s.Helper, operation, strings.TrimSpace(stderr.String()))
+	}
+
+	answer := make(map[string]string)
+	scanner := bufio.NewScanner(&stdout)
+	for scanner.Scan() {
+		if parts := strings.SplitN(scanner.Text(), "=", 2); len(parts) == 2 {
+			answer[parts[0]] = parts[1]
+		}
+	}
+
+	return answer, scanner.Err()
+}
+
+// Get implements CredentialStore
+func (s *CredentialHelperStore) Get(server, user string) (string, error) {
+	answer, err := s.run("get", s.description(server, user))
+	if err != nil {
+		return "", err
+	}
+
+	password, ok := answer["password"]
+	if !ok || len(password) == 0 {
+		return "", ErrCredentialNotFound
+	}
+
+	return password, nil
+}
+
+// Store implements CredentialStore
+func (s *CredentialHelperStore) Store(server, user, secret string) error {
+	_, err := s.run("store", append(s.description(server, user), "password="+secret))
+
+	return err
+}
+
+// Erase implements CredentialStore. The protocol does not tell if something was erased, so it never return
+// ErrCredentialNotFound.
+func (s *CredentialHelperStore) Erase(server, user string) error {
+	_, err := s.run("erase", s.description(server, user))
+
+	return err
+}
+
+// ReadSecret read a line from the standard input, without echoing it when it is a terminal
+func ReadSecret(prompt string) (string, error) {
+	info, err := os.Stdin.Stat()
+	if err != nil {
+		return "", err
+	}
+
+	terminal := info.Mode()&os.ModeCharDevice != 0
+	if terminal {
+		fmt.Fprint(os.Stderr, prompt)
+		if err := stty("-echo"); err != nil {
+			return "", fmt.Errorf("cannot disable the terminal echo: %w", err)
+		}
+		defer func() {
+			_ = stty("echo")
+			fmt.Fprintln(os.Stderr)
+		}()
+	}
+
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && len(line) == 0 {
+		return "", err
+	}
+
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// stty change the settings of the terminal attached to the standard input
+func stty(setting string) error {
+	cmd := exec.Command("stty", setting)
+	cmd.Stdin = os.Stdin
+
+	return cmd.Run()
+}
@@ -0,0 +1,162 @@
+// Package helper provides handy func and struct to be reused in commands
+package helper
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/daeMOn63/bitclient"
+)
+
+// Exit codes returned by bitadmin, one per error class
+const (
+	ExitCodeError          = 1
+	ExitCodeAuthentication = 3
+	ExitCodePermission     = 4
+	ExitCodeNotFound       = 5
+	ExitCodeConflict       = 6
+	ExitCodeBadRequest     = 7
+	ExitCodeServer         = 8
+	ExitCodeNetwork        = 9
+)
+
+// APIError is a Bitbucket api failure translated to an actionable message
+type APIError struct {
+	StatusCode int
+	ExitCode   int
+	Message    string
+	Hint       string
+	Cause      error
+}
+
+// Error implements the error interface
+func (e *APIError) Error() string {
+	if len(e.Hint) == 0 {
+		return e.Message
+	}
+
+	return fmt.Sprintf("%s\n%s", e.Message, e.Hint)
+}
+
+// Unwrap give access to the original error
+func (e *APIError) Unwrap() error {
+	return e.Cause
+}
+
+// errorHint hold the exit code and the advice given for a class of api failures
+type errorHint struct {
+	exitCode int
+	hint     string
+}
+
+// exceptionHints map the Bitbucket exception names, without their package, to a dedicated advice.
+// Exceptions not listed fall back on the status code.
+var exceptionHints = map[string]errorHint{
+	"NoSuchProjectException":           {ExitCodeNotFound, "The project does not exist or is not visible to the user, check the --project flag"},
+	"NoSuchRepositoryException":        {ExitCodeNotFound, "The repository does not exist or is not visible to the user, check the --project and --repository flags"},
+	"NoSuchUserException":              {ExitCodeNotFound, "The user does not exist, check the user name (the login, not the display name)"},
+	"NoSuchGroupException":             {ExitCodeNotFound, "The group does not exist, check the group name"},
+	"NoSuchBranchException":            {ExitCodeNotFound, "The branch does not exist in the repository, check the branch name"},
+	"DuplicateRefRestrictionException": {ExitCodeConflict, "An identical branch restriction already exists on the repository or project"},
+	"DuplicateProjectException":        {ExitCodeConflict, "A project with the same key or name already exists"},
+	"DuplicateRepositoryNameException": {ExitCodeConflict, "A repository with the same name already exists in the project"},
+	"AuthorisationException":           {ExitCodePermission, "The user is not allowed to do this, it probably lacks REPO_ADMIN, PROJECT_ADMIN or ADMIN permission"},
+	"ArgumentValidationException":      {ExitCodeBadRequest, "Bitbucket rejected the request, check the flag values listed above"},
+}
+
+// statusHint give the advice matching an http status code
+func statusHint(statusCode int) errorHint {
+	switch {
+	case statusCode == 400:
+		return errorHint{ExitCodeBadRequest, "Bitbucket rejected the request, check the flag values listed above"}
+	case statusCode == 401:
+		return errorHint{ExitCodeAuthentication, "Authentication failed, check the --user and --password flags"}
+	case statusCode == 403:
+		return errorHint{ExitCodePermission, "The user is not allowed to do this, it probably lacks REPO_ADMIN, PROJECT_ADMIN or ADMIN permission"}
+	case statusCode == 404:
+		return errorHint{ExitCodeNotFound, "The project, repository, user, group or plugin does not exist, or is not visible to the user"}
+	case statusCode == 409:
+		return errorHint{ExitCodeConflict, "The change conflict with the current state (ie: already existing repository or branch restriction)"}
+	case statusCode >= 500:
+		return errorHint{ExitCodeServer, "Bitbucket failed to process the request, check the server logs"}
+	}
+
+	return errorHint{exitCode: ExitCodeError}
+}
+
+// exceptionHint find the advice matching the first known exception of the error payload
+func exceptionHint(restError RestError) (errorHint, bool) {
+	for _, detail := range restError.Errors {
+		name := detail.ExceptionName[strings.LastIndex(detail.ExceptionName, ".")+1:]
+		if hint, ok := exceptionHints[name]; ok {
+			return hint, true
+		}
+	}
+
+	return errorHint{}, false
+}
+
+// TranslateError classify the api failures found in err, wrapped or not, and add a hint on how to fix them.
+// Other errors are returned untouched.
+func TranslateError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var apiError *APIError
+	if errors.As(err, &apiError) {
+		return err
+	}
+
+	var requestError bitclient.RequestError
+	var restError RestError
+	var urlError *url.Error
+	var netError net.Error
+
+	translated := &APIError{
+		Message: err.Error(),
+		Cause:   err,
+	}
+
+	// bitclient only keep the status code, the rest client also decode the exception name
+	switch {
+	case errors.As(err, &requestError):
+		translated.StatusCode = requestError.Code
+	case errors.As(err, &restError):
+		translated.StatusCode = restError.Code
+	case errors.As(err, &urlError), errors.As(err, &netError):
+		translated.ExitCode = ExitCodeNetwork
+		translated.Hint = "Bitbucket cannot be reached, check the --url flag and your network connection"
+
+		return translated
+	default:
+		return err
+	}
+
+	hint, ok := exceptionHint(restError)
+	if !ok {
+		hint = statusHint(translated.StatusCode)
+	}
+
+	translated.ExitCode = hint.exitCode
+	translated.Hint = hint.hint
+
+	return translated
+}
+
+// ExitCode give the process exit code matching err
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+
+	var apiError *APIError
+	if errors.As(TranslateError(err), &apiError) {
+		return apiError.ExitCode
+	}
+
+	return ExitCodeError
+}
@@ -0,0 +1,106 @@
+package helper
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/daeMOn63/bitclient"
+)
+
+func TestTranslateError(t *testing.T) {
+	restError := func(code int, exceptionName string) RestError {
+		return RestError{Code: code, Errors: []RestErrorDetail{{Message: "failed", ExceptionName: exceptionName}}}
+	}
+
+	tests := []struct {
+		name     string
+		err      error
+		exitCode int
+		hint     string
+	}{
+		{"bitclient unauthorized", bitclient.RequestError{Code: 401}, ExitCodeAuthentication, "Authentication failed"},
+		{"bitclient forbidden", bitclient.RequestError{Code: 403}, ExitCodePermission, "not allowed"},
+		{"bitclient not found", bitclient.RequestError{Code: 404}, ExitCodeNotFound, "does not exist"},
+		{"bitclient bad request", bitclient.RequestError{Code: 400}, ExitCodeBadRequest, "rejected"},
+		{"bitclient conflict", bitclient.RequestError{Code: 409}, ExitCodeConflict, "conflict"},
+		{"bitclient server error", bitclient.RequestError{Code: 502}, ExitCodeServer, "server logs"},
+		{"unknown status", bitclient.RequestError{Code: 418}, ExitCodeError, ""},
+		{"no such project", restError(404, "com.atlassian.bitbucket.project.NoSuchProjectException"), ExitCodeNotFound, "--project flag"},
+		{"no such repository", restError(404, "com.atlassian.bitbucket.repository.NoSuchRepositoryException"), ExitCodeNotFound, "--repository flags"},
+		{"no such user", restError(404, "com.atlassian.bitbucket.user.NoSuchUserException"), ExitCodeNotFound, "user name"},
+		{"no such group", restError(404, "com.atlassian.bitbucket.user.NoSuchGroupException"), ExitCodeNotFound, "group name"},
+		{"duplicate restriction", restError(409, "com.atlassian.bitbucket.ref.restriction.DuplicateRefRestrictionException"), ExitCodeConflict, "branch restriction"},
+		{"authorisation", restError(401, "com.atlassian.bitbucket.AuthorisationException"), ExitCodePermission, "not allowed"},
+		{"validation", restError(400, "com.atlassian.bitbucket.validation.ArgumentValidationException"), ExitCodeBadRequest, "rejected"},
+		{"unknown exception", restError(409, "com.atlassian.bitbucket.SomeOtherException"), ExitCodeConflict, "conflict"},
+		{"second known exception", RestError{Code: 404, Errors: []RestErrorDetail{
+			{ExceptionName: "com.atlassian.bitbucket.SomeOtherException"},
+			{ExceptionName: "com.atlassian.bitbucket.user.NoSuchGroupException"},
+		}}, ExitCodeNotFound, "group name"},
+		{"wrapped", fmt.Errorf("cannot read hooks: %w", restError(404, "com.atlassian.bitbucket.project.NoSuchProjectException")), ExitCodeNotFound, "--project flag"},
+		{"network", &url.Error{Op: "Get", URL: "http://stash.server.com", Err: errors.New("connection refused")}, ExitCodeNetwork, "--url flag"},
+	}
+
+	for _, test := range tests {
+		var apiError *APIError
+		if !errors.As(TranslateError(test.err), &apiError) {
+			t.Errorf("%s: TranslateError did not return an APIError", test.name)
+			continue
+		}
+
+		if apiError.ExitCode != test.exitCode {
+			t.Errorf("%s: exit code %d, expected %d", test.name, apiError.ExitCode, test.exitCode)
+		}
+		if !strings.Contains(apiError.Hint, test.hint) || (len(test.hint) == 0 && len(apiError.Hint) > 0) {
+			t.Errorf("%s: hint %q, expected to contain %q", test.name, apiError.Hint, test.hint)
+		}
+		if apiError.Cause == nil || apiError.Cause.Error() != test.err.Error() {
+			t.Errorf("%s: the original error is not kept", test.name)
+		}
+		if ExitCode(test.err) != test.exitCode {
+			t.Errorf("%s: ExitCode = %d, expected %d", test.name, ExitCode(test.err), test.exitCode)
+		}
+	}
+}
+
+func TestTranslateErrorUntouched(t *testing.T) {
+	if TranslateError(nil) != nil {
+		t.Error("TranslateError(nil) should be nil")
+	}
+
+	err := errors.New("--project flag is required")
+	if TranslateError(err) != err {
+		t.Error("errors not coming from the api should be returned untouched")
+	}
+
+	translated := TranslateError(bitclient.RequestError{Code: 404})
+	if TranslateError(translated) != translated {
+		t.Error("translated errors should not be translated again")
+	}
+
+	if ExitCode(nil) != 0 {
+		t.Errorf("ExitCode(nil) = %d, expected 0", ExitCode(nil))
+	}
+	if ExitCode(err) != ExitCodeError {
+		t.Errorf("ExitCode(%s) = %d, expected %d", err, ExitCode(err), ExitCodeError)
+	}
+}
+
+func TestAPIErrorMessage(t *testing.T) {
+	tests := []struct {
+		err     APIError
+		message string
+	}{
+		{APIError{Message: "404 - Not Found"}, "404 - Not Found"},
+		{APIError{Message: "404 - Not Found", Hint: "Check the --project flag"}, "404 - Not Found\nCheck the --project flag"},
+	}
+
+	for _, test := range tests {
+		if message := test.err.Error(); message != test.message {
+			t.Errorf("Error() = %q, expected %q", message, test.message)
+		}
+	}
+}
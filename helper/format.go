@@ -0,0 +1,61 @@
+// Package helper provides handy func and struct to be reused in commands
+package helper
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// Supported output formats of the reporting commands
+const (
+	FormatTable = "table"
+	FormatJSON  = "json"
+	FormatCSV   = "csv"
+)
+
+// ValidateFormat ensure format is one of the supported output formats
+func ValidateFormat(format string) error {
+	switch format {
+	case FormatTable, FormatJSON, FormatCSV:
+		return nil
+	}
+
+	return fmt.Errorf("invalid format %s, must be one of %s, %s, %s", format, FormatTable, FormatJSON, FormatCSV)
+}
+
+// WriteFormatted output a report in given format. Table and CSV are built from header and rows,
+// while JSON is encoded from data so it can keep a richer structure.
+func WriteFormatted(w io.Writer, format string, header []string, rows [][]string, data interface{}) error {
+	switch format {
+	case FormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+
+		return encoder.Encode(data)
+	case FormatCSV:
+		writer := csv.NewWriter(w)
+		if err := writer.Write(header); err != nil {
+			return err
+		}
+		if err := writer.WriteAll(rows); err != nil {
+			return err
+		}
+
+		return writer.Error()
+	case FormatTable:
+		writer := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+
+		fmt.Fprintln(writer, strings.Join(header, "\t"))
+		for _, row := range rows {
+			fmt.Fprintln(writer, strings.Join(row, "\t"))
+		}
+
+		return writer.Flush()
+	}
+
+	return ValidateFormat(format)
+}
@@ -0,0 +1,93 @@
+// Package helper provides handy func and struct to be reused in commands
+package helper
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Base scores of each kind of match. Penalties are capped to fuzzyMaxPenalty so the kinds never overlap.
+const (
+	fuzzyExact       = 1000
+	fuzzyPrefix      = 900
+	fuzzyWordStart   = 800
+	fuzzySubstring   = 700
+	fuzzySubsequence = 500
+	fuzzyMaxPenalty  = 99
+)
+
+// FuzzyScore rate how well candidate match term, case insensitive. 0 means no match, otherwise the higher the better:
+// exact matches come first, then prefixes, substrings starting a word, other substrings, and finally candidates
+// containing the term letters in order.
+func FuzzyScore(term string, candidate string) int {
+	term = strings.ToLower(strings.TrimSpace(term))
+	candidate = strings.ToLower(candidate)
+
+	if len(term) == 0 || len(candidate) == 0 {
+		return 0
+	}
+
+	switch {
+	case candidate == term:
+		return fuzzyExact
+	case strings.HasPrefix(candidate, term):
+		return fuzzyPrefix - minInt(utf8.RuneCountInString(candidate)-utf8.RuneCountInString(term), fuzzyMaxPenalty)
+	}
+
+	if i := strings.Index(candidate, term); i >= 0 {
+		before := []rune(candidate[:i])
+		previous := before[len(before)-1]
+
+		// Matches starting a word (ie: "api" in "billing-api") are better than matches in the middle of a word
+		if !unicode.IsLetter(previous) && !unicode.IsDigit(previous) {
+			return fuzzyWordStart - minInt(len(before), fuzzyMaxPenalty)
+		}
+
+		return fuzzySubstring - minInt(len(before), fuzzyMaxPenalty)
+	}
+
+	// Subsequence match, penalized by the gaps between the matched letters
+	score := fuzzySubsequence
+	position := 0
+	termRunes := []rune(term)
+	matched := 0
+	for i, r := range []rune(candidate) {
+		if matched == len(termRunes) {
+			break
+		}
+		if r == termRunes[matched] {
+			if matched > 0 {
+				score -= minInt(i-position-1, 20) * 5
+			}
+			position = i
+			matched++
+		}
+	}
+
+	if matched < len(termRunes) || score <= 0 {
+		return 0
+	}
+
+	return score
+}
+
+// BestFuzzyScore give the best FuzzyScore of term over all candidates
+func BestFuzzyScore(term string, candidates ...string) int {
+	best := 0
+	for _, candidate := range candidates {
+		if score := FuzzyScore(term, candidate); score > best {
+			best = score
+		}
+	}
+
+	return best
+}
+
+func minInt(a int, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
+}
@@ -0,0 +1,74 @@
+package helper
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFuzzyScore(t *testing.T) {
+	tests := []struct {
+		term      string
+		candidate string
+		score     int
+	}{
+		{"", "api", 0},
+		{"api", "", 0},
+		{"xyz", "billing-api", 0},
+		{"ipa", "api", 0},
+		{"api", "api", 1000},
+		{" API ", "api", 1000},
+		{"api", "api-gateway", 892},
+		{"api", "billing-api", 792},
+		{"api", "rapid", 699},
+		{"bapi", "billing-api", 465},
+		// Positions are counted in letters, not bytes
+		{"api", "éapi", 699},
+		{"api", "é-api", 798},
+		{"api", "apié", 899},
+		{"CAFÉ", "café", 1000},
+	}
+
+	for _, test := range tests {
+		if score := FuzzyScore(test.term, test.candidate); score != test.score {
+			t.Errorf("FuzzyScore(%q, %q) = %d, expected %d", test.term, test.candidate, score, test.score)
+		}
+	}
+}
+
+func TestFuzzyScoreOrder(t *testing.T) {
+	// Each candidate must score better than the next one
+	tests := []struct {
+		term       string
+		candidates []string
+	}{
+		{"api", []string{"api", "api-gateway", "billing-api", "rapid", "a-p-i"}},
+		{"api", []string{"api-" + strings.Repeat("x", 200), "-api"}},
+		{"api", []string{"-" + strings.Repeat("x", 200) + "-api", "xapi"}},
+		{"api", []string{"xapi", "a" + strings.Repeat("x", 200) + "pi"}},
+		{"api", []string{"billing-api", "billingapi"}},
+		{"api", []string{"apix", "apixx"}},
+		{"api", []string{"aapi", "aaapi"}},
+		{"api", []string{"a-p-i", "a---p---i"}},
+	}
+
+	for _, test := range tests {
+		for i := 1; i < len(test.candidates); i++ {
+			better := FuzzyScore(test.term, test.candidates[i-1])
+			worse := FuzzyScore(test.term, test.candidates[i])
+			if better <= worse {
+				t.Errorf("FuzzyScore(%q, %q) = %d, expected more than %q with %d",
+					test.term, test.candidates[i-1], better, test.candidates[i], worse)
+			}
+		}
+	}
+}
+
+func TestBestFuzzyScore(t *testing.T) {
+	if score := BestFuzzyScore("api", "web", "billing-api", "api"); score != 1000 {
+		t.Errorf("BestFuzzyScore = %d, expected 1000", score)
+	}
+
+	if score := BestFuzzyScore("api"); score != 0 {
+		t.Errorf("BestFuzzyScore without candidates = %d, expected 0", score)
+	}
+}
@@ -0,0 +1,141 @@
+// Package helper provides handy func and struct to be reused in commands
+package helper
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// ErrGitDetachedHead is returned when no branch is checked out
+var ErrGitDetachedHead = errors.New("HEAD is detached, no branch is checked out")
+
+// ciBranchVariables list the environment variables holding the built branch on common CI servers, CI checkouts
+// being usually on a detached HEAD
+var ciBranchVariables = []string{
+	"BRANCH_NAME",                      // Jenkins multibranch pipelines
+	"GIT_BRANCH",                       // Jenkins git plugin
+	"CI_COMMIT_REF_NAME",               // GitLab CI
+	"GITHUB_HEAD_REF",                  // GitHub Actions, pull requests
+	"GITHUB_REF_NAME",                  // GitHub Actions
+	"BITBUCKET_BRANCH",                 // Bitbucket Pipelines
+	"TRAVIS_BRANCH",                    // Travis CI
+	"bamboo_planRepository_branchName", // Bamboo
+}
+
+// GitCommit hold the details of a commit read from a local git repository
+type GitCommit struct {
+	Hash           string
+	AuthorName     string
+	AuthorEmail    string
+	CommitterName  string
+	CommitterEmail string
+	Parents        []string
+	Message        string
+}
+
+// ShortHash return the abbreviated commit hash
+func (c GitCommit) ShortHash() string {
+	if len(c.Hash) > 10 {
+		return c.Hash[:10]
+	}
+
+	return c.Hash
+}
+
+// Subject return the first line of the commit message
+func (c GitCommit) Subject() string {
+	return strings.SplitN(c.Message, "\n", 2)[0]
+}
+
+// IsMerge tell if the commit has more than one parent
+func (c GitCommit) IsMerge() bool {
+	return len(c.Parents) > 1
+}
+
+// gitCommitFormat output the commit fields separated by NUL, commits being separated by a record separator
+const gitCommitFormat = "--format=%H%x00%an%x00%ae%x00%cn%x00%ce%x00%P%x00%B%x1e"
+
+// ReadGitCommits list the commits of revRange (ie: origin/master..HEAD) in the git repository at repoPath
+func ReadGitCommits(repoPath string, revRange string) ([]GitCommit, error) {
+	output, err := git(repoPath, "log", gitCommitFormat, revRange, "--")
+	if err != nil {
+		return nil, err
+	}
+
+	var commits []GitCommit
+
+	for _, record := range strings.Split(output, "\x1e") {
+		record = strings.TrimLeft(record, "\n")
+		if len(record) == 0 {
+			continue
+		}
+
+		fields := strings.SplitN(record, "\x00", 7)
+		if len(fields) != 7 {
+			return nil, fmt.Errorf("unexpected git log output %q", record)
+		}
+
+		commits = append(commits, GitCommit{
+			Hash:           fields[0],
+			AuthorName:     fields[1],
+			AuthorEmail:    fields[2],
+			CommitterName:  fields[3],
+			CommitterEmail: fields[4],
+			Parents:        strings.Fields(fields[5]),
+			Message:        strings.TrimRight(fields[6], "\n"),
+		})
+	}
+
+	return commits, nil
+}
+
+// CurrentGitBranch return the name of the branch checked out in the git repository at repoPath.
+// On a detached HEAD, the branch is read from the CI environment variables, ErrGitDetachedHead being returned
+// when none is set.
+func CurrentGitBranch(repoPath string) (string, error) {
+	output, err := git(repoPath, "symbolic-ref", "--quiet", "--short", "HEAD")
+	if err == nil {
+		return strings.TrimSpace(output), nil
+	}
+
+	// symbolic-ref fail on a detached HEAD, make sure the repository itself is readable
+	if _, err := git(repoPath, "rev-parse", "--verify", "HEAD"); err != nil {
+		return "", err
+	}
+
+	if branch := CIGitBranch(); len(branch) > 0 {
+		return branch, nil
+	}
+
+	return "", ErrGitDetachedHead
+}
+
+// CIGitBranch return the branch built by the CI server, or an empty string when none is found
+func CIGitBranch() string {
+	for _, variable := range ciBranchVariables {
+		if branch := os.Getenv(variable); len(branch) > 0 {
+			return strings.TrimPrefix(strings.TrimPrefix(branch, "refs/heads/"), "origin/")
+		}
+	}
+
+	return ""
+}
+
+// git run a git command in repoPath and return its standard output
+func git(repoPath string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.Command("git", append([]string{"-C", repoPath}, args...)...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s failed: %s %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+
+	return stdout.String(), nil
+}
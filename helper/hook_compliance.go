@@ -0,0 +1,179 @@
+// Package helper provides handy func and struct to be reused in commands
+package helper
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Normalization modes applied on hook settings values before comparison
+const (
+	NormalizeStripWhitespace = "strip-whitespace"
+	NormalizeTrim            = "trim"
+	NormalizeLowercase       = "lowercase"
+	NormalizeIgnore          = "ignore"
+)
+
+// NormalizeAllFields is the field name applying a normalization to every field
+const NormalizeAllFields = "*"
+
+// SettingsNormalization map settings field names to the normalization modes applied on their values
+type SettingsNormalization map[string][]string
+
+// SettingsDifference describe a settings field not matching the baseline
+type SettingsDifference struct {
+	Field    string      `json:"field"`
+	Expected interface{} `json:"expected"`
+	Actual   interface{} `json:"actual"`
+}
+
+// String convert the difference to a readable string
+func (d SettingsDifference) String() string {
+	return fmt.Sprintf("%s: expected %v, got %v", d.Field, d.Expected, d.Actual)
+}
+
+// ParseSettingsNormalization parse field=mode definitions (ie: issueJqlMatcher=strip-whitespace, *=trim)
+func ParseSettingsNormalization(definitions []string) (SettingsNormalization, error) {
+	normalization := make(SettingsNormalization)
+
+	for _, definition := range definitions {
+		parts := strings.SplitN(definition, "=", 2)
+		if len(parts) != 2 || len(parts[0]) == 0 {
+			return nil, fmt.Errorf("invalid normalization %s, expected <field>=<mode>", definition)
+		}
+
+		switch parts[1] {
+		case NormalizeStripWhitespace, NormalizeTrim, NormalizeLowercase, NormalizeIgnore:
+		default:
+			return nil, fmt.Errorf(
+				"invalid normalization mode %s, must be one of %s, %s, %s, %s",
+				parts[1],
+				NormalizeStripWhitespace,
+				NormalizeTrim,
+				NormalizeLowercase,
+				NormalizeIgnore,
+			)
+		}
+
+		normalization[parts[0]] = append(normalization[parts[0]], parts[1])
+	}
+
+	return normalization, nil
+}
+
+// modes return the normalization modes applying to given field
+func (n SettingsNormalization) modes(field string) []string {
+	return append(append([]string{}, n[NormalizeAllFields]...), n[field]...)
+}
+
+// normalize apply the field normalization modes on value. The second value is false when the field must be ignored.
+func (n SettingsNormalization) normalize(field string, value interface{}) (interface{}, bool) {
+	for _, mode := range n.modes(field) {
+		if mode == NormalizeIgnore {
+			return nil, false
+		}
+
+		str, ok := value.(string)
+		if !ok {
+			continue
+		}
+
+		switch mode {
+		case NormalizeStripWhitespace:
+			value = strings.Map(func(r rune) rune {
+				if unicode.IsSpace(r) {
+					return -1
+				}
+				return r
+			}, str)
+		case NormalizeTrim:
+			value = strings.TrimSpace(str)
+		case NormalizeLowercase:
+			value = strings.ToLower(str)
+		}
+	}
+
+	// Bitbucket omit unset fields, so zero values are considered unset
+	switch v := value.(type) {
+	case string:
+		if len(v) == 0 {
+			return nil, true
+		}
+	case bool:
+		if !v {
+			return nil, true
+		}
+	case float64:
+		if v == 0 {
+			return nil, true
+		}
+	}
+
+	return value, true
+}
+
+// CompareHookSettings list the fields of actual not matching the baseline, once normalized
+func CompareHookSettings(baseline json.RawMessage, actual json.RawMessage, normalization SettingsNormalization) ([]SettingsDifference, error) {
+	expectedFields, err := settingsFields(baseline)
+	if err != nil {
+		return nil, fmt.Errorf("invalid baseline settings: %s", err)
+	}
+
+	actualFields, err := settingsFields(actual)
+	if err != nil {
+		return nil, fmt.Errorf("invalid settings: %s", err)
+	}
+
+	fields := make(map[string]bool)
+	for field := range expectedFields {
+		fields[field] = true
+	}
+	for field := range actualFields {
+		fields[field] = true
+	}
+
+	var names []string
+	for field := range fields {
+		names = append(names, field)
+	}
+	sort.Strings(names)
+
+	var differences []SettingsDifference
+
+	for _, field := range names {
+		expected, compare := normalization.normalize(field, expectedFields[field])
+		if !compare {
+			continue
+		}
+		current, _ := normalization.normalize(field, actualFields[field])
+
+		if !reflect.DeepEqual(expected, current) {
+			differences = append(differences, SettingsDifference{
+				Field:    field,
+				Expected: expectedFields[field],
+				Actual:   actualFields[field],
+			})
+		}
+	}
+
+	return differences, nil
+}
+
+// settingsFields decode the settings JSON object, empty settings giving no fields
+func settingsFields(settings json.RawMessage) (map[string]interface{}, error) {
+	fields := make(map[string]interface{})
+
+	if len(settings) == 0 {
+		return fields, nil
+	}
+
+	if err := json.Unmarshal(settings, &fields); err != nil {
+		return nil, err
+	}
+
+	return fields, nil
+}
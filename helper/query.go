@@ -0,0 +1,196 @@
+// Package helper provides handy func and struct to be reused in commands
+package helper
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Record is a flattened entity, nested fields being joined with dots (ie: project.key)
+type Record map[string]interface{}
+
+// queryOperators are the supported operators, the two characters ones coming first so they win over = < and >
+var queryOperators = []string{"!=", ">=", "<=", "=", "~", ">", "<"}
+
+// QueryFilter is a single condition of a query (ie: project.key=PRJ, emailAddress=*@example.com, active=false)
+type QueryFilter struct {
+	Field    string
+	Operator string
+	Value    string
+	regex    *regexp.Regexp
+	number   float64
+}
+
+// ParseQueryFilter parse a `<field><operator><value>` condition. Supported operators are:
+// = and != (shell patterns allowed), ~ (regular expression), >, >=, < and <= (numbers).
+func ParseQueryFilter(expression string) (QueryFilter, error) {
+	// The first operator found is used, so values can hold operator characters (ie: name=a>b)
+	position := -1
+	operator := ""
+	for _, candidate := range queryOperators {
+		i := strings.Index(expression, candidate)
+		if i > 0 && (position < 0 || i < position) {
+			position = i
+			operator = candidate
+		}
+	}
+
+	if position < 0 {
+		return QueryFilter{}, fmt.Errorf("invalid filter %s, expected <field><operator><value> with one of %s", expression, strings.Join(queryOperators, " "))
+	}
+
+	filter := QueryFilter{
+		Field:    strings.TrimSpace(expression[:position]),
+		Operator: operator,
+		Value:    strings.TrimSpace(expression[position+len(operator):]),
+	}
+
+	var err error
+	switch operator {
+	case "=", "!=":
+		_, err = path.Match(filter.Value, "")
+	case "~":
+		filter.regex, err = regexp.Compile(filter.Value)
+	default:
+		filter.number, err = strconv.ParseFloat(filter.Value, 64)
+	}
+	if err != nil {
+		return QueryFilter{}, fmt.Errorf("invalid filter %s: %s", expression, err)
+	}
+
+	return filter, nil
+}
+
+// Match tells if record satisfy the filter. A missing field is matched as an empty value.
+func (f QueryFilter) Match(record Record) bool {
+	value, _ := record.Get(f.Field)
+	text := FormatRecordValue(value)
+
+	switch f.Operator {
+	case "=", "!=":
+		matched := text == f.Value
+		if !matched && isPattern(f.Value) {
+			matched, _ = path.Match(f.Value, text)
+		}
+		return matched == (f.Operator == "=")
+	case "~":
+		return f.regex.MatchString(text)
+	}
+
+	number, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return false
+	}
+
+	switch f.Operator {
+	case ">":
+		return number > f.number
+	case ">=":
+		return number >= f.number
+	case "<":
+		return number < f.number
+	case "<=":
+		return number <= f.number
+	}
+
+	return false
+}
+
+// NewRecord flatten entity, using its JSON representation
+func NewRecord(entity interface{}) (Record, error) {
+	data, err := json.Marshal(entity)
+	if err != nil {
+		return nil, err
+	}
+
+	var decoded interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil, err
+	}
+
+	record := Record{}
+	record.flatten("", decoded)
+
+	return record, nil
+}
+
+// flatten add value to the record, walking through the nested objects
+func (r Record) flatten(prefix string, value interface{}) {
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		r[prefix] = value
+		return
+	}
+
+	for key, nested := range object {
+		if len(prefix) > 0 {
+			key = prefix + "." + key
+		}
+		r.flatten(key, nested)
+	}
+}
+
+// Get give the value of field, the name being case insensitive
+func (r Record) Get(field string) (interface{}, bool) {
+	if value, ok := r[field]; ok {
+		return value, true
+	}
+
+	for key, value := range r {
+		if strings.EqualFold(key, field) {
+			return value, true
+		}
+	}
+
+	return nil, false
+}
+
+// RecordFields give the sorted field names of the records
+func RecordFields(records []Record) []string {
+	fields := make(map[string]bool)
+	for _, record := range records {
+		for field := range record {
+			fields[field] = true
+		}
+	}
+
+	var names []string
+	for field := range fields {
+		names = append(names, field)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// FormatRecordValue convert a record value to a string, lists being joined with commas
+func FormatRecordValue(value interface{}) string {
+	switch typed := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return typed
+	case bool:
+		return strconv.FormatBool(typed)
+	case float64:
+		return strconv.FormatFloat(typed, 'f', -1, 64)
+	case []interface{}:
+		var values []string
+		for _, item := range typed {
+			values = append(values, FormatRecordValue(item))
+		}
+		return strings.Join(values, ",")
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+
+	return string(data)
+}
@@ -0,0 +1,95 @@
+package helper
+
+import "testing"
+
+func TestParseQueryFilter(t *testing.T) {
+	tests := []struct {
+		expression string
+		field      string
+		operator   string
+		value      string
+		wantErr    bool
+	}{
+		{expression: "project.key=PRJ", field: "project.key", operator: "=", value: "PRJ"},
+		{expression: "slug != api-*", field: "slug", operator: "!=", value: "api-*"},
+		{expression: "size>=10", field: "size", operator: ">=", value: "10"},
+		{expression: "size<=10", field: "size", operator: "<=", value: "10"},
+		{expression: "size>10", field: "size", operator: ">", value: "10"},
+		{expression: "size<10", field: "size", operator: "<", value: "10"},
+		{expression: "name~^api", field: "name", operator: "~", value: "^api"},
+		// The first operator found wins, so values can hold operator characters
+		{expression: "name=a>b", field: "name", operator: "=", value: "a>b"},
+		{expression: "name~a=b", field: "name", operator: "~", value: "a=b"},
+		{expression: "name!=a<b", field: "name", operator: "!=", value: "a<b"},
+		{expression: "no-operator", wantErr: true},
+		{expression: "=value", wantErr: true},
+		{expression: "size>ten", wantErr: true},
+		{expression: "name~[", wantErr: true},
+		{expression: "name=[", wantErr: true},
+	}
+
+	for _, test := range tests {
+		filter, err := ParseQueryFilter(test.expression)
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("ParseQueryFilter(%q) expected an error, got %+v", test.expression, filter)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseQueryFilter(%q) unexpected error: %s", test.expression, err)
+			continue
+		}
+
+		if filter.Field != test.field || filter.Operator != test.operator || filter.Value != test.value {
+			t.Errorf("ParseQueryFilter(%q) = %q %q %q, expected %q %q %q", test.expression,
+				filter.Field, filter.Operator, filter.Value, test.field, test.operator, test.value)
+		}
+	}
+}
+
+func TestQueryFilterMatch(t *testing.T) {
+	record := Record{
+		"slug":        "billing-api",
+		"project.key": "PRJ",
+		"forkable":    false,
+		"size":        float64(42),
+		"labels":      []interface{}{"a", "b"},
+	}
+
+	tests := []struct {
+		expression string
+		match      bool
+	}{
+		{"slug=billing-api", true},
+		{"slug=billing", false},
+		{"slug=*-api", true},
+		{"slug!=*-api", false},
+		{"slug!=web", true},
+		{"SLUG=billing-api", true},
+		{"project.key=PRJ", true},
+		{"forkable=false", true},
+		{"labels=a,b", true},
+		{"slug~^bill", true},
+		{"slug~^api", false},
+		{"size>41", true},
+		{"size>42", false},
+		{"size>=42", true},
+		{"size<42", false},
+		{"size<=42", true},
+		{"slug>1", false},
+		{"missing=", true},
+		{"missing!=", false},
+	}
+
+	for _, test := range tests {
+		filter, err := ParseQueryFilter(test.expression)
+		if err != nil {
+			t.Fatalf("ParseQueryFilter(%q) unexpected error: %s", test.expression, err)
+		}
+
+		if match := filter.Match(record); match != test.match {
+			t.Errorf("%q match = %t, expected %t", test.expression, match, test.match)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+// Package helper provides handy func and struct to be reused in commands
+package helper
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/dghubble/sling"
+)
+
+// RestClient is a thin client over the Bitbucket REST api, used for the endpoints not exposed by bitclient
+type RestClient struct {
+	sling *sling.Sling
+}
+
+// RestErrorDetail is a single error entry of a Bitbucket error payload
+type RestErrorDetail struct {
+	Context       string `json:"context"`
+	Message       string `json:"message"`
+	ExceptionName string `json:"exceptionName"`
+}
+
+// RestError is returned when Bitbucket answer with a non 2xx status code
+type RestError struct {
+	Code   int               `json:"-"`
+	Errors []RestErrorDetail `json:"errors"`
+}
+
+// Error implements the error interface
+func (e RestError) Error() string {
+	var messages []string
+	for _, detail := range e.Errors {
+		// Context is set on validation errors and hold the faulty field name
+		if len(detail.Context) > 0 {
+			messages = append(messages, fmt.Sprintf("%s: %s", detail.Context, detail.Message))
+			continue
+		}
+		messages = append(messages, detail.Message)
+	}
+
+	if len(messages) == 0 {
+		return fmt.Sprintf("%d - %s", e.Code, http.StatusText(e.Code))
+	}
+
+	return fmt.Sprintf("%d - %s", e.Code, strings.Join(messages, ", "))
+}
+
+// pageRequest hold the paging query parameters
+type pageRequest struct {
+	Start uint `url:"start,omitempty"`
+	Limit uint `url:"limit,omitempty"`
+}
+
+// pageResponse hold a single page of a paged response, values are decoded later on
+type pageResponse struct {
+	Values        json.RawMessage `json:"values"`
+	IsLastPage    bool            `json:"isLastPage"`
+	NextPageStart uint            `json:"nextPageStart"`
+}
+
+// pageLimit is the number of entities requested per page
+const pageLimit = 1000
+
+// GetPaged walk through all the pages of given path and append their values to v, which must be a pointer to a slice.
+// params can be used to provide additional query parameters.
+func (rc *RestClient) GetPaged(path string, params interface{}, v interface{}) error {
+	slice := reflect.ValueOf(v)
+	if slice.Kind() != reflect.Ptr || slice.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("GetPaged expect a pointer to a slice, got %T", v)
+	}
+
+	page := pageRequest{Limit: pageLimit}
+
+	for {
+		response := pageResponse{}
+
+		err := rc.do(rc.sling.New().Get(path).QueryStruct(params).QueryStruct(page), &response)
+		if err != nil {
+			return err
+		}
+
+		values := reflect.New(slice.Elem().Type())
+		if len(response.Values) > 0 {
+			if err := json.Unmarshal(response.Values, values.Interface()); err != nil {
+				return err
+			}
+		}
+
+		slice.Elem().Set(reflect.AppendSlice(slice.Elem(), values.Elem()))
+
+		if response.IsLastPage {
+			return nil
+		}
+
+		page.Start = response.NextPageStart
+	}
+}
+
+// Get send a GET request on given path, with params encoded as query string, and decode the response in v
+func (rc *RestClient) Get(path string, params interface{}, v interface{}) error {
+	return rc.do(rc.sling.New().Get(path).QueryStruct(params), v)
+}
+
+// Post send a POST request on given path with a JSON body, and decode the response in v
+func (rc *RestClient) Post(path string, body interface{}, v interface{}) error {
+	return rc.do(rc.sling.New().Post(path).BodyJSON(body), v)
+}
+
+// Put send a PUT request on given path with a JSON body, and decode the response in v
+func (rc *RestClient) Put(path string, body interface{}, v interface{}) error {
+	return rc.do(rc.sling.New().Put(path).BodyJSON(body), v)
+}
+
+// Delete send a DELETE request on given path
+func (rc *RestClient) Delete(path string) error {
+	return rc.do(rc.sling.New().Delete(path), nil)
+}
+
+func (rc *RestClient) do(s *sling.Sling, v interface{}) error {
+	restError := RestError{}
+
+	resp, err := s.Receive(v, &restError)
+
+	// Error payloads are not always JSON (ie: proxies), so the status code wins over decoding errors
+	if resp != nil && (resp.StatusCode < 200 || resp.StatusCode >= 300) {
+		restError.Code = resp.StatusCode
+		return restError
+	}
+
+	return err
+}
+
+// NewRestClient create a new RestClient authenticated with given credentials, sending the requests with httpClient,
+// or http.DefaultClient when nil
+func NewRestClient(url string, username string, password string, httpClient *http.Client) *RestClient {
+	return &RestClient{
+		sling: sling.New().
+			Client(httpClient).
+			Base(strings.TrimRight(url, "/")+"/rest/").
+			SetBasicAuth(username, password).
+			Set("Accept", "application/json"),
+	}
+}
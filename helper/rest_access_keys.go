@@ -0,0 +1,285 @@
+// Package helper provides handy func and struct to be reused in commands
+package helper
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/daeMOn63/bitclient"
+)
+
+// SSHKey define a public ssh key registered on Bitbucket
+type SSHKey struct {
+	ID    int    `json:"id,omitempty"`
+	Text  string `json:"text"`
+	Label string `json:"label,omitempty"`
+}
+
+// AccessKey define an ssh key granted on a repository or a project
+type AccessKey struct {
+	Key        SSHKey `json:"key"`
+	Permission string `json:"permission"`
+}
+
+// RepositoryAccessKey is an access key granted on a repository, as listed by the key endpoints
+type RepositoryAccessKey struct {
+	AccessKey
+	Repository bitclient.Repository `json:"repository"`
+}
+
+// ProjectAccessKey is an access key granted on a project, as listed by the key endpoints
+type ProjectAccessKey struct {
+	AccessKey
+	Project bitclient.Project `json:"project"`
+}
+
+// Fingerprint compute the SHA256 fingerprint of the key, as displayed by ssh-keygen -l
+func (k SSHKey) Fingerprint() (string, error) {
+	blob, err := k.blob()
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(blob)
+
+	return "SHA256:" + base64.RawStdEncoding.EncodeToString(sum[:]), nil
+}
+
+// LegacyFingerprint compute the MD5 fingerprint of the key, as displayed by ssh-keygen -l -E md5
+func (k SSHKey) LegacyFingerprint() (string, error) {
+	blob, err := k.blob()
+	if err != nil {
+		return "", err
+	}
+
+	sum := md5.Sum(blob)
+
+	var parts []string
+	for _, b := range sum {
+		parts = append(parts, fmt.Sprintf("%02x", b))
+	}
+
+	return "MD5:" + strings.Join(parts, ":"), nil
+}
+
+// MatchFingerprint tell if the key match given fingerprint, either in SHA256 or MD5 format. Prefix is optional.
+func (k SSHKey) MatchFingerprint(fingerprint string) bool {
+	sha, err := k.Fingerprint()
+	if err != nil {
+		return false
+	}
+	legacy, _ := k.LegacyFingerprint()
+
+	switch fingerprint {
+	case sha, strings.TrimPrefix(sha, "SHA256:"), legacy, strings.TrimPrefix(legacy, "MD5:"):
+		return true
+	}
+
+	return false
+}
+
+// blob decode the base64 part of the key text (ie: ssh-rsa <blob> comment)
+func (k SSHKey) blob() ([]byte, error) {
+	fields := strings.Fields(k.Text)
+	if len(fields) < 2 {
+		return nil, fmt.Errorf("invalid ssh public key %q", k.Text)
+	}
+
+	return base64.StdEncoding.DecodeString(fields[1])
+}
+
+// ReadSSHKey load a public ssh key from given file. The key comment is used as label.
+func ReadSSHKey(filename string) (SSHKey, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return SSHKey{}, err
+	}
+
+	key := SSHKey{Text: strings.TrimSpace(string(data))}
+	if _, err := key.blob(); err != nil {
+		return SSHKey{}, fmt.Errorf("%s does not contain a valid ssh public key", filename)
+	}
+
+	fields := strings.Fields(key.Text)
+	if len(fields) > 2 {
+		key.Label = strings.Join(fields[2:], " ")
+	}
+
+	return key, nil
+}
+
+// ReadSSHKeys load the public keys from given files
+func ReadSSHKeys(filenames []string) ([]SSHKey, error) {
+	var keys []SSHKey
+
+	for _, filename := range filenames {
+		key, err := ReadSSHKey(filename)
+		if err != nil {
+			return nil, err
+		}
+
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+// ResolveFingerprint return the given fingerprint, or the fingerprint of the key file when provided instead
+func ResolveFingerprint(fingerprint string, keyFile string) (string, error) {
+	if len(fingerprint) > 0 && len(keyFile) > 0 {
+		return "", errors.New("--fingerprint and --key-file cannot be used together")
+	}
+
+	if len(keyFile) > 0 {
+		key, err := ReadSSHKey(keyFile)
+		if err != nil {
+			return "", err
+		}
+
+		return key.Fingerprint()
+	}
+
+	if len(fingerprint) == 0 {
+		return "", errors.New("one of --fingerprint or --key-file flag is required")
+	}
+
+	return fingerprint, nil
+}
+
+// AccessKeyPermission convert a read / write permission to the api value for given scope (REPO or PROJECT)
+func AccessKeyPermission(scope string, permission string) (string, error) {
+	switch permission {
+	case "read", scope + "_READ":
+		return scope + "_READ", nil
+	case "write", scope + "_WRITE":
+		return scope + "_WRITE", nil
+	}
+
+	return "", fmt.Errorf("invalid permission %s, must be one of read, write", permission)
+}
+
+// FindAccessKey return the key matching the fingerprint, or nil when not found
+func FindAccessKey(keys []AccessKey, fingerprint string) *AccessKey {
+	for i, key := range keys {
+		if key.Key.MatchFingerprint(fingerprint) {
+			return &keys[i]
+		}
+	}
+
+	return nil
+}
+
+// String convert the access key to a readable string
+func (k AccessKey) String() string {
+	fingerprint, err := k.Key.Fingerprint()
+	if err != nil {
+		fingerprint = "invalid key"
+	}
+
+	return fmt.Sprintf("#%d [%s] %s %s", k.Key.ID, k.Permission, fingerprint, k.Key.Label)
+}
+
+func repositoryAccessKeysPath(projectKey string, repositorySlug string) string {
+	return fmt.Sprintf("keys/1.0/projects/%s/repos/%s/ssh", projectKey, repositorySlug)
+}
+
+func projectAccessKeysPath(projectKey string) string {
+	return fmt.Sprintf("keys/1.0/projects/%s/ssh", projectKey)
+}
+
+// GetRepositoryAccessKeys retrieve the access keys of given repository
+func (rc *RestClient) GetRepositoryAccessKeys(projectKey string, repositorySlug string) ([]AccessKey, error) {
+	var keys []AccessKey
+
+	err := rc.GetPaged(repositoryAccessKeysPath(projectKey, repositorySlug), nil, &keys)
+
+	return keys, err
+}
+
+// AddRepositoryAccessKey grant the key on given repository with given permission (REPO_READ or REPO_WRITE)
+func (rc *RestClient) AddRepositoryAccessKey(projectKey string, repositorySlug string, key SSHKey, permission string) (AccessKey, error) {
+	created := AccessKey{}
+
+	err := rc.Post(
+		repositoryAccessKeysPath(projectKey, repositorySlug),
+		AccessKey{Key: key, Permission: permission},
+		&created,
+	)
+
+	return created, err
+}
+
+// DeleteRepositoryAccessKey revoke the key with given id from given repository
+func (rc *RestClient) DeleteRepositoryAccessKey(projectKey string, repositorySlug string, keyID int) error {
+	return rc.Delete(fmt.Sprintf("%s/%d", repositoryAccessKeysPath(projectKey, repositorySlug), keyID))
+}
+
+// UpdateRepositoryAccessKeyPermission change the permission (REPO_READ or REPO_WRITE) of the key with given id on given
+// repository
+func (rc *RestClient) UpdateRepositoryAccessKeyPermission(projectKey string, repositorySlug string, keyID int, permission string) (AccessKey, error) {
+	updated := AccessKey{}
+
+	err := rc.Put(
+		fmt.Sprintf("%s/%d/permission/%s", repositoryAccessKeysPath(projectKey, repositorySlug), keyID, permission),
+		nil,
+		&updated,
+	)
+
+	return updated, err
+}
+
+// GetProjectAccessKeys retrieve the access keys of given project
+func (rc *RestClient) GetProjectAccessKeys(projectKey string) ([]AccessKey, error) {
+	var keys []AccessKey
+
+	err := rc.GetPaged(projectAccessKeysPath(projectKey), nil, &keys)
+
+	return keys, err
+}
+
+// AddProjectAccessKey grant the key on given project with given permission (PROJECT_READ or PROJECT_WRITE)
+func (rc *RestClient) AddProjectAccessKey(projectKey string, key SSHKey, permission string) (AccessKey, error) {
+	created := AccessKey{}
+
+	err := rc.Post(projectAccessKeysPath(projectKey), AccessKey{Key: key, Permission: permission}, &created)
+
+	return created, err
+}
+
+// DeleteProjectAccessKey revoke the key with given id from given project
+func (rc *RestClient) DeleteProjectAccessKey(projectKey string, keyID int) error {
+	return rc.Delete(fmt.Sprintf("%s/%d", projectAccessKeysPath(projectKey), keyID))
+}
+
+// UpdateProjectAccessKeyPermission change the permission (PROJECT_READ or PROJECT_WRITE) of the key with given id on
+// given project
+func (rc *RestClient) UpdateProjectAccessKeyPermission(projectKey string, keyID int, permission string) (AccessKey, error) {
+	updated := AccessKey{}
+
+	err := rc.Put(fmt.Sprintf("%s/%d/permission/%s", projectAccessKeysPath(projectKey), keyID, permission), nil, &updated)
+
+	return updated, err
+}
+
+// GetAccessKeyRepositories retrieve every repository the key with given id is granted on
+func (rc *RestClient) GetAccessKeyRepositories(keyID int) ([]RepositoryAccessKey, error) {
+	var keys []RepositoryAccessKey
+
+	err := rc.GetPaged(fmt.Sprintf("keys/1.0/ssh/%d/repos", keyID), nil, &keys)
+
+	return keys, err
+}
+
+// GetAccessKeyProjects retrieve every project the key with given id is granted on
+func (rc *RestClient) GetAccessKeyProjects(keyID int) ([]ProjectAccessKey, error) {
+	var keys []ProjectAccessKey
+
+	err := rc.GetPaged(fmt.Sprintf("keys/1.0/ssh/%d/projects", keyID), nil, &keys)
+
+	return keys, err
+}
@@ -0,0 +1,87 @@
+// Package helper provides handy func and struct to be reused in commands
+package helper
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/daeMOn63/bitclient"
+)
+
+// Branch define a repository branch as returned by the Bitbucket api
+type Branch struct {
+	ID           string `json:"id"`
+	DisplayID    string `json:"displayId"`
+	LatestCommit string `json:"latestCommit"`
+	IsDefault    bool   `json:"isDefault"`
+}
+
+// BranchesRequest hold the query parameters of the branches endpoint
+type BranchesRequest struct {
+	FilterText string `url:"filterText,omitempty"`
+	Start      uint   `url:"start,omitempty"`
+	Limit      uint   `url:"limit,omitempty"`
+}
+
+// BranchesResponse hold a page of branches
+type BranchesResponse struct {
+	Values        []Branch `json:"values"`
+	IsLastPage    bool     `json:"isLastPage"`
+	NextPageStart uint     `json:"nextPageStart"`
+}
+
+// GetBranches retrieve a page of branches of given repository
+func (rc *RestClient) GetBranches(projectKey string, repositorySlug string, params BranchesRequest) (BranchesResponse, error) {
+	response := BranchesResponse{}
+
+	err := rc.Get(
+		fmt.Sprintf("api/1.0/projects/%s/repos/%s/branches", projectKey, repositorySlug),
+		params,
+		&response,
+	)
+
+	return response, err
+}
+
+// FindBranch lookup for the branch matching exactly given refID in given repository. Both full refs
+// (ie: refs/heads/master) and branch names (ie: master) are accepted.
+func (rc *RestClient) FindBranch(projectKey string, repositorySlug string, refID string) (Branch, error) {
+	var branches []Branch
+
+	// The filter match the branch names, every page is read as it match them partially
+	err := rc.GetPaged(
+		fmt.Sprintf("api/1.0/projects/%s/repos/%s/branches", projectKey, repositorySlug),
+		BranchesRequest{FilterText: strings.TrimPrefix(refID, "refs/heads/")},
+		&branches,
+	)
+	if err != nil {
+		return Branch{}, err
+	}
+
+	for _, branch := range branches {
+		if branch.ID == refID || branch.DisplayID == refID {
+			return branch, nil
+		}
+	}
+
+	return Branch{}, fmt.Errorf("cannot find branch %s in repository %s/%s", refID, projectKey, repositorySlug)
+}
+
+// GetAllBranches retrieve every branch of given repository
+func (rc *RestClient) GetAllBranches(projectKey string, repositorySlug string) ([]Branch, error) {
+	var branches []Branch
+
+	err := rc.GetPaged(fmt.Sprintf("api/1.0/projects/%s/repos/%s/branches", projectKey, repositorySlug), nil, &branches)
+
+	return branches, err
+}
+
+// GetProjectBranchRestrictions retrieve the branch restrictions defined on given project, applied to all its
+// repositories. bitclient only read the restrictions of a repository.
+func (rc *RestClient) GetProjectBranchRestrictions(projectKey string) ([]bitclient.BranchRestriction, error) {
+	var restrictions []bitclient.BranchRestriction
+
+	err := rc.GetPaged(fmt.Sprintf("branch-permissions/2.0/projects/%s/restrictions", projectKey), nil, &restrictions)
+
+	return restrictions, err
+}
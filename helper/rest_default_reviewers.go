@@ -0,0 +1,77 @@
+// Package helper provides handy func and struct to be reused in commands
+package helper
+
+import (
+	"fmt"
+
+	"github.com/daeMOn63/bitclient"
+)
+
+// Matcher types supported by the default reviewers conditions
+const (
+	MatcherTypeAnyRef        = "ANY_REF"
+	MatcherTypeBranch        = "BRANCH"
+	MatcherTypePattern       = "PATTERN"
+	MatcherTypeModelCategory = "MODEL_CATEGORY"
+	MatcherTypeModelBranch   = "MODEL_BRANCH"
+)
+
+// anyRefMatcherID is the fixed identifier of the ANY_REF matcher
+const anyRefMatcherID = "ANY_REF_MATCHER_ID"
+
+// NewRefMatcher create a bitclient.Matcher of given type. The id is ignored for ANY_REF matchers.
+func NewRefMatcher(matcherType string, id string) (bitclient.Matcher, error) {
+	switch matcherType {
+	case MatcherTypeAnyRef:
+		return bitclient.Matcher{
+			Id:     anyRefMatcherID,
+			Type:   bitclient.MatcherType{Id: MatcherTypeAnyRef},
+			Active: true,
+		}, nil
+	case MatcherTypeBranch, MatcherTypePattern, MatcherTypeModelCategory, MatcherTypeModelBranch:
+		if len(id) == 0 {
+			return bitclient.Matcher{}, fmt.Errorf("a matcher value is required for %s matchers", matcherType)
+		}
+
+		return bitclient.Matcher{
+			Id:        id,
+			DisplayId: id,
+			Type:      bitclient.MatcherType{Id: matcherType},
+			Active:    true,
+		}, nil
+	}
+
+	return bitclient.Matcher{}, fmt.Errorf(
+		"unsupported matcher type %s, must be one of %s, %s, %s, %s or %s",
+		matcherType,
+		MatcherTypeAnyRef,
+		MatcherTypeBranch,
+		MatcherTypePattern,
+		MatcherTypeModelCategory,
+		MatcherTypeModelBranch,
+	)
+}
+
+// FormatRefMatcher convert the matcher to a readable string
+func FormatRefMatcher(m bitclient.Matcher) string {
+	if m.Type.Id == MatcherTypeAnyRef {
+		return MatcherTypeAnyRef
+	}
+
+	return fmt.Sprintf("%s:%s", m.Type.Id, m.Id)
+}
+
+// RefMatchersEqual tell if both matchers target the same refs
+func RefMatchersEqual(m bitclient.Matcher, other bitclient.Matcher) bool {
+	if m.Type.Id != other.Type.Id {
+		return false
+	}
+
+	return m.Type.Id == MatcherTypeAnyRef || m.Id == other.Id
+}
+
+// DeleteDefaultReviewersCondition remove the default reviewers condition with given id.
+// bitclient can list, create and update the conditions but provide no way to delete them.
+func (rc *RestClient) DeleteDefaultReviewersCondition(projectKey string, repositorySlug string, id int) error {
+	return rc.Delete(fmt.Sprintf("default-reviewers/1.0/projects/%s/repos/%s/condition/%d", projectKey, repositorySlug, id))
+}
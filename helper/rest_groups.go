@@ -0,0 +1,59 @@
+// Package helper provides handy func and struct to be reused in commands
+package helper
+
+import (
+	"fmt"
+
+	"github.com/daeMOn63/bitclient"
+)
+
+// groupEntry is a group as returned by the admin groups endpoint
+type groupEntry struct {
+	Name string `json:"name"`
+}
+
+// PermissionSnapshot hold the users and groups permissions of a repository or a project at a given time
+type PermissionSnapshot struct {
+	Users  []bitclient.UserPermission  `json:"users"`
+	Groups []bitclient.GroupPermission `json:"groups"`
+}
+
+// GetGroups retrieve the names of all the groups
+func (rc *RestClient) GetGroups() ([]string, error) {
+	var entries []groupEntry
+
+	err := rc.GetPaged("api/1.0/admin/groups", nil, &entries)
+	if err != nil {
+		return nil, err
+	}
+
+	var groups []string
+	for _, entry := range entries {
+		groups = append(groups, entry.Name)
+	}
+
+	return groups, nil
+}
+
+// permissionsPath give the permissions endpoint of a repository, or of the project when repositorySlug is empty
+func permissionsPath(projectKey string, repositorySlug string, kind string) string {
+	if len(repositorySlug) == 0 {
+		return fmt.Sprintf("api/1.0/projects/%s/permissions/%s", projectKey, kind)
+	}
+
+	return fmt.Sprintf("api/1.0/projects/%s/repos/%s/permissions/%s", projectKey, repositorySlug, kind)
+}
+
+// GetPermissions retrieve the users and groups permissions of a repository, or of the project when repositorySlug is empty
+func (rc *RestClient) GetPermissions(projectKey string, repositorySlug string) (PermissionSnapshot, error) {
+	snapshot := PermissionSnapshot{}
+
+	err := rc.GetPaged(permissionsPath(projectKey, repositorySlug, "users"), nil, &snapshot.Users)
+	if err != nil {
+		return snapshot, err
+	}
+
+	err = rc.GetPaged(permissionsPath(projectKey, repositorySlug, "groups"), nil, &snapshot.Groups)
+
+	return snapshot, err
+}
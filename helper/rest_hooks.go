@@ -0,0 +1,134 @@
+// Package helper provides handy func and struct to be reused in commands
+package helper
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
+// HookDetails describe an installed hook plugin
+type HookDetails struct {
+	Key           string `json:"key"`
+	Name          string `json:"name"`
+	Type          string `json:"type"`
+	Description   string `json:"description"`
+	Version       string `json:"version"`
+	ConfigFormKey string `json:"configFormKey"`
+}
+
+// HookScope tell where the hook state is defined (PROJECT or REPOSITORY)
+type HookScope struct {
+	Type       string `json:"type"`
+	ResourceID int    `json:"resourceId"`
+}
+
+// RepositoryHook define the state of a hook on a repository or a project
+type RepositoryHook struct {
+	Details    HookDetails `json:"details"`
+	Enabled    bool        `json:"enabled"`
+	Configured bool        `json:"configured"`
+	Scope      HookScope   `json:"scope"`
+}
+
+// Inherited tell if the hook state of a repository come from its project
+func (h RepositoryHook) Inherited() bool {
+	return h.Scope.Type == "PROJECT"
+}
+
+// hooksPath return the hooks settings path of a repository, or of the project when repositorySlug is empty
+func hooksPath(projectKey string, repositorySlug string) string {
+	if len(repositorySlug) == 0 {
+		return fmt.Sprintf("api/1.0/projects/%s/settings/hooks", projectKey)
+	}
+
+	return fmt.Sprintf("api/1.0/projects/%s/repos/%s/settings/hooks", projectKey, repositorySlug)
+}
+
+func hookPath(projectKey string, repositorySlug string, hookKey string) string {
+	return fmt.Sprintf("%s/%s", hooksPath(projectKey, repositorySlug), url.PathEscape(hookKey))
+}
+
+// GetRepositoryHooks retrieve all the hooks of given repository, or of the project when repositorySlug is empty
+func (rc *RestClient) GetRepositoryHooks(projectKey string, repositorySlug string) ([]RepositoryHook, error) {
+	var hooks []RepositoryHook
+
+	err := rc.GetPaged(hooksPath(projectKey, repositorySlug), nil, &hooks)
+
+	return hooks, err
+}
+
+// GetRepositoryHook retrieve a single hook of given repository
+func (rc *RestClient) GetRepositoryHook(projectKey string, repositorySlug string, hookKey string) (RepositoryHook, error) {
+	hook := RepositoryHook{}
+
+	err := rc.Get(hookPath(projectKey, repositorySlug, hookKey), nil, &hook)
+
+	return hook, err
+}
+
+// EnableRepositoryHook turn on the hook on given repository, or on the project when repositorySlug is empty.
+// When not nil, settings are saved along.
+func (rc *RestClient) EnableRepositoryHook(projectKey string, repositorySlug string, hookKey string, settings interface{}) (RepositoryHook, error) {
+	hook := RepositoryHook{}
+
+	err := rc.Put(hookPath(projectKey, repositorySlug, hookKey)+"/enabled", settings, &hook)
+
+	return hook, err
+}
+
+// DisableRepositoryHook turn off the hook on given repository, or on the project when repositorySlug is empty
+func (rc *RestClient) DisableRepositoryHook(projectKey string, repositorySlug string, hookKey string) error {
+	return rc.Delete(hookPath(projectKey, repositorySlug, hookKey) + "/enabled")
+}
+
+// GetRepositoryHookSettings retrieve the raw settings of the hook on given repository, or on the project when repositorySlug is empty
+func (rc *RestClient) GetRepositoryHookSettings(projectKey string, repositorySlug string, hookKey string) (json.RawMessage, error) {
+	var settings json.RawMessage
+
+	err := rc.Get(hookPath(projectKey, repositorySlug, hookKey)+"/settings", nil, &settings)
+
+	return settings, err
+}
+
+// SetRepositoryHookSettings replace the settings of the hook on given repository, or on the project when repositorySlug is empty
+func (rc *RestClient) SetRepositoryHookSettings(projectKey string, repositorySlug string, hookKey string, settings json.RawMessage) (json.RawMessage, error) {
+	var saved json.RawMessage
+
+	err := rc.Put(hookPath(projectKey, repositorySlug, hookKey)+"/settings", settings, &saved)
+
+	return saved, err
+}
+
+// InheritRepositoryHook drop the repository specific state and settings of the hook, so the project ones apply again
+func (rc *RestClient) InheritRepositoryHook(projectKey string, repositorySlug string, hookKey string) error {
+	return rc.Delete(hookPath(projectKey, repositorySlug, hookKey))
+}
+
+// HookSettingsHash compute a short hash of the hook settings, independent of the JSON keys order and formatting.
+// Empty settings give an empty hash.
+func HookSettingsHash(settings json.RawMessage) (string, error) {
+	if len(settings) == 0 {
+		return "", nil
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(settings, &value); err != nil {
+		return "", err
+	}
+	if value == nil {
+		return "", nil
+	}
+
+	// encoding/json sort map keys, giving a canonical representation
+	canonical, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(canonical)
+
+	return hex.EncodeToString(sum[:])[:12], nil
+}
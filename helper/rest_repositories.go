@@ -0,0 +1,42 @@
+// Package helper provides handy func and struct to be reused in commands
+package helper
+
+import (
+	"fmt"
+
+	"github.com/daeMOn63/bitclient"
+)
+
+// moveRepositoryRequest is the body of the repository update moving or renaming it
+type moveRepositoryRequest struct {
+	Name    string            `json:"name"`
+	Project moveProjectTarget `json:"project"`
+}
+
+// moveProjectTarget identify the project a repository is moved to
+type moveProjectTarget struct {
+	Key string `json:"key"`
+}
+
+// MoveRepository move the repository to targetProject under targetName, and return it as updated by Bitbucket.
+// bitclient.UpdateRepository drop the response, so the new slug computed by Bitbucket from the name would be unknown.
+func (rc *RestClient) MoveRepository(projectKey string, repositorySlug string, targetProject string, targetName string) (bitclient.Repository, error) {
+	repository := bitclient.Repository{}
+
+	err := rc.Put(
+		fmt.Sprintf("api/1.0/projects/%s/repos/%s", projectKey, repositorySlug),
+		moveRepositoryRequest{Name: targetName, Project: moveProjectTarget{Key: targetProject}},
+		&repository,
+	)
+
+	return repository, err
+}
+
+// GetRepository retrieve a single repository of given project
+func (rc *RestClient) GetRepository(projectKey string, repositorySlug string) (bitclient.Repository, error) {
+	repository := bitclient.Repository{}
+
+	err := rc.Get(fmt.Sprintf("api/1.0/projects/%s/repos/%s", projectKey, repositorySlug), nil, &repository)
+
+	return repository, err
+}
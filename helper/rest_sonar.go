@@ -0,0 +1,48 @@
+// Package helper provides handy func and struct to be reused in commands
+package helper
+
+import (
+	"fmt"
+)
+
+// SonarSettings hold the raw Sonar for Bitbucket settings, grouped by section (project, issues, mergeChecks...).
+// Settings are kept raw so fields unknown to bitadmin are preserved on updates.
+type SonarSettings map[string]interface{}
+
+// Set change a single field of given section
+func (s SonarSettings) Set(section string, field string, value interface{}) {
+	fields, ok := s[section].(map[string]interface{})
+	if !ok {
+		fields = make(map[string]interface{})
+		s[section] = fields
+	}
+
+	fields[field] = value
+}
+
+// sonarSettingsPath return the sonar settings path of a repository, or of the project when repositorySlug is empty
+func sonarSettingsPath(projectKey string, repositorySlug string) string {
+	if len(repositorySlug) == 0 {
+		return fmt.Sprintf("sonar4stash/1.0/projects/%s/settings", projectKey)
+	}
+
+	return fmt.Sprintf("sonar4stash/1.0/projects/%s/repos/%s/settings", projectKey, repositorySlug)
+}
+
+// GetSonarSettings retrieve the sonar settings of given repository, or the project defaults when repositorySlug is empty
+func (rc *RestClient) GetSonarSettings(projectKey string, repositorySlug string) (SonarSettings, error) {
+	settings := make(SonarSettings)
+
+	err := rc.Get(sonarSettingsPath(projectKey, repositorySlug), nil, &settings)
+
+	return settings, err
+}
+
+// SetSonarSettings save the sonar settings of given repository, or the project defaults when repositorySlug is empty
+func (rc *RestClient) SetSonarSettings(projectKey string, repositorySlug string, settings SonarSettings) (SonarSettings, error) {
+	saved := make(SonarSettings)
+
+	err := rc.Post(sonarSettingsPath(projectKey, repositorySlug), settings, &saved)
+
+	return saved, err
+}
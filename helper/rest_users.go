@@ -0,0 +1,89 @@
+// Package helper provides handy func and struct to be reused in commands
+package helper
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/daeMOn63/bitclient"
+)
+
+// groupMembersRequest hold the query parameters of the group members endpoint
+type groupMembersRequest struct {
+	Context string `url:"context"`
+}
+
+// GetUser retrieve a single user from its slug
+func (rc *RestClient) GetUser(slug string) (bitclient.User, error) {
+	user := bitclient.User{}
+
+	err := rc.Get(fmt.Sprintf("api/1.0/users/%s", slug), nil, &user)
+
+	return user, err
+}
+
+// GetGroupMembers retrieve all the users belonging to given group
+func (rc *RestClient) GetGroupMembers(group string) ([]bitclient.User, error) {
+	var users []bitclient.User
+
+	err := rc.GetPaged("api/1.0/admin/groups/more-members", groupMembersRequest{Context: group}, &users)
+
+	return users, err
+}
+
+// searchRequest hold the query parameters of the filtered listing endpoints
+type searchRequest struct {
+	Filter string `url:"filter,omitempty"`
+	Name   string `url:"name,omitempty"`
+	Limit  uint   `url:"limit,omitempty"`
+}
+
+// searchResponse hold a single page of a filtered listing
+type searchResponse struct {
+	Values json.RawMessage `json:"values"`
+}
+
+// search read the first page of a filtered listing, which is enough to suggest results
+func (rc *RestClient) search(path string, params searchRequest, v interface{}) error {
+	response := searchResponse{}
+
+	err := rc.Get(path, params, &response)
+	if err != nil || len(response.Values) == 0 {
+		return err
+	}
+
+	return json.Unmarshal(response.Values, v)
+}
+
+// SearchUsers retrieve the users whose name, display name or email contains term
+func (rc *RestClient) SearchUsers(term string, limit uint) ([]bitclient.User, error) {
+	var users []bitclient.User
+
+	err := rc.search("api/1.0/users", searchRequest{Filter: term, Limit: limit}, &users)
+
+	return users, err
+}
+
+// SearchProjects retrieve the projects whose name contains term
+func (rc *RestClient) SearchProjects(term string, limit uint) ([]bitclient.Project, error) {
+	var projects []bitclient.Project
+
+	err := rc.search("api/1.0/projects", searchRequest{Name: term, Limit: limit}, &projects)
+
+	return projects, err
+}
+
+// SearchRepositories retrieve the repositories whose name contains term, across all projects
+func (rc *RestClient) SearchRepositories(term string, limit uint) ([]bitclient.Repository, error) {
+	var repositories []bitclient.Repository
+
+	err := rc.search("api/1.0/repos", searchRequest{Name: term, Limit: limit}, &repositories)
+
+	return repositories, err
+}
+
+// CheckCredentials send an authenticated request, Bitbucket rejecting invalid credentials with a 401 even on the
+// endpoints allowing anonymous access
+func (rc *RestClient) CheckCredentials() error {
+	return rc.Get("api/1.0/projects", pageRequest{Limit: 1}, &pageResponse{})
+}
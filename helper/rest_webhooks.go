@@ -0,0 +1,160 @@
+// Package helper provides handy func and struct to be reused in commands
+package helper
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// webhookEventAliases map short event names to their Bitbucket event keys
+var webhookEventAliases = map[string]string{
+	"push":               "repo:refs_changed",
+	"repo-modified":      "repo:modified",
+	"fork":               "repo:forked",
+	"commit-comment":     "repo:comment:added",
+	"pr-opened":          "pr:opened",
+	"pr-from-ref-update": "pr:from_ref_updated",
+	"pr-modified":        "pr:modified",
+	"pr-reviewer-update": "pr:reviewer:updated",
+	"pr-approved":        "pr:reviewer:approved",
+	"pr-unapproved":      "pr:reviewer:unapproved",
+	"pr-needs-work":      "pr:reviewer:needs_work",
+	"pr-merged":          "pr:merged",
+	"pr-declined":        "pr:declined",
+	"pr-deleted":         "pr:deleted",
+	"pr-comment-added":   "pr:comment:added",
+	"pr-comment-edited":  "pr:comment:edited",
+	"pr-comment-deleted": "pr:comment:deleted",
+}
+
+// Webhook define an outbound webhook of a repository
+type Webhook struct {
+	ID            int               `json:"id,omitempty"`
+	Name          string            `json:"name"`
+	URL           string            `json:"url"`
+	Events        []string          `json:"events"`
+	Active        bool              `json:"active"`
+	Configuration map[string]string `json:"configuration,omitempty"`
+}
+
+// WebhookTestResponse hold the result of a webhook test
+type WebhookTestResponse struct {
+	Request struct {
+		URL    string `json:"url"`
+		Method string `json:"method"`
+	} `json:"request"`
+	Response struct {
+		StatusCode int    `json:"statusCode"`
+		Body       string `json:"body"`
+	} `json:"response"`
+}
+
+// webhookTestRequest hold the query parameters of the webhook test endpoint
+type webhookTestRequest struct {
+	URL string `url:"url"`
+}
+
+// String convert the webhook to a readable string
+func (w Webhook) String() string {
+	status := "DISABLED"
+	if w.Active == true {
+		status = "ENABLED "
+	}
+
+	return fmt.Sprintf("#%d [%s] %s %s (%s)", w.ID, status, w.Name, w.URL, strings.Join(w.Events, ", "))
+}
+
+// WebhookEvents convert event names, either short aliases (push, pr-merged...) or Bitbucket event keys, to event keys
+func WebhookEvents(names []string) ([]string, error) {
+	var events []string
+
+	for _, name := range names {
+		if event, ok := webhookEventAliases[name]; ok {
+			events = append(events, event)
+			continue
+		}
+
+		if strings.Contains(name, ":") {
+			events = append(events, name)
+			continue
+		}
+
+		return nil, fmt.Errorf("unknown webhook event %s, must be one of %s or a Bitbucket event key", name, strings.Join(WebhookEventAliases(), ", "))
+	}
+
+	return events, nil
+}
+
+// WebhookEventAliases list the supported short event names
+func WebhookEventAliases() []string {
+	var aliases []string
+	for alias := range webhookEventAliases {
+		aliases = append(aliases, alias)
+	}
+
+	sort.Strings(aliases)
+
+	return aliases
+}
+
+// FindWebhooksByURL return the webhooks pointing at given url
+func FindWebhooksByURL(webhooks []Webhook, url string) []Webhook {
+	var found []Webhook
+
+	for _, webhook := range webhooks {
+		if strings.TrimRight(webhook.URL, "/") == strings.TrimRight(url, "/") {
+			found = append(found, webhook)
+		}
+	}
+
+	return found
+}
+
+func webhooksPath(projectKey string, repositorySlug string) string {
+	return fmt.Sprintf("api/1.0/projects/%s/repos/%s/webhooks", projectKey, repositorySlug)
+}
+
+// GetWebhooks retrieve the webhooks of given repository
+func (rc *RestClient) GetWebhooks(projectKey string, repositorySlug string) ([]Webhook, error) {
+	var webhooks []Webhook
+
+	err := rc.GetPaged(webhooksPath(projectKey, repositorySlug), nil, &webhooks)
+
+	return webhooks, err
+}
+
+// CreateWebhook add a new webhook on given repository
+func (rc *RestClient) CreateWebhook(projectKey string, repositorySlug string, webhook Webhook) (Webhook, error) {
+	created := Webhook{}
+
+	err := rc.Post(webhooksPath(projectKey, repositorySlug), webhook, &created)
+
+	return created, err
+}
+
+// UpdateWebhook replace the webhook identified by webhook.ID
+func (rc *RestClient) UpdateWebhook(projectKey string, repositorySlug string, webhook Webhook) (Webhook, error) {
+	updated := Webhook{}
+
+	err := rc.Put(fmt.Sprintf("%s/%d", webhooksPath(projectKey, repositorySlug), webhook.ID), webhook, &updated)
+
+	return updated, err
+}
+
+// DeleteWebhook remove the webhook with given id
+func (rc *RestClient) DeleteWebhook(projectKey string, repositorySlug string, id int) error {
+	return rc.Delete(fmt.Sprintf("%s/%d", webhooksPath(projectKey, repositorySlug), id))
+}
+
+// TestWebhook ask Bitbucket to send a test request to given url
+func (rc *RestClient) TestWebhook(projectKey string, repositorySlug string, url string) (WebhookTestResponse, error) {
+	response := WebhookTestResponse{}
+
+	err := rc.do(
+		rc.sling.New().Post(webhooksPath(projectKey, repositorySlug)+"/test").QueryStruct(webhookTestRequest{URL: url}),
+		&response,
+	)
+
+	return response, err
+}
@@ -0,0 +1,183 @@
+// Package helper provides handy func and struct to be reused in commands
+package helper
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy define how failed api calls are retried
+type RetryPolicy struct {
+	MaxRetries   int
+	InitialDelay time.Duration
+	MaxDelay     time.Duration
+}
+
+// Delay give the exponential backoff of given attempt (starting at 0), with jitter.
+// The delay is randomly picked between half and the full backoff, so concurrent clients don't retry all at once.
+func (p RetryPolicy) Delay(attempt int) time.Duration {
+	delay := p.InitialDelay
+	for i := 0; i < attempt && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+
+	if delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+
+	if delay <= 1 {
+		return delay
+	}
+
+	half := delay / 2
+
+	return half + time.Duration(rand.Int63n(int64(delay-half)))
+}
+
+// RetryTransport is a http.RoundTripper retrying idempotent requests on rate limiting, unavailability, timeouts and
+// connection resets
+type RetryTransport struct {
+	Next   http.RoundTripper
+	Policy RetryPolicy
+	// Verbose receive a line for every retry, nil to stay quiet
+	Verbose io.Writer
+}
+
+// NewRetryTransport create a RetryTransport wrapping next, or http.DefaultTransport when nil
+func NewRetryTransport(next http.RoundTripper, policy RetryPolicy, verbose io.Writer) *RetryTransport {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	return &RetryTransport{
+		Next:    next,
+		Policy:  policy,
+		Verbose: verbose,
+	}
+}
+
+// retryableMethods can be safely sent multiple times. POST are never retried as they may create duplicates, and
+// DELETE as a retried delete of an already deleted resource fail with a misleading 404.
+var retryableMethods = map[string]bool{
+	http.MethodGet:  true,
+	http.MethodHead: true,
+	http.MethodPut:  true,
+}
+
+// retryableStatus are the status codes telling the request was not processed: rate limiting and unavailability.
+// Other server errors may come after a partial processing, or will fail the same way again.
+var retryableStatus = map[int]bool{
+	http.StatusTooManyRequests:    true,
+	http.StatusServiceUnavailable: true,
+}
+
+// isRetryableError tell if the request failed on a timeout or a connection reset. DNS and TLS failures come from the
+// configuration, retrying them would only delay the error.
+func isRetryableError(err error) bool {
+	var dnsError *net.DNSError
+	if errors.As(err, &dnsError) || strings.Contains(err.Error(), "tls:") || strings.Contains(err.Error(), "TLS handshake") {
+		return false
+	}
+
+	if errors.Is(err, syscall.ECONNRESET) {
+		return true
+	}
+
+	var netError net.Error
+	return errors.As(err, &netError) && netError.Timeout()
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *RetryTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	if !retryableMethods[request.Method] || t.Policy.MaxRetries <= 0 {
+		return t.Next.RoundTrip(request)
+	}
+
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 && request.GetBody != nil {
+			body, err := request.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			request.Body = body
+		}
+
+		response, err := t.Next.RoundTrip(request)
+
+		if attempt >= t.Policy.MaxRetries {
+			return response, err
+		}
+		if err != nil && !isRetryableError(err) {
+			return response, err
+		}
+		if err == nil && !retryableStatus[response.StatusCode] {
+			return response, err
+		}
+
+		// A body which cannot be replayed would be sent empty, better fail now
+		if request.Body != nil && request.GetBody == nil {
+			return response, err
+		}
+
+		delay := t.Policy.Delay(attempt)
+		reason := ""
+
+		if err != nil {
+			reason = err.Error()
+		} else {
+			reason = response.Status
+			if retryAfter, ok := parseRetryAfter(response.Header.Get("Retry-After")); ok {
+				delay = retryAfter
+			}
+
+			// Drain the body so the connection can be reused
+			_, _ = io.Copy(ioutil.Discard, response.Body)
+			if err := response.Body.Close(); err != nil {
+				return nil, err
+			}
+		}
+
+		if t.Verbose != nil {
+			fmt.Fprintf(t.Verbose, "[RETRY] %s %s: %s, retrying in %s (%d/%d)\n",
+				request.Method, request.URL, reason, delay.Round(time.Millisecond), attempt+1, t.Policy.MaxRetries)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-request.Context().Done():
+			timer.Stop()
+			return nil, request.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// parseRetryAfter read a Retry-After header value, given either in seconds or as a http date
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if len(value) == 0 {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		delay := time.Until(date)
+		if delay < 0 {
+			delay = 0
+		}
+		return delay, true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,179 @@
+package helper
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyDelay(t *testing.T) {
+	policy := RetryPolicy{MaxRetries: 5, InitialDelay: time.Second, MaxDelay: 10 * time.Second}
+
+	tests := []struct {
+		attempt int
+		backoff time.Duration
+	}{
+		{0, time.Second},
+		{1, 2 * time.Second},
+		{3, 8 * time.Second},
+		{4, 10 * time.Second},
+		{20, 10 * time.Second},
+	}
+
+	for _, test := range tests {
+		// The jitter pick the delay between half and the full backoff
+		for i := 0; i < 100; i++ {
+			delay := policy.Delay(test.attempt)
+			if delay < test.backoff/2 || delay > test.backoff {
+				t.Fatalf("Delay(%d) = %s, expected between %s and %s", test.attempt, delay, test.backoff/2, test.backoff)
+			}
+		}
+	}
+
+	if delay := (RetryPolicy{}).Delay(3); delay != 0 {
+		t.Errorf("Delay without initial delay = %s, expected 0", delay)
+	}
+}
+
+func TestRetryableMethods(t *testing.T) {
+	tests := map[string]bool{
+		http.MethodGet:    true,
+		http.MethodHead:   true,
+		http.MethodPut:    true,
+		http.MethodPost:   false,
+		http.MethodDelete: false,
+		http.MethodPatch:  false,
+	}
+
+	for method, retryable := range tests {
+		if retryableMethods[method] != retryable {
+			t.Errorf("retryableMethods[%s] = %t, expected %t", method, !retryable, retryable)
+		}
+	}
+}
+
+func TestRetryableStatus(t *testing.T) {
+	tests := map[int]bool{
+		http.StatusTooManyRequests:     true,
+		http.StatusServiceUnavailable:  true,
+		http.StatusOK:                  false,
+		http.StatusNotFound:            false,
+		http.StatusConflict:            false,
+		http.StatusInternalServerError: false,
+		http.StatusBadGateway:          false,
+		http.StatusGatewayTimeout:      false,
+	}
+
+	for status, retryable := range tests {
+		if retryableStatus[status] != retryable {
+			t.Errorf("retryableStatus[%d] = %t, expected %t", status, !retryable, retryable)
+		}
+	}
+}
+
+// timeoutError is a net.Error reporting a timeout
+type timeoutError struct {
+	message string
+}
+
+func (e timeoutError) Error() string   { return e.message }
+func (e timeoutError) Timeout() bool   { return true }
+func (e timeoutError) Temporary() bool { return true }
+
+func TestIsRetryableError(t *testing.T) {
+	urlError := func(err error) error {
+		return &url.Error{Op: "Get", URL: "http://stash.server.com", Err: err}
+	}
+
+	tests := []struct {
+		name      string
+		err       error
+		retryable bool
+	}{
+		{"timeout", urlError(timeoutError{"i/o timeout"}), true},
+		{"connection reset", urlError(&net.OpError{Op: "read", Err: os.NewSyscallError("read", syscall.ECONNRESET)}), true},
+		{"connection refused", urlError(&net.OpError{Op: "dial", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}), false},
+		{"dns", urlError(&net.OpError{Op: "dial", Err: &net.DNSError{Err: "no such host", Name: "stash.server.com", IsTimeout: true}}), false},
+		{"tls", urlError(errors.New("remote error: tls: handshake failure")), false},
+		{"tls handshake timeout", urlError(timeoutError{"net/http: TLS handshake timeout"}), false},
+		{"other", errors.New("unexpected EOF"), false},
+	}
+
+	for _, test := range tests {
+		if retryable := isRetryableError(test.err); retryable != test.retryable {
+			t.Errorf("isRetryableError(%s) = %t, expected %t", test.name, retryable, test.retryable)
+		}
+	}
+}
+
+// roundTripFunc is a http.RoundTripper calling itself
+type roundTripFunc func(request *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(request *http.Request) (*http.Response, error) {
+	return f(request)
+}
+
+func TestRetryTransport(t *testing.T) {
+	tests := []struct {
+		method   string
+		status   int
+		attempts int
+	}{
+		{http.MethodGet, http.StatusServiceUnavailable, 3},
+		{http.MethodPut, http.StatusTooManyRequests, 3},
+		{http.MethodGet, http.StatusInternalServerError, 1},
+		{http.MethodGet, http.StatusOK, 1},
+		{http.MethodPost, http.StatusServiceUnavailable, 1},
+		{http.MethodDelete, http.StatusServiceUnavailable, 1},
+	}
+
+	for _, test := range tests {
+		attempts := 0
+		transport := NewRetryTransport(roundTripFunc(func(request *http.Request) (*http.Response, error) {
+			attempts++
+			return &http.Response{StatusCode: test.status, Status: http.StatusText(test.status), Body: http.NoBody, Header: http.Header{}}, nil
+		}), RetryPolicy{MaxRetries: 2, InitialDelay: time.Nanosecond, MaxDelay: time.Nanosecond}, nil)
+
+		request, err := http.NewRequest(test.method, "http://stash.server.com/rest/api/1.0/projects", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		response, err := transport.RoundTrip(request)
+		if err != nil {
+			t.Fatalf("%s %d unexpected error: %s", test.method, test.status, err)
+		}
+		if response.StatusCode != test.status {
+			t.Errorf("%s %d answered %d", test.method, test.status, response.StatusCode)
+		}
+		if attempts != test.attempts {
+			t.Errorf("%s %d sent %d times, expected %d", test.method, test.status, attempts, test.attempts)
+		}
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		value string
+		delay time.Duration
+		ok    bool
+	}{
+		{"", 0, false},
+		{"30", 30 * time.Second, true},
+		{"-1", 0, false},
+		{"soon", 0, false},
+		{"Mon, 02 Jan 2006 15:04:05 GMT", 0, true},
+	}
+
+	for _, test := range tests {
+		delay, ok := parseRetryAfter(test.value)
+		if delay != test.delay || ok != test.ok {
+			t.Errorf("parseRetryAfter(%q) = %s %t, expected %s %t", test.value, delay, ok, test.delay, test.ok)
+		}
+	}
+}
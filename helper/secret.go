@@ -0,0 +1,26 @@
+// Package helper provides handy func and struct to be reused in commands
+package helper
+
+import (
+	"fmt"
+	"os"
+)
+
+// CheckSecretFilePermissions ensure a file holding a secret exists and is only readable by its owner.
+// Named pipes are accepted, allowing to use file descriptors (ie: <(echo -n 'secret'))
+func CheckSecretFilePermissions(filename string) error {
+	fileInfo, err := os.Stat(filename)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("Cannot read secret file %s", filename)
+	}
+	if err != nil {
+		return err
+	}
+
+	mode := fileInfo.Mode() - (fileInfo.Mode() & os.ModeNamedPipe)
+	if mode != 0600 {
+		return fmt.Errorf("Wrong permission on secret file, please run \"chmod 600 %s\"", filename)
+	}
+
+	return nil
+}
@@ -0,0 +1,227 @@
+// Package helper provides handy func and struct to be reused in commands
+package helper
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/daeMOn63/bitclient"
+)
+
+// RepositorySelector select repositories from a project key and a repository slug.
+// Both accept shell patterns (ie: PRJ*, *-api), an empty value matching everything.
+type RepositorySelector struct {
+	Project    string
+	Repository string
+}
+
+// Select return the repositories matching the selector
+func (s RepositorySelector) Select(client *bitclient.BitClient) ([]bitclient.Repository, error) {
+	if err := s.validate(); err != nil {
+		return nil, err
+	}
+
+	// No need to walk through the projects when a single repository is targeted
+	if len(s.Project) > 0 && len(s.Repository) > 0 && !isPattern(s.Project) && !isPattern(s.Repository) {
+		return []bitclient.Repository{
+			{Slug: s.Repository, Project: bitclient.Project{Key: s.Project}},
+		}, nil
+	}
+
+	projectKeys := []string{s.Project}
+
+	if len(s.Project) == 0 || isPattern(s.Project) {
+		projects, err := GetAllProjects(client)
+		if err != nil {
+			return nil, err
+		}
+
+		projectKeys = nil
+		for _, project := range projects {
+			if s.matchProject(project.Key) {
+				projectKeys = append(projectKeys, project.Key)
+			}
+		}
+	}
+
+	var repositories []bitclient.Repository
+
+	for _, projectKey := range projectKeys {
+		projectRepositories, err := GetAllRepositories(client, projectKey)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, repository := range projectRepositories {
+			if s.matchRepository(repository.Slug) {
+				repositories = append(repositories, repository)
+			}
+		}
+	}
+
+	return repositories, nil
+}
+
+// SelectProjects return the keys of the projects matching the selector, ignoring the repository part
+func (s RepositorySelector) SelectProjects(client *bitclient.BitClient) ([]string, error) {
+	if err := s.validate(); err != nil {
+		return nil, err
+	}
+
+	if len(s.Project) > 0 && !isPattern(s.Project) {
+		return []string{s.Project}, nil
+	}
+
+	projects, err := GetAllProjects(client)
+	if err != nil {
+		return nil, err
+	}
+
+	var projectKeys []string
+	for _, project := range projects {
+		if s.matchProject(project.Key) {
+			projectKeys = append(projectKeys, project.Key)
+		}
+	}
+
+	return projectKeys, nil
+}
+
+// String convert the selector to a readable string
+func (s RepositorySelector) String() string {
+	project := s.Project
+	if len(project) == 0 {
+		project = "*"
+	}
+
+	repository := s.Repository
+	if len(repository) == 0 {
+		repository = "*"
+	}
+
+	return fmt.Sprintf("%s/%s", project, repository)
+}
+
+func (s RepositorySelector) validate() error {
+	for _, pattern := range []string{s.Project, s.Repository} {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid pattern %s: %s", pattern, err)
+		}
+	}
+
+	return nil
+}
+
+// Match tell if the repository is selected
+func (s RepositorySelector) Match(repository bitclient.Repository) bool {
+	return s.matchProject(repository.Project.Key) && s.matchRepository(repository.Slug)
+}
+
+// MatchProject tell if the project is selected, ignoring the repository part
+func (s RepositorySelector) MatchProject(projectKey string) bool {
+	return s.matchProject(projectKey)
+}
+
+func (s RepositorySelector) matchProject(projectKey string) bool {
+	return matchPattern(s.Project, projectKey)
+}
+
+func (s RepositorySelector) matchRepository(slug string) bool {
+	return matchPattern(s.Repository, slug)
+}
+
+func matchPattern(pattern string, value string) bool {
+	if len(pattern) == 0 {
+		return true
+	}
+
+	matched, _ := path.Match(pattern, value)
+
+	return matched
+}
+
+func isPattern(value string) bool {
+	return strings.ContainsAny(value, "*?[")
+}
+
+// GetAllProjects load every project visible on the server
+func GetAllProjects(client *bitclient.BitClient) ([]bitclient.Project, error) {
+	var projects []bitclient.Project
+
+	limit := uint(1000)
+	start := uint(0)
+	isLastPage := false
+
+	for !isLastPage {
+		projectResponse, err := client.GetProjects(bitclient.PagedRequest{
+			Limit: limit,
+			Start: start,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		projects = append(projects, projectResponse.Values...)
+
+		isLastPage = projectResponse.IsLastPage
+
+		start = projectResponse.NextPageStart
+	}
+
+	return projects, nil
+}
+
+// GetAllRepositories load every repository of given project
+func GetAllRepositories(client *bitclient.BitClient, projectKey string) ([]bitclient.Repository, error) {
+	var repositories []bitclient.Repository
+
+	limit := uint(1000)
+	start := uint(0)
+	isLastPage := false
+
+	for !isLastPage {
+		repositoryResponse, err := client.GetRepositories(projectKey, bitclient.PagedRequest{
+			Limit: limit,
+			Start: start,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		repositories = append(repositories, repositoryResponse.Values...)
+
+		isLastPage = repositoryResponse.IsLastPage
+
+		start = repositoryResponse.NextPageStart
+	}
+
+	return repositories, nil
+}
+
+// GetAllUsers load every user visible on the server
+func GetAllUsers(client *bitclient.BitClient) ([]bitclient.User, error) {
+	var users []bitclient.User
+
+	limit := uint(1000)
+	start := uint(0)
+	isLastPage := false
+
+	for !isLastPage {
+		userResponse, err := client.GetUsers(bitclient.PagedRequest{
+			Limit: limit,
+			Start: start,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		users = append(users, userResponse.Values...)
+
+		isLastPage = userResponse.IsLastPage
+
+		start = userResponse.NextPageStart
+	}
+
+	return users, nil
+}
@@ -0,0 +1,161 @@
+// Package helper provides handy func and struct to be reused in commands
+package helper
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// traceBodyLimit is the maximum number of bytes of a body written in the trace
+const traceBodyLimit = 8192
+
+// redacted replace the sensitive values in the trace
+const redacted = "REDACTED"
+
+// sensitiveHeaders hold credentials and must never be traced
+var sensitiveHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"Cookie":              true,
+	"Set-Cookie":          true,
+}
+
+// sensitiveFields match the JSON fields and query parameters holding secrets (ie: webhook secret, passwords, tokens)
+var sensitiveFields = regexp.MustCompile(`(?i)^(.*password.*|.*secret.*|.*token.*|credentials|passphrase)$`)
+
+// sensitiveJSONValues match the string values of the sensitive JSON fields
+var sensitiveJSONValues = regexp.MustCompile(`(?i)("[^"]*(?:password|secret|token|credentials|passphrase)[^"]*"\s*:\s*)"(?:[^"\\]|\\.)*"`)
+
+// TraceTransport is a http.RoundTripper writing every request and response to Output, with credentials redacted
+type TraceTransport struct {
+	Next   http.RoundTripper
+	Output io.Writer
+	// Debug add the headers and bodies to the trace
+	Debug bool
+}
+
+// NewTraceTransport create a TraceTransport wrapping next, or http.DefaultTransport when nil
+func NewTraceTransport(next http.RoundTripper, output io.Writer, debug bool) *TraceTransport {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	return &TraceTransport{
+		Next:   next,
+		Output: output,
+		Debug:  debug,
+	}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *TraceTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	target := RedactURL(request.URL)
+
+	fmt.Fprintf(t.Output, "[HTTP] > %s %s\n", request.Method, target)
+
+	if t.Debug {
+		t.writeHeaders(">", request.Header)
+
+		if request.Body != nil && request.GetBody != nil {
+			body, err := request.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			t.writeBody(">", body)
+		}
+	}
+
+	start := time.Now()
+	response, err := t.Next.RoundTrip(request)
+	elapsed := time.Since(start).Round(time.Millisecond)
+
+	if err != nil {
+		fmt.Fprintf(t.Output, "[HTTP] < %s %s failed after %s: %s\n", request.Method, target, elapsed, err)
+		return response, err
+	}
+
+	fmt.Fprintf(t.Output, "[HTTP] < %s %s %s (%s)\n", request.Method, target, response.Status, elapsed)
+
+	if t.Debug {
+		t.writeHeaders("<", response.Header)
+
+		// The body is read once for the trace and replaced by an in memory copy for the caller
+		data, err := ioutil.ReadAll(response.Body)
+		if closeErr := response.Body.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		response.Body = ioutil.NopCloser(bytes.NewReader(data))
+		t.writeBody("<", ioutil.NopCloser(bytes.NewReader(data)))
+	}
+
+	return response, nil
+}
+
+// writeHeaders write the headers sorted by name, with sensitive values redacted
+func (t *TraceTransport) writeHeaders(direction string, header http.Header) {
+	var names []string
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		value := strings.Join(header[name], ", ")
+		if sensitiveHeaders[http.CanonicalHeaderKey(name)] {
+			value = redacted
+		}
+		fmt.Fprintf(t.Output, "[HTTP] %s %s: %s\n", direction, name, value)
+	}
+}
+
+// writeBody write the body, truncated to traceBodyLimit, with sensitive JSON fields redacted
+func (t *TraceTransport) writeBody(direction string, body io.ReadCloser) {
+	data, err := ioutil.ReadAll(io.LimitReader(body, traceBodyLimit+1))
+	if closeErr := body.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil || len(data) == 0 {
+		return
+	}
+
+	truncated := ""
+	if len(data) > traceBodyLimit {
+		data = data[:traceBodyLimit]
+		truncated = " [truncated]"
+	}
+
+	fmt.Fprintf(t.Output, "[HTTP] %s %s%s\n", direction, RedactBody(data), truncated)
+}
+
+// RedactURL give the url as a string, without user info and with sensitive query parameters redacted
+func RedactURL(u *url.URL) string {
+	redactedURL := *u
+	redactedURL.User = nil
+
+	query := redactedURL.Query()
+	for name := range query {
+		if sensitiveFields.MatchString(name) {
+			query.Set(name, redacted)
+			redactedURL.RawQuery = query.Encode()
+		}
+	}
+
+	return redactedURL.String()
+}
+
+// RedactBody replace the values of the sensitive JSON string fields
+func RedactBody(body []byte) []byte {
+	return sensitiveJSONValues.ReplaceAll(body, []byte(`$1"`+redacted+`"`))
+}
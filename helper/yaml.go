@@ -0,0 +1,64 @@
+// Package helper provides handy func and struct to be reused in commands
+package helper
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// IsYAMLFile tell from its extension if filename hold YAML content
+func IsYAMLFile(filename string) bool {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".yml", ".yaml":
+		return true
+	}
+
+	return false
+}
+
+// YAMLToJSON convert a YAML document to its JSON equivalent
+func YAMLToJSON(data []byte) ([]byte, error) {
+	var value interface{}
+	if err := yaml.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+
+	converted, err := jsonCompatible(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(converted)
+}
+
+// jsonCompatible replace the map[interface{}]interface{} produced by the YAML decoder by map[string]interface{}
+func jsonCompatible(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		object := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			converted, err := jsonCompatible(item)
+			if err != nil {
+				return nil, err
+			}
+			object[fmt.Sprintf("%v", key)] = converted
+		}
+		return object, nil
+	case []interface{}:
+		list := make([]interface{}, len(v))
+		for i, item := range v {
+			converted, err := jsonCompatible(item)
+			if err != nil {
+				return nil, err
+			}
+			list[i] = converted
+		}
+		return list, nil
+	}
+
+	return value, nil
+}
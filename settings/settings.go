@@ -2,12 +2,20 @@
 package settings
 
 import (
+	"errors"
 	"fmt"
 	"github.com/daeMOn63/bitadmin/helper"
 	"github.com/daeMOn63/bitclient"
 	"github.com/urfave/cli"
+	"io"
 	"io/ioutil"
+	"net/http"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 )
 
 // BitAdminSettings hold the global flags values
@@ -16,7 +24,19 @@ type BitAdminSettings struct {
 	Password     string
 	PasswordFile string
 	URL          string
-	TempDir      string
+	CacheRoot    string
+	Verbose      bool
+	Debug        bool
+	TraceFile    string
+	CacheTTL     time.Duration
+	CacheRefresh bool
+	Retry        helper.RetryPolicy
+	// CredentialHelper is the name of the store holding the passwords, see helper.NewCredentialStore
+	CredentialHelper string
+
+	httpClient *http.Client
+	// storeError is the reason why the saved password cannot be read, reported when no password is given
+	storeError error
 }
 
 // GetFlags provide the []cli.Flag needed by a cli.Command
@@ -34,50 +54,363 @@ func (bs *BitAdminSettings) GetFlags() []cli.Flag {
 		},
 		cli.StringFlag{
 			Name:        "password",
-			Usage:       "Read password from `<file>`",
+			Usage:       "Read password from `<file>`. When not given, the password saved by the login command is used",
 			Destination: &bs.PasswordFile,
 		},
+		cli.StringFlag{
+			Name: "credential-helper",
+			Usage: "Save and read the passwords with `<helper>`: " + helper.CredentialStoreSecretService + " for the OS keyring (libsecret), " +
+				helper.CredentialStoreNone + " to disable, or a git credential helper (ie: store, osxkeychain, !/path/to/script)",
+			EnvVar:      "BITADMIN_CREDENTIAL_HELPER",
+			Value:       bs.CredentialHelper,
+			Destination: &bs.CredentialHelper,
+		},
+		cli.BoolFlag{
+			Name:        "verbose",
+			Usage:       "Trace the api calls (method, url, status, timing) and their retries on stderr",
+			EnvVar:      "BITADMIN_VERBOSE",
+			Destination: &bs.Verbose,
+		},
+		cli.BoolFlag{
+			Name:        "debug",
+			Usage:       "Like --verbose, also tracing the headers and bodies. Credentials and secrets are redacted",
+			EnvVar:      "BITADMIN_DEBUG",
+			Destination: &bs.Debug,
+		},
+		cli.StringFlag{
+			Name:        "trace-file",
+			Usage:       "Append the --verbose or --debug traces to `<file>` instead of stderr",
+			EnvVar:      "BITADMIN_TRACE_FILE",
+			Destination: &bs.TraceFile,
+		},
+		cli.IntFlag{
+			Name:        "retries",
+			Usage:       "Retry failed GET, HEAD and PUT api calls up to `<count>` times on rate limiting (429), unavailability (503), timeouts and connection resets. 0 to disable",
+			EnvVar:      "BITADMIN_RETRIES",
+			Value:       bs.Retry.MaxRetries,
+			Destination: &bs.Retry.MaxRetries,
+		},
+		cli.DurationFlag{
+			Name:        "retry-delay",
+			Usage:       "Initial `<delay>` between retries, doubled on every attempt. Retry-After headers sent by Bitbucket take precedence",
+			EnvVar:      "BITADMIN_RETRY_DELAY",
+			Value:       bs.Retry.InitialDelay,
+			Destination: &bs.Retry.InitialDelay,
+		},
+		cli.DurationFlag{
+			Name:        "retry-max-delay",
+			Usage:       "Maximum `<delay>` between retries",
+			EnvVar:      "BITADMIN_RETRY_MAX_DELAY",
+			Value:       bs.Retry.MaxDelay,
+			Destination: &bs.Retry.MaxDelay,
+		},
+		cli.StringFlag{
+			Name:        "cache-dir",
+			Usage:       "`<directory>` holding the caches of every server and user",
+			EnvVar:      "BITADMIN_CACHE_DIR",
+			Value:       bs.CacheRoot,
+			Destination: &bs.CacheRoot,
+		},
+		cli.DurationFlag{
+			Name:        "cache-ttl",
+			Usage:       "Cached data older than `<duration>` are considered stale. 0 to never expire",
+			EnvVar:      "BITADMIN_CACHE_TTL",
+			Value:       bs.CacheTTL,
+			Destination: &bs.CacheTTL,
+		},
+		cli.BoolFlag{
+			Name:        "cache-auto-refresh",
+			Usage:       "Refresh the cache in background when autocompletion find it stale",
+			EnvVar:      "BITADMIN_CACHE_AUTO_REFRESH",
+			Destination: &bs.CacheRefresh,
+		},
 	}
 }
 
 // GetAPIClient create a new instance of bitclient.BitClient initialized with the flag values
 func (bs *BitAdminSettings) GetAPIClient() (*bitclient.BitClient, error) {
+	if err := bs.loadCredentials(); err != nil {
+		return nil, err
+	}
 
-	// Load password from password file, checking for proper file permissions
-	if bs.PasswordFile != "" {
-		fileInfo, err := os.Stat(bs.PasswordFile)
-		if os.IsNotExist(err) {
-			return nil, fmt.Errorf("Cannot read password file %s", bs.PasswordFile)
-		}
+	// bitclient build its requests on http.DefaultClient and provide no way to use another client, so the retry
+	// policy is installed there. bitadmin is a single command process, nothing else rely on the default client.
+	httpClient, err := bs.getHTTPClient()
+	if err != nil {
+		return nil, err
+	}
+	http.DefaultClient.Transport = httpClient.Transport
+
+	return bitclient.NewBitClient(bs.URL, bs.Username, bs.Password), nil
+}
+
+// GetRestClient create a new instance of helper.RestClient initialized with the flag values.
+// It must be used only for the api endpoints not provided by bitclient.
+func (bs *BitAdminSettings) GetRestClient() (*helper.RestClient, error) {
+	if err := bs.loadCredentials(); err != nil {
+		return nil, err
+	}
+
+	httpClient, err := bs.getHTTPClient()
+	if err != nil {
+		return nil, err
+	}
+
+	return helper.NewRestClient(bs.URL, bs.Username, bs.Password, httpClient), nil
+}
 
-		// Ensure proper permission on password file or named pipe if used
-		mode := fileInfo.Mode() - (fileInfo.Mode() & os.ModeNamedPipe)
-		if mode != 0600 {
-			return nil, fmt.Errorf("Wrong permission on password file, please run \"chmod 600 %s\"", bs.PasswordFile)
+// loadCredentials read the password file, or the credential store when no file is given, and validate the global flags
+func (bs *BitAdminSettings) loadCredentials() error {
+
+	// Load password from password file, checking for proper file permissions.
+	// It is read only once as it can be a file descriptor.
+	if bs.PasswordFile != "" && bs.Password == "" {
+		if err := helper.CheckSecretFilePermissions(bs.PasswordFile); err != nil {
+			return err
 		}
 
 		passFromFile, err := ioutil.ReadFile(bs.PasswordFile)
 		if err != nil {
-			return nil, err
+			return err
 		}
 
 		bs.Password = string(passFromFile)
 	}
 
-	if err := bs.Validate(); err != nil {
-		return nil, err
+	if bs.PasswordFile == "" && bs.Password == "" && bs.Username != "" && bs.URL != "" {
+		password, err := bs.readStoredPassword()
+		if err != nil {
+			return err
+		}
+
+		bs.Password = password
 	}
 
-	return bitclient.NewBitClient(bs.URL, bs.Username, bs.Password), nil
+	return bs.Validate()
+}
+
+// GetCredentialStore give the store selected with --credential-helper, or an error when they are disabled
+func (bs *BitAdminSettings) GetCredentialStore() (helper.CredentialStore, error) {
+	store := helper.NewCredentialStore(bs.CredentialHelper)
+	if store == nil {
+		return nil, fmt.Errorf("credential stores are disabled, set --credential-helper to %s or a credential helper", helper.CredentialStoreSecretService)
+	}
+
+	return store, nil
+}
+
+// GetServer give the normalized url of the server, identifying it in the caches and credential stores
+func (bs *BitAdminSettings) GetServer() string {
+	return helper.NormalizeServerURL(bs.URL)
+}
+
+// readStoredPassword give the password saved by the login command, or an empty string when there is none.
+// The store is never queried during autocompletion, it must stay fast and cannot answer an unlock prompt.
+func (bs *BitAdminSettings) readStoredPassword() (string, error) {
+	store := helper.NewCredentialStore(bs.CredentialHelper)
+	if store == nil || isCompleting() {
+		return "", nil
+	}
+
+	password, err := store.Get(bs.GetServer(), bs.Username)
+	if errors.Is(err, helper.ErrCredentialNotFound) {
+		return "", nil
+	}
+	if errors.Is(err, helper.ErrCredentialStoreUnavailable) {
+		bs.storeError = err
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("cannot read the password from %s: %w", store.Name(), err)
+	}
+
+	return password, nil
+}
+
+// getHTTPClient give the http client of the api calls, going through the retry policy and the tracing.
+// Its transport wrap http.DefaultTransport, so it can be installed on http.DefaultClient without looping.
+func (bs *BitAdminSettings) getHTTPClient() (*http.Client, error) {
+	if bs.httpClient != nil {
+		return bs.httpClient, nil
+	}
+
+	var trace io.Writer
+	if bs.Verbose || bs.Debug {
+		trace = os.Stderr
+
+		if len(bs.TraceFile) > 0 {
+			// Traces may hold sensitive data not caught by the redaction, so the file is kept private
+			file, err := os.OpenFile(bs.TraceFile, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+			if err != nil {
+				return nil, fmt.Errorf("cannot open trace file: %w", err)
+			}
+			trace = file
+		}
+	}
+
+	var transport http.RoundTripper = http.DefaultTransport
+	if trace != nil {
+		transport = helper.NewTraceTransport(transport, trace, bs.Debug)
+	}
+
+	bs.httpClient = &http.Client{Transport: helper.NewRetryTransport(transport, bs.Retry, trace)}
+
+	return bs.httpClient, nil
 }
 
-// GetFileCache create a new instance of helper.FileCache and load the data from disk
+// GetFileCache give the cache used by the autocompletion, or nil when it cannot be chosen (ie: several caches exist
+// and the global flags do not select one). Commands must use LoadFileCache to report why.
 func (bs *BitAdminSettings) GetFileCache() *helper.FileCache {
-	cache := helper.NewFileCache(bs.TempDir)
-	cache.Load()
+	cache, err := bs.LoadFileCache()
+	if err != nil {
+		return nil
+	}
+
+	if bs.CacheRefresh && isCompleting() && cache.IsExpired() {
+		bs.refreshCacheInBackground(cache.Dir())
+	}
+
 	return cache
 }
 
+// LoadFileCache create a new instance of helper.FileCache and load the data from disk
+func (bs *BitAdminSettings) LoadFileCache() (*helper.FileCache, error) {
+	cacheDir, err := bs.GetCacheDir()
+	if err != nil {
+		return nil, err
+	}
+
+	cache := helper.NewFileCache(cacheDir)
+	cache.SetTTL(bs.CacheTTL)
+	cache.SetQuiet(isCompleting())
+
+	// A missing cache is expected until the first warmup, anything else is worth a warning
+	err = cache.Load()
+	if err != nil && !os.IsNotExist(err) {
+		fmt.Fprintf(os.Stderr, "[WARN] cannot load cache: %s\n", err)
+	}
+
+	if len(bs.URL) > 0 && len(bs.Username) > 0 {
+		cache.SetIdentity(helper.NormalizeServerURL(bs.URL), bs.Username)
+	}
+
+	// The autocompletion may pick the cache of another server, the migration wait for a command run with --url
+	if _, legacyErr := os.Stat(helper.LegacyCacheFile()); legacyErr == nil && len(bs.URL) > 0 && !isCompleting() {
+		bs.migrateLegacyCache(cache, os.IsNotExist(err))
+	}
+
+	return cache, nil
+}
+
+// migrateLegacyCache import the cache file of the released versions in the first cache created for a --url. Its
+// namespaces are left stale, so they are refreshed from that server before being trusted. This file is readable by
+// every local user and hold emails, so it is removed even when it cannot be imported.
+func (bs *BitAdminSettings) migrateLegacyCache(cache *helper.FileCache, empty bool) {
+	legacyFile := helper.LegacyCacheFile()
+
+	if empty {
+		if err := cache.MigrateLegacyCache(legacyFile); err != nil {
+			fmt.Fprintf(os.Stderr, "[WARN] cannot migrate the cache %s: %s\n", legacyFile, err)
+		}
+	}
+
+	if err := os.Remove(legacyFile); err != nil && !os.IsNotExist(err) {
+		fmt.Fprintf(os.Stderr, "[WARN] cannot remove the cache %s: %s\n", legacyFile, err)
+	}
+
+	// The directory is shared with the default cache root when no home directory is found, so it is kept if not empty
+	_ = os.Remove(filepath.Dir(legacyFile))
+}
+
+// GetCacheDir give the cache directory of the server and user from the global flags.
+// When they are not provided (ie: autocompletion without the global flags), the only existing cache is used.
+func (bs *BitAdminSettings) GetCacheDir() (string, error) {
+	if len(bs.URL) > 0 && len(bs.Username) > 0 {
+		return filepath.Join(bs.CacheRoot, helper.CacheKey(bs.URL, bs.Username)), nil
+	}
+
+	caches, err := helper.ListFileCaches(bs.CacheRoot)
+	if err != nil {
+		return "", err
+	}
+
+	switch len(caches) {
+	case 0:
+		return "", errors.New("no cache found, provide the --url and --user flags")
+	case 1:
+		return caches[0].Dir(), nil
+	}
+
+	return "", fmt.Errorf("%d caches found in %s, provide the --url and --user flags to select one", len(caches), bs.CacheRoot)
+}
+
+// backgroundRefreshDelay is the minimum delay between two background refresh, so each completion doesn't start a new one
+const backgroundRefreshDelay = 10 * time.Minute
+
+// isCompleting tells if bitadmin has been called by the shell autocompletion
+func isCompleting() bool {
+	for _, arg := range os.Args {
+		if arg == "--generate-bash-completion" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// refreshCacheInBackground start a detached "bitadmin cache refresh" of cacheDir with the current global flags.
+// Completion must stay fast and quiet, so failures are ignored and the refreshed data will serve the next completions.
+func (bs *BitAdminSettings) refreshCacheInBackground(cacheDir string) {
+	if len(bs.Username) == 0 || len(bs.URL) == 0 {
+		return
+	}
+
+	// Passwords given as file descriptors (ie: <(echo secret)) cannot be read again by another process. Without
+	// password file, the refresh read the password from the credential store.
+	args := []string{
+		"--user", bs.Username,
+		"--url", bs.URL,
+		"--credential-helper", bs.CredentialHelper,
+		"--cache-dir", bs.CacheRoot,
+		"--cache-ttl", bs.CacheTTL.String(),
+		"--retries", strconv.Itoa(bs.Retry.MaxRetries),
+		"--retry-delay", bs.Retry.InitialDelay.String(),
+		"--retry-max-delay", bs.Retry.MaxDelay.String(),
+	}
+	switch {
+	case strings.HasPrefix(bs.PasswordFile, "/dev/") || strings.HasPrefix(bs.PasswordFile, "/proc/"):
+		return
+	case len(bs.PasswordFile) > 0:
+		args = append(args, "--password", bs.PasswordFile)
+	case helper.NewCredentialStore(bs.CredentialHelper) == nil:
+		return
+	}
+
+	lockFile := filepath.Join(cacheDir, "refresh.lock")
+	if info, err := os.Stat(lockFile); err == nil && time.Since(info.ModTime()) < backgroundRefreshDelay {
+		return
+	}
+
+	if err := os.MkdirAll(cacheDir, 0700); err != nil {
+		return
+	}
+	if err := ioutil.WriteFile(lockFile, nil, 0600); err != nil {
+		return
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return
+	}
+
+	refresh := exec.Command(executable, append(args, "cache", "refresh")...)
+	if err := refresh.Start(); err != nil {
+		return
+	}
+
+	_ = refresh.Process.Release()
+}
+
 // Validate check for errors in global flag values
 func (bs *BitAdminSettings) Validate() error {
 
@@ -85,20 +418,35 @@ func (bs *BitAdminSettings) Validate() error {
 		return fmt.Errorf("global flag --user is required")
 	}
 
+	if bs.Password == "" && bs.storeError != nil {
+		return fmt.Errorf("global flag --password is required, the password saved by the login command cannot be read: %w", bs.storeError)
+	}
+
 	if bs.Password == "" {
-		return fmt.Errorf("global flag --password is required")
+		return fmt.Errorf("global flag --password is required, or save the password with the login command")
 	}
 
 	if bs.URL == "" {
 		return fmt.Errorf("global flag --url is required")
 	}
 
+	if bs.Retry.MaxRetries < 0 {
+		return fmt.Errorf("global flag --retries must be positive")
+	}
+
 	return nil
 }
 
 // NewSettings create a new instance of BitAdminSettings
 func NewSettings() *BitAdminSettings {
 	return &BitAdminSettings{
-		TempDir: os.TempDir() + "/bitadmin",
+		CacheRoot:        helper.DefaultCacheRoot(),
+		CredentialHelper: helper.CredentialStoreNone,
+		CacheTTL:         24 * time.Hour,
+		Retry: helper.RetryPolicy{
+			MaxRetries:   3,
+			InitialDelay: 500 * time.Millisecond,
+			MaxDelay:     30 * time.Second,
+		},
 	}
 }